@@ -6,6 +6,7 @@ import (
 	_ "github.com/gophab/gophrame/core/engine"
 	_ "github.com/gophab/gophrame/core/eventbus"
 	_ "github.com/gophab/gophrame/core/security"
+	_ "github.com/gophab/gophrame/core/security/feign"
 	_ "github.com/gophab/gophrame/core/snowflake"
 
 	// core
@@ -13,11 +14,19 @@ import (
 	_ "github.com/gophab/gophrame/core/database"
 	_ "github.com/gophab/gophrame/core/email"
 	_ "github.com/gophab/gophrame/core/email/code"
+	_ "github.com/gophab/gophrame/core/health"
+	_ "github.com/gophab/gophrame/core/kafka"
+	_ "github.com/gophab/gophrame/core/messaging"
+	_ "github.com/gophab/gophrame/core/metrics"
 	_ "github.com/gophab/gophrame/core/microservice"
 	_ "github.com/gophab/gophrame/core/rabbitmq"
 	_ "github.com/gophab/gophrame/core/redis"
+	_ "github.com/gophab/gophrame/core/resilience"
 	_ "github.com/gophab/gophrame/core/sms"
 	_ "github.com/gophab/gophrame/core/sms/code"
+	_ "github.com/gophab/gophrame/core/sse"
+	_ "github.com/gophab/gophrame/core/tracing"
+	_ "github.com/gophab/gophrame/core/webservice/middleware"
 	_ "github.com/gophab/gophrame/core/websocket"
 
 	// starter
@@ -5,18 +5,29 @@ import (
 
 	"github.com/gophab/gophrame/core/config"
 
+	_ "github.com/gophab/gophrame/core/cache/config"
 	_ "github.com/gophab/gophrame/core/captcha/config"
 	_ "github.com/gophab/gophrame/core/casbin/config"
 	_ "github.com/gophab/gophrame/core/database/config"
 	_ "github.com/gophab/gophrame/core/email/config"
+	_ "github.com/gophab/gophrame/core/health/config"
+	_ "github.com/gophab/gophrame/core/i18n/config"
+	_ "github.com/gophab/gophrame/core/kafka/config"
 	_ "github.com/gophab/gophrame/core/logger/config"
+	_ "github.com/gophab/gophrame/core/messaging/config"
+	_ "github.com/gophab/gophrame/core/metrics/config"
 	_ "github.com/gophab/gophrame/core/microservice/config"
+	_ "github.com/gophab/gophrame/core/module/config"
 	_ "github.com/gophab/gophrame/core/rabbitmq/config"
 	_ "github.com/gophab/gophrame/core/redis/config"
+	_ "github.com/gophab/gophrame/core/resilience/config"
 	_ "github.com/gophab/gophrame/core/security/config"
 	_ "github.com/gophab/gophrame/core/sms/config"
 	_ "github.com/gophab/gophrame/core/snowflake/config"
 	_ "github.com/gophab/gophrame/core/social/config"
+	_ "github.com/gophab/gophrame/core/sse/config"
+	_ "github.com/gophab/gophrame/core/tracing/config"
+	_ "github.com/gophab/gophrame/core/webservice/config"
 	_ "github.com/gophab/gophrame/core/websocket/config"
 )
 
@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/cache/config"
+	"github.com/gophab/gophrame/core/logger"
+	RedisConfig "github.com/gophab/gophrame/core/redis/config"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// 缓存接口，值以 JSON 序列化存储，各实现语义一致
+type Cache interface {
+	// 读取缓存到 out，不存在时返回 false
+	Get(ctx context.Context, key string, out interface{}) (bool, error)
+
+	// 写入缓存，ttl <= 0 时使用区域默认过期时间
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error
+
+	Delete(ctx context.Context, keys ...string) error
+
+	// 删除带有指定标签的缓存
+	DeleteByTag(ctx context.Context, tags ...string) error
+
+	// 剩余过期时间，不存在时返回 0
+	TTL(ctx context.Context, key string) (time.Duration, error)
+
+	Clear(ctx context.Context) error
+}
+
+func encode(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func decode(data []byte, out interface{}) error {
+	return json.Unmarshal(data, out)
+}
+
+var (
+	regions      = make(map[string]Cache)
+	regionsMutex sync.Mutex
+)
+
+func regionSetting(name string) *config.RegionSetting {
+	result := config.RegionSetting{}
+	if setting, b := config.Setting.Regions[name]; b && setting != nil {
+		result = *setting
+	}
+	if result.Type == "" {
+		result.Type = config.Setting.Type
+	}
+	if result.TTL <= 0 {
+		result.TTL = config.Setting.TTL
+	}
+	if result.LocalTTL <= 0 {
+		result.LocalTTL = config.Setting.LocalTTL
+	}
+	if result.Database == nil {
+		database := RedisConfig.Setting.Database
+		result.Database = &database
+	}
+	if result.Prefix == "" {
+		result.Prefix = "cache:" + name + ":"
+	}
+	return &result
+}
+
+// 获取缓存区域，按 cache.regions.<name> 配置创建，未配置时使用全局默认
+func Region(name string) Cache {
+	regionsMutex.Lock()
+	defer regionsMutex.Unlock()
+
+	if result, b := regions[name]; b {
+		return result
+	}
+
+	result := newRegion(name, regionSetting(name))
+	regions[name] = result
+	return result
+}
+
+// 获取需跨实例一致的缓存区域：区域未单独配置类型时，启用 Redis 则使用 two-level，
+// 否则返回 nil —— 仅有进程内缓存时变更无法通知其他实例，调用方应直接读取数据源
+func SharedRegion(name string) Cache {
+	regionsMutex.Lock()
+	defer regionsMutex.Unlock()
+
+	if result, b := regions[name]; b {
+		return result
+	}
+
+	setting := regionSetting(name)
+	if region, b := config.Setting.Regions[name]; !b || region == nil || region.Type == "" {
+		if !RedisConfig.Setting.Enabled {
+			return nil
+		}
+		setting.Type = config.TYPE_TWO_LEVEL
+	}
+
+	result := newRegion(name, setting)
+	regions[name] = result
+	return result
+}
+
+func newRegion(name string, setting *config.RegionSetting) Cache {
+	if setting.Type != config.TYPE_MEMORY && !RedisConfig.Setting.Enabled {
+		logger.Warn("[CACHE] Redis not enabled, region [", name, "] fallback to memory cache")
+		setting.Type = config.TYPE_MEMORY
+	}
+
+	switch setting.Type {
+	case config.TYPE_REDIS:
+		return NewRedisCache(*setting.Database, setting.Prefix, setting.TTL)
+	case config.TYPE_TWO_LEVEL:
+		return NewTwoLevelCache(name, NewMemoryCache(setting.LocalTTL), NewRedisCache(*setting.Database, setting.Prefix, setting.TTL))
+	default:
+		return NewMemoryCache(setting.TTL)
+	}
+}
+
+var loadGroup singleflight.Group
+
+// 共享加载的超时时间，加载不随首个调用方取消
+const loadTimeout = 30 * time.Second
+
+// 保留上下文中的值（链路、租户等），去除取消与截止时间
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+// Cache-aside：缓存未命中时调用 loader 加载并回写，同一 key 的并发加载只执行一次；c 为 nil 时直接加载
+func GetOrLoad[T any](ctx context.Context, c Cache, key string, ttl time.Duration, loader func(ctx context.Context) (T, error), tags ...string) (T, error) {
+	if c == nil {
+		return loader(ctx)
+	}
+
+	var result T
+	if b, err := c.Get(ctx, key, &result); err == nil && b {
+		return result, nil
+	} else if err != nil {
+		logger.Warn("[CACHE] Get [", key, "] error: ", err.Error())
+	}
+
+	ch := loadGroup.DoChan(fmt.Sprintf("%p:%s", c, key), func() (interface{}, error) {
+		// 多个调用方共享本次加载，任一调用方取消不应影响其他等待者
+		loadCtx, cancel := context.WithTimeout(detachedContext{ctx}, loadTimeout)
+		defer cancel()
+
+		value, err := loader(loadCtx)
+		if err != nil {
+			return value, err
+		}
+		if err := c.Set(loadCtx, key, value, ttl, tags...); err != nil {
+			logger.Warn("[CACHE] Set [", key, "] error: ", err.Error())
+		}
+		return value, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return result, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return result, res.Err
+		}
+		// T 为接口类型且 loader 返回 nil 时断言失败，返回零值
+		value, _ := res.Val.(T)
+		return value, nil
+	}
+}
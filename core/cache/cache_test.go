@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gophab/gophrame/core/cache/config"
+	RedisConfig "github.com/gophab/gophrame/core/redis/config"
+)
+
+func TestRegionSettingDatabase(t *testing.T) {
+	savedRegions, savedDatabase := config.Setting.Regions, RedisConfig.Setting.Database
+	defer func() {
+		config.Setting.Regions = savedRegions
+		RedisConfig.Setting.Database = savedDatabase
+	}()
+	RedisConfig.Setting.Database = 3
+
+	zero, five := 0, 5
+	tests := []struct {
+		name    string
+		setting *config.RegionSetting
+		want    int
+	}{
+		{name: "region not configured", want: 3},
+		{name: "database not configured", setting: &config.RegionSetting{}, want: 3},
+		{name: "database 0", setting: &config.RegionSetting{Database: &zero}, want: 0},
+		{name: "database 5", setting: &config.RegionSetting{Database: &five}, want: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Setting.Regions = map[string]*config.RegionSetting{}
+			if tt.setting != nil {
+				config.Setting.Regions["test"] = tt.setting
+			}
+			if got := *regionSetting("test").Database; got != tt.want {
+				t.Fatalf("database = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSharedRegionWithoutRedis(t *testing.T) {
+	savedRegions, savedEnabled := config.Setting.Regions, RedisConfig.Setting.Enabled
+	defer func() {
+		config.Setting.Regions = savedRegions
+		RedisConfig.Setting.Enabled = savedEnabled
+	}()
+	RedisConfig.Setting.Enabled = false
+
+	// 未单独配置的区域不使用进程内缓存，GetOrLoad 每次直接加载
+	config.Setting.Regions = map[string]*config.RegionSetting{}
+	c := SharedRegion("test.shared")
+	if c != nil {
+		t.Fatalf("SharedRegion = %T, want nil", c)
+	}
+	loads := 0
+	for i := 0; i < 2; i++ {
+		if _, err := GetOrLoad(context.Background(), c, "key", 0, func(ctx context.Context) (int, error) {
+			loads++
+			return loads, nil
+		}); err != nil {
+			t.Fatalf("GetOrLoad error: %v", err)
+		}
+	}
+	if loads != 2 {
+		t.Fatalf("loads = %d, want 2", loads)
+	}
+
+	// 显式配置类型时按配置创建
+	config.Setting.Regions["test.memory"] = &config.RegionSetting{Type: config.TYPE_MEMORY}
+	if _, b := SharedRegion("test.memory").(*MemoryCache); !b {
+		t.Fatal("configured memory region not created")
+	}
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"time"
+
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+const (
+	TYPE_MEMORY    = "memory"
+	TYPE_REDIS     = "redis"
+	TYPE_TWO_LEVEL = "two-level"
+)
+
+// 缓存区域配置
+type RegionSetting struct {
+	Type     string        `json:"type"`                     // memory / redis / two-level
+	TTL      time.Duration `json:"ttl"`                      // 默认过期时间
+	LocalTTL time.Duration `json:"localTTL" yaml:"localTTL"` // two-level 本地缓存过期时间
+	Database *int          `json:"database"`                 // redis 数据库，未配置时使用 redis.database，可显式配置为 0
+	Prefix   string        `json:"prefix"`                   // 键前缀，默认 "cache:<region>:"
+}
+
+type CacheSetting struct {
+	Type     string                    `json:"type"`
+	TTL      time.Duration             `json:"ttl"`
+	LocalTTL time.Duration             `json:"localTTL" yaml:"localTTL"`
+	Regions  map[string]*RegionSetting `json:"regions"`
+}
+
+var Setting *CacheSetting = &CacheSetting{
+	Type:     TYPE_MEMORY,
+	TTL:      time.Minute * 10,
+	LocalTTL: time.Minute,
+	Regions:  make(map[string]*RegionSetting),
+}
+
+func init() {
+	logger.Debug("Register Cache Config")
+	config.RegisterConfig("cache", Setting, "Cache Settings")
+}
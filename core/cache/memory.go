@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+)
+
+// 进程内缓存
+type MemoryCache struct {
+	cache   *gocache.Cache
+	ttl     time.Duration
+	tags    map[string]map[string]struct{}
+	keyTags map[string][]string // 键所属的标签，键过期、删除或覆盖时从标签索引中移除
+	mutex   sync.Mutex
+}
+
+func NewMemoryCache(ttl time.Duration) *MemoryCache {
+	result := &MemoryCache{
+		cache:   gocache.New(ttl, ttl*2),
+		ttl:     ttl,
+		tags:    make(map[string]map[string]struct{}),
+		keyTags: make(map[string][]string),
+	}
+	result.cache.OnEvicted(result.evicted)
+	return result
+}
+
+// 过期清理或删除后回调，回调前键可能已被重新写入
+func (m *MemoryCache) evicted(key string, _ interface{}) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, b := m.cache.Get(key); !b {
+		m.untag(key)
+	}
+}
+
+// 调用方持有 mutex
+func (m *MemoryCache) untag(key string) {
+	for _, tag := range m.keyTags[key] {
+		delete(m.tags[tag], key)
+		if len(m.tags[tag]) == 0 {
+			delete(m.tags, tag)
+		}
+	}
+	delete(m.keyTags, key)
+}
+
+func (m *MemoryCache) Get(ctx context.Context, key string, out interface{}) (bool, error) {
+	if data, b := m.cache.Get(key); b {
+		return true, decode(data.([]byte), out)
+	}
+	return false, nil
+}
+
+func (m *MemoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	data, err := encode(value)
+	if err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		ttl = m.ttl
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.cache.Set(key, data, ttl)
+
+	// 覆盖时以本次的标签为准
+	m.untag(key)
+	if len(tags) > 0 {
+		for _, tag := range tags {
+			if m.tags[tag] == nil {
+				m.tags[tag] = make(map[string]struct{})
+			}
+			m.tags[tag][key] = struct{}{}
+		}
+		m.keyTags[key] = append([]string(nil), tags...)
+	}
+	return nil
+}
+
+// 删除时由 evicted 回调维护标签索引，不能持有 mutex 调用
+func (m *MemoryCache) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		m.cache.Delete(key)
+	}
+	return nil
+}
+
+func (m *MemoryCache) DeleteByTag(ctx context.Context, tags ...string) error {
+	m.mutex.Lock()
+	keys := make([]string, 0)
+	for _, tag := range tags {
+		for key := range m.tags[tag] {
+			keys = append(keys, key)
+		}
+	}
+	m.mutex.Unlock()
+
+	return m.Delete(ctx, keys...)
+}
+
+func (m *MemoryCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if _, expiration, b := m.cache.GetWithExpiration(key); b {
+		if expiration.IsZero() {
+			return -1, nil
+		}
+		return time.Until(expiration), nil
+	}
+	return 0, nil
+}
+
+func (m *MemoryCache) Clear(ctx context.Context) error {
+	m.cache.Flush()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.tags = make(map[string]map[string]struct{})
+	m.keyTags = make(map[string][]string)
+	return nil
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func tagIndexSize(m *MemoryCache) (tags int, keys int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return len(m.tags), len(m.keyTags)
+}
+
+func TestMemoryCacheTags(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		action func(m *MemoryCache)
+	}{
+		{name: "delete", action: func(m *MemoryCache) { _ = m.Delete(ctx, "a", "b") }},
+		{name: "delete by tag", action: func(m *MemoryCache) { _ = m.DeleteByTag(ctx, "t1", "t2") }},
+		{name: "overwrite without tags", action: func(m *MemoryCache) {
+			_ = m.Set(ctx, "a", 1, 0)
+			_ = m.Set(ctx, "b", 1, 0)
+		}},
+		{name: "expire", action: func(m *MemoryCache) {
+			time.Sleep(30 * time.Millisecond)
+			m.cache.DeleteExpired()
+		}},
+		{name: "clear", action: func(m *MemoryCache) { _ = m.Clear(ctx) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMemoryCache(time.Minute)
+			if err := m.Set(ctx, "a", 1, 20*time.Millisecond, "t1", "t2"); err != nil {
+				t.Fatalf("Set error: %v", err)
+			}
+			if err := m.Set(ctx, "b", 1, 20*time.Millisecond, "t2"); err != nil {
+				t.Fatalf("Set error: %v", err)
+			}
+			if tags, keys := tagIndexSize(m); tags != 2 || keys != 2 {
+				t.Fatalf("tag index = %d tags %d keys, want 2 tags 2 keys", tags, keys)
+			}
+
+			tt.action(m)
+
+			if tags, keys := tagIndexSize(m); tags != 0 || keys != 0 {
+				t.Fatalf("tag index not cleaned: %d tags %d keys", tags, keys)
+			}
+		})
+	}
+}
+
+func TestMemoryCacheOverwriteTags(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryCache(time.Minute)
+
+	_ = m.Set(ctx, "a", 1, 0, "old")
+	_ = m.Set(ctx, "a", 2, 0, "new")
+
+	// 覆盖后旧标签不再关联该键
+	if err := m.DeleteByTag(ctx, "old"); err != nil {
+		t.Fatalf("DeleteByTag error: %v", err)
+	}
+	var value int
+	if b, _ := m.Get(ctx, "a", &value); !b || value != 2 {
+		t.Fatalf("Get = %v %d, want true 2", b, value)
+	}
+
+	if err := m.DeleteByTag(ctx, "new"); err != nil {
+		t.Fatalf("DeleteByTag error: %v", err)
+	}
+	if b, _ := m.Get(ctx, "a", &value); b {
+		t.Fatal("key not deleted by new tag")
+	}
+}
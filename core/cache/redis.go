@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gophab/gophrame/core/redis"
+	RedisConfig "github.com/gophab/gophrame/core/redis/config"
+
+	redigo "github.com/gomodule/redigo/redis"
+)
+
+var errRedisNotAvailable = errors.New("redis not available")
+
+// Redis 缓存，复用 core/redis 连接池
+type RedisCache struct {
+	database int
+	prefix   string
+	ttl      time.Duration
+}
+
+func NewRedisCache(database int, prefix string, ttl time.Duration) *RedisCache {
+	return &RedisCache{
+		database: database,
+		prefix:   prefix,
+		ttl:      ttl,
+	}
+}
+
+func (r *RedisCache) client() (*redis.RedisClient, error) {
+	if result := redis.GetOneRedisClientIndex(r.database); result != nil {
+		return result, nil
+	}
+	return nil, errRedisNotAvailable
+}
+
+func (r *RedisCache) tagKey(tag string) string {
+	return r.prefix + "tag:" + tag
+}
+
+// 删除多个键：集群模式下多键 DEL 跨槽位会返回 CROSSSLOT，逐个删除
+func (r *RedisCache) del(client *redis.RedisClient, keys []interface{}) error {
+	if RedisConfig.Setting.Mode != RedisConfig.MODE_CLUSTER {
+		_, err := client.Execute("DEL", keys...)
+		return err
+	}
+
+	for _, key := range keys {
+		if _, err := client.Execute("DEL", key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RedisCache) Get(ctx context.Context, key string, out interface{}) (bool, error) {
+	client, err := r.client()
+	if err != nil {
+		return false, err
+	}
+	defer client.ReleaseOneRedisClient()
+
+	data, err := client.Bytes(client.Execute("GET", r.prefix+key))
+	if err == redis.ErrNil {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, decode(data, out)
+}
+
+func (r *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	data, err := encode(value)
+	if err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		ttl = r.ttl
+	}
+
+	client, err := r.client()
+	if err != nil {
+		return err
+	}
+	defer client.ReleaseOneRedisClient()
+
+	if _, err := client.Execute("SET", r.prefix+key, data, "PX", ttl.Milliseconds()); err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		if _, err := client.Execute("SADD", r.tagKey(tag), key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	client, err := r.client()
+	if err != nil {
+		return err
+	}
+	defer client.ReleaseOneRedisClient()
+
+	args := make([]interface{}, len(keys))
+	for i, key := range keys {
+		args[i] = r.prefix + key
+	}
+	return r.del(client, args)
+}
+
+func (r *RedisCache) DeleteByTag(ctx context.Context, tags ...string) error {
+	client, err := r.client()
+	if err != nil {
+		return err
+	}
+	defer client.ReleaseOneRedisClient()
+
+	for _, tag := range tags {
+		keys, err := client.Strings(client.Execute("SMEMBERS", r.tagKey(tag)))
+		if err != nil {
+			return err
+		}
+
+		args := []interface{}{r.tagKey(tag)}
+		for _, key := range keys {
+			args = append(args, r.prefix+key)
+		}
+		if err := r.del(client, args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RedisCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	client, err := r.client()
+	if err != nil {
+		return 0, err
+	}
+	defer client.ReleaseOneRedisClient()
+
+	res, err := client.Int64(client.Execute("PTTL", r.prefix+key))
+	if err != nil {
+		return 0, err
+	}
+	switch res {
+	case -2:
+		return 0, nil
+	case -1:
+		return -1, nil
+	}
+	return time.Duration(res) * time.Millisecond, nil
+}
+
+func (r *RedisCache) Clear(ctx context.Context) error {
+	// 集群模式下多键 DEL 可能跨槽位，逐个删除
+	return redis.ScanKeys(r.database, r.prefix+"*", func(conn redigo.Conn, keys []string) error {
+		for _, key := range keys {
+			if _, err := conn.Do("DEL", key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/redis"
+)
+
+const (
+	invalidateDelete = "delete"
+	invalidateTag    = "tag"
+	invalidateClear  = "clear"
+)
+
+type invalidateMessage struct {
+	Origin string   `json:"origin"`
+	Op     string   `json:"op"`
+	Keys   []string `json:"keys,omitempty"`
+}
+
+var instanceId = func() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}()
+
+// 二级缓存：本地内存 + Redis，写入/删除时通过 Redis 频道通知其他实例清除本地缓存
+type TwoLevelCache struct {
+	local   *MemoryCache
+	remote  *RedisCache
+	channel string
+}
+
+func NewTwoLevelCache(region string, local *MemoryCache, remote *RedisCache) *TwoLevelCache {
+	result := &TwoLevelCache{
+		local:   local,
+		remote:  remote,
+		channel: "cache:invalidate:" + region,
+	}
+	go redis.Subscribe(context.Background(), result.onInvalidate, result.channel)
+	return result
+}
+
+func (t *TwoLevelCache) onInvalidate(channel string, data []byte) {
+	var message invalidateMessage
+	if err := json.Unmarshal(data, &message); err != nil || message.Origin == instanceId {
+		return
+	}
+
+	ctx := context.Background()
+	switch message.Op {
+	case invalidateDelete:
+		_ = t.local.Delete(ctx, message.Keys...)
+	case invalidateTag, invalidateClear:
+		_ = t.local.Clear(ctx)
+	}
+}
+
+func (t *TwoLevelCache) publish(op string, keys ...string) {
+	data, _ := json.Marshal(&invalidateMessage{Origin: instanceId, Op: op, Keys: keys})
+	if err := redis.Publish(t.channel, data); err != nil {
+		logger.Warn("[CACHE] Publish invalidation error: ", err.Error())
+	}
+}
+
+func (t *TwoLevelCache) Get(ctx context.Context, key string, out interface{}) (bool, error) {
+	if b, err := t.local.Get(ctx, key, out); err == nil && b {
+		return true, nil
+	}
+
+	b, err := t.remote.Get(ctx, key, out)
+	if err != nil || !b {
+		return b, err
+	}
+
+	// 回填本地缓存，过期时间不超过远端剩余时间
+	ttl := t.local.ttl
+	if remain, err := t.remote.TTL(ctx, key); err == nil && remain > 0 && remain < ttl {
+		ttl = remain
+	}
+	_ = t.local.Set(ctx, key, out, ttl)
+	return true, nil
+}
+
+func (t *TwoLevelCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	if err := t.remote.Set(ctx, key, value, ttl, tags...); err != nil {
+		return err
+	}
+
+	localTTL := t.local.ttl
+	if ttl > 0 && ttl < localTTL {
+		localTTL = ttl
+	}
+	if err := t.local.Set(ctx, key, value, localTTL, tags...); err != nil {
+		return err
+	}
+
+	t.publish(invalidateDelete, key)
+	return nil
+}
+
+func (t *TwoLevelCache) Delete(ctx context.Context, keys ...string) error {
+	_ = t.local.Delete(ctx, keys...)
+	err := t.remote.Delete(ctx, keys...)
+	t.publish(invalidateDelete, keys...)
+	return err
+}
+
+// 本地回填的缓存不带标签，按标签删除时清空本地缓存
+func (t *TwoLevelCache) DeleteByTag(ctx context.Context, tags ...string) error {
+	_ = t.local.Clear(ctx)
+	err := t.remote.DeleteByTag(ctx, tags...)
+	t.publish(invalidateTag, tags...)
+	return err
+}
+
+func (t *TwoLevelCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return t.remote.TTL(ctx, key)
+}
+
+func (t *TwoLevelCache) Clear(ctx context.Context) error {
+	_ = t.local.Clear(ctx)
+	err := t.remote.Clear(ctx)
+	t.publish(invalidateClear)
+	return err
+}
@@ -64,6 +64,9 @@ func (*JsonExtension) CreateDecoder(typ reflect2.Type) jsoniter.ValDecoder {
 
 var json = jsoniter.ConfigCompatibleWithStandardLibrary
 
+// 最近一次加载的原始配置
+var loadedConfig map[string]interface{}
+
 func init() {
 	json.RegisterExtension(&JsonExtension{})
 	RegisterConfigChangeCallback(func() {
@@ -84,6 +87,27 @@ func UnmarshalFromNode(node any, out interface{}) error {
 	}
 }
 
+// 获取原始配置节点，path 以 "." 分隔逐级查找，例如 "plugins.demo.endpoint"
+func GetConfig(path string) (interface{}, bool) {
+	if loadedConfig == nil {
+		return nil, false
+	}
+
+	var node interface{} = loadedConfig
+	for _, seg := range strings.Split(path, ".") {
+		var b bool
+		if node, b = getConfigNode(node, seg); !b {
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+func HasConfig(path string) bool {
+	_, b := GetConfig(path)
+	return b
+}
+
 func loadConfig() error {
 	var config = make(map[string]interface{})
 
@@ -95,6 +119,8 @@ func loadConfig() error {
 			logger.Debug("Load application configuration: ", text)
 		}
 
+		loadedConfig = config
+
 		// Second to json
 		for key, value := range configs {
 			if key == "ROOT" {
@@ -45,6 +45,10 @@ func InitDB() *gorm.DB {
 		_ = db.Callback().Update().Before("gorm:update").Register("UpdateLastModifiedTimeHook", UpdateLastModifiedTimeHook)
 		_ = db.Callback().Delete().Before("gorm:delete").Register("UpdateDeletedTimeHook", UpdateDeletedTimeHook)
 
+		// 链路追踪及指标
+		registerTracingCallbacks(db)
+		registerMetricsCallbacks(db)
+
 		// 为主连接设置连接池(43行返回的数据库驱动指针)
 		if rawDb, err := db.DB(); err == nil {
 			rawDb.SetConnMaxIdleTime(config.Setting.ConnectionMaxIdleTime)
@@ -166,6 +166,16 @@ func (l *LoggerWrapper) Trace(ctx context.Context, begin time.Time, fc func() (s
 	}
 
 	elapsed := time.Since(begin)
+
+	// 日志附加链路 ID 等上下文字段
+	if fields := logger.Fields(ctx); fields != "" {
+		original := fc
+		fc = func() (string, int64) {
+			sql, rows := original()
+			return "[" + fields + "] " + sql, rows
+		}
+	}
+
 	switch {
 	case err != nil && l.LogLevel >= gormLog.Error && (!errors.Is(err, gormLog.ErrRecordNotFound) || !l.IgnoreRecordNotFoundError):
 		sql, rows := fc()
@@ -0,0 +1,94 @@
+package database
+
+import (
+	"time"
+
+	"github.com/gophab/gophrame/core/metrics"
+
+	"gorm.io/gorm"
+)
+
+const metricsStartKey = "metrics:start"
+
+var queryDuration = metrics.NewHistogramVec("db_query_duration_seconds",
+	"Database statement latency by operation and result", metrics.DefaultBuckets, "operation", "result")
+
+func init() {
+	metrics.MustRegister(queryDuration, metrics.NewCollector(poolMetrics))
+}
+
+// 连接池状态，抓取时读取 sql.DB.Stats
+func poolMetrics() []*metrics.MetricFamily {
+	gauge := func(name, help string, value float64) *metrics.MetricFamily {
+		return &metrics.MetricFamily{Name: name, Help: help, Type: metrics.GAUGE, Samples: []*metrics.Sample{{Value: value}}}
+	}
+	counter := func(name, help string, value float64) *metrics.MetricFamily {
+		return &metrics.MetricFamily{Name: name, Help: help, Type: metrics.COUNTER, Samples: []*metrics.Sample{{Value: value}}}
+	}
+
+	result := []*metrics.MetricFamily{
+		gauge("db_pool_max_open_connections", "Maximum number of open connections", 0),
+		gauge("db_pool_open_connections", "Established connections both in use and idle", 0),
+		gauge("db_pool_in_use_connections", "Connections currently in use", 0),
+		gauge("db_pool_idle_connections", "Idle connections", 0),
+		counter("db_pool_wait_total", "Connections waited for", 0),
+		counter("db_pool_wait_duration_seconds_total", "Time blocked waiting for a new connection", 0),
+	}
+
+	if db == nil {
+		return result
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return result
+	}
+
+	stats := sqlDB.Stats()
+	result[0].Samples[0].Value = float64(stats.MaxOpenConnections)
+	result[1].Samples[0].Value = float64(stats.OpenConnections)
+	result[2].Samples[0].Value = float64(stats.InUse)
+	result[3].Samples[0].Value = float64(stats.Idle)
+	result[4].Samples[0].Value = float64(stats.WaitCount)
+	result[5].Samples[0].Value = stats.WaitDuration.Seconds()
+	return result
+}
+
+func startTimer(db *gorm.DB) {
+	db.InstanceSet(metricsStartKey, time.Now())
+}
+
+func observeDuration(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(metricsStartKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		result := "success"
+		if db.Error != nil && db.Error != gorm.ErrRecordNotFound {
+			result = "error"
+		}
+		queryDuration.WithLabelValues(operation, result).Observe(time.Since(start).Seconds())
+	}
+}
+
+func registerMetricsCallbacks(db *gorm.DB) {
+	callback := db.Callback()
+
+	_ = callback.Create().Before("gorm:create").Register("metrics:before_create", startTimer)
+	_ = callback.Create().After("gorm:create").Register("metrics:after_create", observeDuration("create"))
+	_ = callback.Query().Before("gorm:query").Register("metrics:before_query", startTimer)
+	_ = callback.Query().After("gorm:query").Register("metrics:after_query", observeDuration("query"))
+	_ = callback.Update().Before("gorm:update").Register("metrics:before_update", startTimer)
+	_ = callback.Update().After("gorm:update").Register("metrics:after_update", observeDuration("update"))
+	_ = callback.Delete().Before("gorm:delete").Register("metrics:before_delete", startTimer)
+	_ = callback.Delete().After("gorm:delete").Register("metrics:after_delete", observeDuration("delete"))
+	_ = callback.Row().Before("gorm:row").Register("metrics:before_row", startTimer)
+	_ = callback.Row().After("gorm:row").Register("metrics:after_row", observeDuration("row"))
+	_ = callback.Raw().Before("gorm:raw").Register("metrics:before_raw", startTimer)
+	_ = callback.Raw().After("gorm:raw").Register("metrics:after_raw", observeDuration("raw"))
+}
@@ -1,15 +1,48 @@
 package database
 
 import (
+	"context"
+	"errors"
+
 	"github.com/gophab/gophrame/core/database/config"
 	"github.com/gophab/gophrame/core/global"
+	"github.com/gophab/gophrame/core/health"
 	"github.com/gophab/gophrame/core/inject"
 	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/module"
 	"github.com/gophab/gophrame/core/starter"
 )
 
 func init() {
 	starter.RegisterInitializor(Init)
+
+	module.RegisterBuiltinModule("database", "Database", func() bool {
+		return config.Setting.Enabled
+	}, func() error {
+		if DB() == nil {
+			return errors.New("database not available")
+		}
+		return nil
+	})
+
+	health.RegisterChecker(&health.Checker{
+		Name:      "database",
+		Check:     Ping,
+		Condition: func() bool { return config.Setting.Enabled },
+		Readiness: true,
+	})
+}
+
+// 检测数据库连接
+func Ping(ctx context.Context) error {
+	if DB() == nil {
+		return errors.New("database not available")
+	}
+	sqlDB, err := DB().DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
 }
 
 func Init() {
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/gophab/gophrame/core/database/config"
+	"github.com/gophab/gophrame/core/tracing"
+
+	"gorm.io/gorm"
+)
+
+const tracingSpanKey = "tracing:span"
+
+// 每条 SQL 一个 Span，上级链路取自 db.WithContext(ctx)
+func startSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if !tracing.Enabled() || db.Statement == nil || db.Statement.Context == nil {
+			return
+		}
+
+		_, span := tracing.Start(db.Statement.Context, "gorm."+operation, tracing.WithKind(tracing.SPAN_KIND_CLIENT), tracing.WithAttributes(map[string]interface{}{
+			"db.system":    config.Setting.Driver,
+			"db.operation": operation,
+		}))
+		if span != nil {
+			db.InstanceSet(tracingSpanKey, span)
+		}
+	}
+}
+
+func endSpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(tracingSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(*tracing.Span)
+	if !ok {
+		return
+	}
+
+	if db.Statement.Table != "" {
+		span.SetAttribute("db.sql.table", db.Statement.Table)
+	}
+	span.SetAttribute("db.statement", db.Statement.SQL.String())
+	span.SetAttribute("db.rows_affected", db.Statement.RowsAffected)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+	}
+	span.End()
+}
+
+func registerTracingCallbacks(db *gorm.DB) {
+	callback := db.Callback()
+
+	_ = callback.Create().Before("gorm:create").Register("tracing:before_create", startSpan("create"))
+	_ = callback.Create().After("gorm:create").Register("tracing:after_create", endSpan)
+	_ = callback.Query().Before("gorm:query").Register("tracing:before_query", startSpan("query"))
+	_ = callback.Query().After("gorm:query").Register("tracing:after_query", endSpan)
+	_ = callback.Update().Before("gorm:update").Register("tracing:before_update", startSpan("update"))
+	_ = callback.Update().After("gorm:update").Register("tracing:after_update", endSpan)
+	_ = callback.Delete().Before("gorm:delete").Register("tracing:before_delete", startSpan("delete"))
+	_ = callback.Delete().After("gorm:delete").Register("tracing:after_delete", endSpan)
+	_ = callback.Row().Before("gorm:row").Register("tracing:before_row", startSpan("row"))
+	_ = callback.Row().After("gorm:row").Register("tracing:after_row", endSpan)
+	_ = callback.Raw().Before("gorm:raw").Register("tracing:before_raw", startSpan("raw"))
+	_ = callback.Raw().After("gorm:raw").Register("tracing:after_raw", endSpan)
+}
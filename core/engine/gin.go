@@ -8,16 +8,28 @@ import (
 )
 
 var (
-	mutex  sync.Mutex
-	engine *gin.Engine
+	mutex    sync.Mutex
+	engine   *gin.Engine
+	logger   gin.HandlerFunc = gin.Logger()
+	recovery gin.HandlerFunc = gin.Recovery()
 )
 
+// 替换默认的请求日志，如结构化访问日志
+func SetLogger(handler gin.HandlerFunc) {
+	logger = handler
+}
+
+// 替换默认的 panic 恢复处理，如按统一错误格式返回
+func SetRecovery(handler gin.HandlerFunc) {
+	recovery = handler
+}
+
 func create() {
 	mutex.Lock()
 	if engine == nil {
 		engine = gin.New()
-		engine.Use(gin.Logger()) // 日志
-		engine.Use(gin.Recovery())
+		engine.Use(func(c *gin.Context) { logger(c) }) // 日志
+		engine.Use(func(c *gin.Context) { recovery(c) })
 	}
 	mutex.Unlock()
 }
@@ -6,14 +6,20 @@ import (
 
 	"github.com/gophab/gophrame/core/inject"
 	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/metrics"
 	"github.com/gophab/gophrame/errors"
 )
 
 // 公共消息总线
 var theEventbus *eventbus = CreateEventbus()
 
+// 事件分发计数，mode: sync / async，result: delivered / unhandled（无监听者）
+var dispatchedEvents = metrics.NewCounterVec("eventbus_events_total",
+	"Eventbus events by key, dispatch mode and result", "event", "mode", "result")
+
 func init() {
 	inject.InjectValue("eventbus", theEventbus)
+	metrics.MustRegister(dispatchedEvents)
 }
 
 func RegisterEventListener(key string, keyFunc func(args ...interface{})) bool {
@@ -86,10 +92,12 @@ func (e *eventbus) GetEventListeners(key string) ([]func(args ...interface{}), b
 // 3.执行事件
 func (e *eventbus) PublishEvent(key string, args ...interface{}) {
 	if queue, exists := e.GetEventListeners(key); exists {
+		dispatchedEvents.WithLabelValues(key, "sync", "delivered").Inc()
 		for _, fn := range queue {
 			fn(args...)
 		}
 	} else {
+		dispatchedEvents.WithLabelValues(key, "sync", "unhandled").Inc()
 		logger.Error(errors.ERROR_FUNC_EVENT_NOT_REGISTER, ", 键名：", key)
 	}
 }
@@ -97,12 +105,14 @@ func (e *eventbus) PublishEvent(key string, args ...interface{}) {
 // 3.执行事件
 func (e *eventbus) DispatchEvent(key string, args ...interface{}) {
 	if queue, exists := e.GetEventListeners(key); exists {
+		dispatchedEvents.WithLabelValues(key, "async", "delivered").Inc()
 		for _, fn := range queue {
 			go func(cb func(args ...interface{})) {
 				cb(args...)
 			}(fn)
 		}
 	} else {
+		dispatchedEvents.WithLabelValues(key, "async", "unhandled").Inc()
 		logger.Error(errors.ERROR_FUNC_EVENT_NOT_REGISTER, ", 键名：", key)
 	}
 }
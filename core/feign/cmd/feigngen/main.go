@@ -0,0 +1,476 @@
+// feigngen 根据接口注释生成声明式 Feign 客户端实现
+//
+//	//go:generate go run github.com/gophab/gophrame/core/feign/cmd/feigngen
+//
+// 接口注释：
+//
+//	@FeignClient <服务名>           必须，标记需要生成的接口
+//	@Url <地址>                     可选，直接访问地址
+//	@Path <前缀>                    可选，全部方法的路径前缀
+//
+// 方法注释（方法首个参数必须为 context.Context，返回 error 或 (T, error)）：
+//
+//	@GET|@POST|@PUT|@PATCH|@DELETE <路径模板>
+//	@Query <名称> [参数]             查询参数，参数缺省与名称相同
+//	@Header <名称> <参数>            请求头
+//	@Body <参数>                     请求体，JSON 编码
+//
+// 未声明的参数：与路径变量同名的作为路径参数，GET/DELETE 作为同名查询参数，
+// 其他方法作为请求体（最多一个）
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const feignImport = "github.com/gophab/gophrame/core/feign"
+
+var (
+	input  = flag.String("file", os.Getenv("GOFILE"), "source file declaring the interfaces")
+	output = flag.String("output", "", "output file, default <file>_feign.go")
+	types  = flag.String("type", "", "comma separated interface names, default all annotated")
+)
+
+func main() {
+	flag.Parse()
+	if *input == "" {
+		fail("no source file, use -file or run from go generate")
+	}
+	if *output == "" {
+		*output = strings.TrimSuffix(*input, ".go") + "_feign.go"
+	}
+
+	source, err := generate(*input, *types)
+	if err != nil {
+		fail(err.Error())
+	}
+	if source == nil {
+		fail("no @FeignClient interface found in " + *input)
+	}
+	if err := os.WriteFile(*output, source, 0644); err != nil {
+		fail(err.Error())
+	}
+}
+
+func fail(message string) {
+	fmt.Fprintln(os.Stderr, "feigngen:", message)
+	os.Exit(1)
+}
+
+type annotation struct {
+	name string
+	args []string
+}
+
+func annotations(doc *ast.CommentGroup) []annotation {
+	result := make([]annotation, 0)
+	if doc == nil {
+		return result
+	}
+	for _, comment := range doc.List {
+		text := strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(comment.Text, "//"), "/*"))
+		if !strings.HasPrefix(text, "@") {
+			continue
+		}
+		fields := strings.Fields(text)
+		result = append(result, annotation{name: strings.ToUpper(fields[0][1:]), args: fields[1:]})
+	}
+	return result
+}
+
+type param struct {
+	name     string
+	typeExpr string
+}
+
+type binding struct {
+	key   string
+	param string
+}
+
+type method struct {
+	name       string
+	params     []param
+	results    []string
+	http       string
+	path       string
+	pathParams []binding
+	query      []binding
+	headers    []binding
+	body       string
+}
+
+type client struct {
+	name    string
+	service string
+	url     string
+	prefix  string
+	methods []*method
+}
+
+type generator struct {
+	fset    *token.FileSet
+	file    *ast.File
+	imports map[string]string // 名称 -> 路径
+	used    map[string]bool
+}
+
+func (g *generator) expr(e ast.Expr) string {
+	ast.Inspect(e, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				if _, ok := g.imports[ident.Name]; ok {
+					g.used[ident.Name] = true
+				}
+			}
+		}
+		return true
+	})
+
+	var buf bytes.Buffer
+	_ = format.Node(&buf, g.fset, e)
+	return buf.String()
+}
+
+func (g *generator) isContext(e ast.Expr) bool {
+	if sel, ok := e.(*ast.SelectorExpr); ok && sel.Sel.Name == "Context" {
+		if ident, ok := sel.X.(*ast.Ident); ok {
+			return g.imports[ident.Name] == "context"
+		}
+	}
+	return false
+}
+
+var pathVariable = regexp.MustCompile(`\{([^{}]+)\}`)
+
+func (g *generator) method(field *ast.Field, fn *ast.FuncType) (*method, error) {
+	result := &method{name: field.Names[0].Name}
+
+	// 参数
+	for i, p := range fn.Params.List {
+		names := p.Names
+		if len(names) == 0 {
+			if i == 0 {
+				names = []*ast.Ident{ast.NewIdent("ctx")}
+			} else {
+				return nil, fmt.Errorf("%s: parameters must be named", result.name)
+			}
+		}
+		typeExpr := g.expr(p.Type)
+		for _, name := range names {
+			if name.Name == "_" && len(result.params) == 0 {
+				name = ast.NewIdent("ctx")
+			}
+			result.params = append(result.params, param{name: name.Name, typeExpr: typeExpr})
+		}
+	}
+	if len(fn.Params.List) == 0 || !g.isContext(fn.Params.List[0].Type) {
+		return nil, fmt.Errorf("%s: first parameter must be context.Context", result.name)
+	}
+
+	// 返回值
+	if fn.Results != nil {
+		for _, r := range fn.Results.List {
+			count := len(r.Names)
+			if count == 0 {
+				count = 1
+			}
+			for i := 0; i < count; i++ {
+				result.results = append(result.results, g.expr(r.Type))
+			}
+		}
+	}
+	if len(result.results) == 0 || len(result.results) > 2 || result.results[len(result.results)-1] != "error" {
+		return nil, fmt.Errorf("%s: must return error or (T, error)", result.name)
+	}
+
+	// 注解
+	bound := map[string]bool{result.params[0].name: true}
+	paramExists := func(name string) bool {
+		for _, p := range result.params {
+			if p.name == name {
+				return true
+			}
+		}
+		return false
+	}
+	bind := func(name string) error {
+		if !paramExists(name) {
+			return fmt.Errorf("%s: unknown parameter %s", result.name, name)
+		}
+		bound[name] = true
+		return nil
+	}
+
+	for _, a := range annotations(field.Doc) {
+		switch a.name {
+		case "GET", "POST", "PUT", "PATCH", "DELETE", "HEAD":
+			if len(a.args) != 1 {
+				return nil, fmt.Errorf("%s: @%s requires a path", result.name, a.name)
+			}
+			result.http, result.path = a.name, a.args[0]
+		case "QUERY":
+			if len(a.args) < 1 || len(a.args) > 2 {
+				return nil, fmt.Errorf("%s: usage @Query <name> [param]", result.name)
+			}
+			p := a.args[len(a.args)-1]
+			if err := bind(p); err != nil {
+				return nil, err
+			}
+			result.query = append(result.query, binding{key: a.args[0], param: p})
+		case "HEADER":
+			if len(a.args) != 2 {
+				return nil, fmt.Errorf("%s: usage @Header <name> <param>", result.name)
+			}
+			if err := bind(a.args[1]); err != nil {
+				return nil, err
+			}
+			result.headers = append(result.headers, binding{key: a.args[0], param: a.args[1]})
+		case "BODY":
+			if len(a.args) != 1 {
+				return nil, fmt.Errorf("%s: usage @Body <param>", result.name)
+			}
+			if err := bind(a.args[0]); err != nil {
+				return nil, err
+			}
+			result.body = a.args[0]
+		}
+	}
+	if result.http == "" {
+		return nil, fmt.Errorf("%s: missing @GET/@POST/@PUT/@PATCH/@DELETE", result.name)
+	}
+
+	// 路径变量
+	for _, match := range pathVariable.FindAllStringSubmatch(result.path, -1) {
+		if err := bind(match[1]); err != nil {
+			return nil, err
+		}
+		result.pathParams = append(result.pathParams, binding{key: match[1], param: match[1]})
+	}
+
+	// 未声明的参数
+	for _, p := range result.params {
+		if bound[p.name] {
+			continue
+		}
+		if result.http == "GET" || result.http == "DELETE" || result.http == "HEAD" {
+			result.query = append(result.query, binding{key: p.name, param: p.name})
+		} else if result.body == "" {
+			result.body = p.name
+		} else {
+			return nil, fmt.Errorf("%s: parameter %s is not bound", result.name, p.name)
+		}
+	}
+	return result, nil
+}
+
+func generate(filename, names string) ([]byte, error) {
+	g := &generator{
+		fset:    token.NewFileSet(),
+		imports: make(map[string]string),
+		used:    make(map[string]bool),
+	}
+
+	file, err := parser.ParseFile(g.fset, filename, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	g.file = file
+
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := filepath.Base(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		g.imports[name] = path
+	}
+
+	wanted := make(map[string]bool)
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			wanted[name] = true
+		}
+	}
+
+	clients := make([]*client, 0)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			iface, ok := typeSpec.Type.(*ast.InterfaceType)
+			if !ok {
+				continue
+			}
+			if len(wanted) > 0 && !wanted[typeSpec.Name.Name] {
+				continue
+			}
+
+			doc := typeSpec.Doc
+			if doc == nil && len(gen.Specs) == 1 {
+				doc = gen.Doc
+			}
+
+			c := &client{name: typeSpec.Name.Name}
+			annotated := false
+			for _, a := range annotations(doc) {
+				switch a.name {
+				case "FEIGNCLIENT":
+					annotated = true
+					if len(a.args) > 0 {
+						c.service = a.args[0]
+					}
+				case "URL":
+					if len(a.args) > 0 {
+						c.url = a.args[0]
+					}
+				case "PATH":
+					if len(a.args) > 0 {
+						c.prefix = strings.TrimRight(a.args[0], "/")
+					}
+				}
+			}
+			if !annotated {
+				continue
+			}
+			if c.service == "" && c.url == "" {
+				return nil, fmt.Errorf("%s: @FeignClient requires a service name", c.name)
+			}
+
+			for _, field := range iface.Methods.List {
+				fn, ok := field.Type.(*ast.FuncType)
+				if !ok || len(field.Names) == 0 {
+					return nil, fmt.Errorf("%s: embedded interfaces are not supported", c.name)
+				}
+				m, err := g.method(field, fn)
+				if err != nil {
+					return nil, fmt.Errorf("%s.%s", c.name, err.Error())
+				}
+				c.methods = append(c.methods, m)
+			}
+			clients = append(clients, c)
+		}
+	}
+
+	if len(clients) == 0 {
+		return nil, nil
+	}
+	return g.render(clients)
+}
+
+func mapLiteral(bindings []binding) string {
+	if len(bindings) == 0 {
+		return ""
+	}
+	var buf strings.Builder
+	buf.WriteString("map[string]interface{}{\n")
+	for _, b := range bindings {
+		fmt.Fprintf(&buf, "%s: %s,\n", strconv.Quote(b.key), b.param)
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+func unexport(name string) string {
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+func (g *generator) render(clients []*client) ([]byte, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "// Code generated by feigngen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", g.file.Name.Name)
+
+	// 标准库与第三方分组
+	std, others := []string{}, []string{strconv.Quote(feignImport)}
+	for name := range g.used {
+		path := g.imports[name]
+		spec := strconv.Quote(path)
+		if filepath.Base(path) != name {
+			spec = name + " " + spec
+		}
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			others = append(others, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(others)
+	fmt.Fprintf(&buf, "import (\n%s\n\n%s\n)\n", strings.Join(std, "\n"), strings.Join(others, "\n"))
+
+	for _, c := range clients {
+		impl := unexport(c.name) + "Feign"
+
+		fmt.Fprintf(&buf, "\ntype %s struct {\n\ttarget *feign.Target\n}\n", impl)
+		fmt.Fprintf(&buf, "\n// 创建 %s 的 Feign 实现\n", c.name)
+		fmt.Fprintf(&buf, "func New%s(options ...feign.TargetOption) %s {\n", c.name, c.name)
+		if c.url != "" {
+			fmt.Fprintf(&buf, "options = append([]feign.TargetOption{feign.WithUrl(%s)}, options...)\n", strconv.Quote(c.url))
+		}
+		fmt.Fprintf(&buf, "return &%s{target: feign.NewTarget(%s, options...)}\n}\n", impl, strconv.Quote(c.service))
+
+		for _, m := range c.methods {
+			params := make([]string, 0, len(m.params))
+			for _, p := range m.params {
+				params = append(params, p.name+" "+p.typeExpr)
+			}
+			results := strings.Join(m.results, ", ")
+			if len(m.results) > 1 {
+				results = "(" + results + ")"
+			}
+
+			fmt.Fprintf(&buf, "\nfunc (c *%s) %s(%s) %s {\n", impl, m.name, strings.Join(params, ", "), results)
+
+			resultArg := "nil"
+			if len(m.results) == 2 {
+				fmt.Fprintf(&buf, "var result %s\n", m.results[0])
+				resultArg = "&result"
+			}
+
+			fmt.Fprintf(&buf, "err := c.target.Invoke(%s, &feign.Call{\n", m.params[0].name)
+			fmt.Fprintf(&buf, "Method: %s,\n", strconv.Quote(m.http))
+			fmt.Fprintf(&buf, "Path: %s,\n", strconv.Quote(c.prefix+m.path))
+			if literal := mapLiteral(m.pathParams); literal != "" {
+				fmt.Fprintf(&buf, "Params: %s,\n", literal)
+			}
+			if literal := mapLiteral(m.query); literal != "" {
+				fmt.Fprintf(&buf, "Query: %s,\n", literal)
+			}
+			if literal := mapLiteral(m.headers); literal != "" {
+				fmt.Fprintf(&buf, "Headers: %s,\n", literal)
+			}
+			if m.body != "" {
+				fmt.Fprintf(&buf, "Body: %s,\n", m.body)
+			}
+			fmt.Fprintf(&buf, "}, %s)\n", resultArg)
+
+			if len(m.results) == 2 {
+				fmt.Fprintf(&buf, "return result, err\n}\n")
+			} else {
+				fmt.Fprintf(&buf, "return err\n}\n")
+			}
+		}
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %s\n%s", err.Error(), buf.String())
+	}
+	return source, nil
+}
@@ -2,18 +2,40 @@ package feign
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 )
 
+type orderedInterceptor struct {
+	interceptor FeignClientInterceptor
+	order       int
+}
+
+var orderedInterceptors = []*orderedInterceptor{}
 var globalFeignClientInterceptors = []FeignClientInterceptor{}
 
 func RegisterGlobalFeignClientInterceptor(interceptor FeignClientInterceptor) {
-	globalFeignClientInterceptors = append(globalFeignClientInterceptors, interceptor)
+	RegisterGlobalFeignClientInterceptorEx(interceptor, 0)
+}
+
+// 按 order 从小到大执行，order 越小越靠外层
+func RegisterGlobalFeignClientInterceptorEx(interceptor FeignClientInterceptor, order int) {
+	orderedInterceptors = append(orderedInterceptors, &orderedInterceptor{interceptor: interceptor, order: order})
+	sort.SliceStable(orderedInterceptors, func(i, j int) bool {
+		return orderedInterceptors[i].order < orderedInterceptors[j].order
+	})
+
+	interceptors := make([]FeignClientInterceptor, 0, len(orderedInterceptors))
+	for _, item := range orderedInterceptors {
+		interceptors = append(interceptors, item.interceptor)
+	}
+	globalFeignClientInterceptors = interceptors
 }
 
 func NewClient() *FeignClient {
@@ -42,6 +64,16 @@ func (f *FeignClientInterceptorChain) Next(method string, url string, urlValues
 	}
 }
 
+// 从当前位置复制调用链，在新的 client 上执行后续拦截器，用于重试等需要多次执行的场景
+func (f *FeignClientInterceptorChain) Fork(ctx context.Context) *FeignClientInterceptorChain {
+	client := f.FeignClient.clone()
+	client.ctx = ctx
+	return &FeignClientInterceptorChain{
+		FeignClient: client,
+		p:           f.p,
+	}
+}
+
 func (f *FeignClientInterceptorChain) Exit(err error) *FeignClient {
 	f.Error = err
 	return f.FeignClient
@@ -60,6 +92,7 @@ type FeignClient struct {
 	Request      *http.Request
 	Response     *http.Response
 	Error        error
+	ctx          context.Context
 	cloned       bool
 }
 
@@ -72,13 +105,34 @@ var DefaultRequestOptions = RequestOptions{
 	ContentType: "application/json;charset=UTF-8",
 }
 
+// 每次请求使用独立的副本，共享 HttpClient 及拦截器
 func (m *FeignClient) clone() *FeignClient {
+	httpClient := m.HttpClient
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
 	return &FeignClient{
-		HttpClient: &http.Client{},
-		cloned:     true,
+		HttpClient:   httpClient,
+		Interceptors: m.Interceptors,
+		ctx:          m.ctx,
+		cloned:       true,
 	}
 }
 
+// 指定请求上下文，用于超时、取消及在拦截器间传递信息
+func (m *FeignClient) WithContext(ctx context.Context) *FeignClient {
+	result := m.clone()
+	result.ctx = ctx
+	return result
+}
+
+func (m *FeignClient) Context() context.Context {
+	if m.ctx == nil {
+		return context.Background()
+	}
+	return m.ctx
+}
+
 func (m *FeignClient) Fetch(data interface{}) error {
 	if !m.cloned {
 		return errors.New("invalid client")
@@ -92,6 +146,7 @@ func (m *FeignClient) Fetch(data interface{}) error {
 		return errors.New("no response")
 	}
 
+	defer m.Response.Body.Close()
 	if resBytes, err := io.ReadAll(m.Response.Body); err != nil {
 		return err
 	} else {
@@ -116,6 +171,7 @@ func (m *FeignClient) Raw() ([]byte, error) {
 		return nil, errors.New("no response")
 	}
 
+	defer m.Response.Body.Close()
 	if resBytes, err := io.ReadAll(m.Response.Body); err != nil {
 		return nil, err
 	} else {
@@ -128,7 +184,7 @@ func (m *FeignClient) Get(url string, urlValues url.Values, options ...*RequestO
 }
 
 func (m *FeignClient) Post(url string, urlValues url.Values, bodyValue interface{}, options ...*RequestOptions) *FeignClient {
-	return m.Do("POST", url, nil, bodyValue, options...)
+	return m.Do("POST", url, urlValues, bodyValue, options...)
 }
 
 func (m *FeignClient) Put(url string, urlValues url.Values, bodyValue interface{}, options ...*RequestOptions) *FeignClient {
@@ -144,12 +200,9 @@ func (m *FeignClient) Delete(url string, urlValues url.Values, options ...*Reque
 }
 
 func (m *FeignClient) Do(method string, url string, urlValues url.Values, bodyValue interface{}, options ...*RequestOptions) *FeignClient {
-	if !m.cloned {
-		return m.clone().Do(method, url, urlValues, bodyValue, options...)
-	}
-
+	// 每次调用在新的副本上执行，client 可重复使用
 	return (&FeignClientInterceptorChain{
-		FeignClient: m,
+		FeignClient: m.clone(),
 	}).Next(method, url, urlValues, bodyValue, options...)
 }
 
@@ -159,7 +212,14 @@ func (m *FeignClient) do(method string, url string, urlValues url.Values, bodyVa
 	}
 
 	var bodyValueBytes []byte = make([]byte, 0)
-	if bodyValue != nil {
+	switch body := bodyValue.(type) {
+	case nil:
+	case []byte:
+		// 原样发送，如表单
+		bodyValueBytes = body
+	case string:
+		bodyValueBytes = []byte(body)
+	default:
 		bytes, err := json.Marshal(bodyValue)
 		if err != nil {
 			m.Error = err
@@ -168,7 +228,7 @@ func (m *FeignClient) do(method string, url string, urlValues url.Values, bodyVa
 		bodyValueBytes = bytes
 	}
 
-	req, err := http.NewRequest(method, m.formatUrl(url, urlValues), bytes.NewReader(bodyValueBytes))
+	req, err := http.NewRequestWithContext(m.Context(), method, m.formatUrl(url, urlValues), bytes.NewReader(bodyValueBytes))
 	if err != nil {
 		m.Error = err
 		return m
@@ -182,7 +242,11 @@ func (m *FeignClient) formatUrl(url string, urlValues url.Values) string {
 	sb := new(strings.Builder)
 	sb.WriteString(url)
 	if len(urlValues) > 0 {
-		sb.WriteString("?")
+		if strings.Contains(url, "?") {
+			sb.WriteString("&")
+		} else {
+			sb.WriteString("?")
+		}
 		sb.WriteString(urlValues.Encode())
 	}
 
@@ -0,0 +1,231 @@
+package feign
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+/**
+ * 声明式客户端
+ *
+ * 在接口及方法注释上声明请求，由 feigngen 生成实现：
+ *
+ *	//go:generate go run github.com/gophab/gophrame/core/feign/cmd/feigngen
+ *
+ *	// @FeignClient user-service
+ *	type UserClient interface {
+ *		// @GET /users/{id}
+ *		// @Header Authorization token
+ *		GetUser(ctx context.Context, id string, token string) (*User, error)
+ *
+ *		// @POST /users
+ *		// @Body user
+ *		CreateUser(ctx context.Context, user *User) (*User, error)
+ *	}
+ *
+ *	client := NewUserClient()
+ *
+ * 生成的实现通过 Target.Invoke 发起调用，经过全部拦截器（含注册中心服务发现）
+ */
+
+// 一次声明式调用
+type Call struct {
+	Method  string
+	Path    string                 // 路径模板，如 /users/{id}
+	Params  map[string]interface{} // 路径参数
+	Query   map[string]interface{} // 查询参数，切片展开为多个值，nil 忽略
+	Headers map[string]interface{}
+	Body    interface{}
+}
+
+// 非 2xx 响应
+type HttpError struct {
+	StatusCode int
+	Status     string
+	Body       []byte
+}
+
+func (e *HttpError) Error() string {
+	if len(e.Body) > 0 {
+		return fmt.Sprintf("%s: %s", e.Status, string(e.Body))
+	}
+	return e.Status
+}
+
+// 将非 2xx 响应映射为业务错误，返回 nil 时使用 HttpError
+type ErrorDecoder func(response *http.Response, body []byte) error
+
+// 声明式客户端的调用目标
+type Target struct {
+	Name         string // 服务名，经注册中心解析
+	Url          string // 直接地址，设置后不使用服务名
+	Client       *FeignClient
+	ErrorDecoder ErrorDecoder
+	Headers      map[string]string // 每次调用附加的请求头
+}
+
+type TargetOption func(*Target)
+
+func WithUrl(url string) TargetOption {
+	return func(t *Target) {
+		t.Url = url
+	}
+}
+
+func WithFeignClient(client *FeignClient) TargetOption {
+	return func(t *Target) {
+		t.Client = client
+	}
+}
+
+func WithErrorDecoder(decoder ErrorDecoder) TargetOption {
+	return func(t *Target) {
+		t.ErrorDecoder = decoder
+	}
+}
+
+func WithHeader(name, value string) TargetOption {
+	return func(t *Target) {
+		if t.Headers == nil {
+			t.Headers = make(map[string]string)
+		}
+		t.Headers[name] = value
+	}
+}
+
+func NewTarget(name string, options ...TargetOption) *Target {
+	result := &Target{Name: name}
+	for _, option := range options {
+		option(result)
+	}
+	if result.Client == nil {
+		result.Client = NewClient()
+	}
+	return result
+}
+
+func (t *Target) baseUrl() string {
+	if t.Url != "" {
+		return strings.TrimRight(t.Url, "/")
+	}
+	return "http://" + t.Name
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+func expandPath(path string, params map[string]interface{}) (string, error) {
+	var err error
+	result := pathParamPattern.ReplaceAllStringFunc(path, func(s string) string {
+		name := s[1 : len(s)-1]
+		value, ok := params[name]
+		if !ok {
+			err = fmt.Errorf("missing path parameter: %s", name)
+			return s
+		}
+		return url.PathEscape(fmt.Sprint(value))
+	})
+	return result, err
+}
+
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+func queryValues(query map[string]interface{}) url.Values {
+	result := url.Values{}
+	for name, value := range query {
+		if isNil(value) {
+			continue
+		}
+		v := reflect.Indirect(reflect.ValueOf(value))
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			for i := 0; i < v.Len(); i++ {
+				result.Add(name, fmt.Sprint(v.Index(i).Interface()))
+			}
+		} else {
+			result.Add(name, fmt.Sprint(v.Interface()))
+		}
+	}
+	return result
+}
+
+// 执行调用，响应 JSON 解码到 result（*string、*[]byte 接收原文）
+func (t *Target) Invoke(ctx context.Context, call *Call, result interface{}) error {
+	path, err := expandPath(call.Path, call.Params)
+	if err != nil {
+		return err
+	}
+
+	options := &RequestOptions{
+		ContentType: DefaultRequestOptions.ContentType,
+		Headers:     make(map[string]string),
+	}
+	for name, value := range t.Headers {
+		options.Headers[name] = value
+	}
+	for name, value := range call.Headers {
+		if !isNil(value) {
+			options.Headers[name] = fmt.Sprint(reflect.Indirect(reflect.ValueOf(value)).Interface())
+		}
+	}
+
+	var body interface{}
+	if !isNil(call.Body) {
+		body = call.Body
+	}
+
+	client := t.Client.WithContext(ctx).Do(call.Method, t.baseUrl()+path, queryValues(call.Query), body, options)
+	if client.Error != nil {
+		return client.Error
+	}
+	if client.Response == nil {
+		return fmt.Errorf("no response")
+	}
+
+	data, err := client.Raw()
+	if err != nil {
+		return err
+	}
+
+	if client.Response.StatusCode < 200 || client.Response.StatusCode > 299 {
+		if t.ErrorDecoder != nil {
+			if err := t.ErrorDecoder(client.Response, data); err != nil {
+				return err
+			}
+		}
+		return &HttpError{
+			StatusCode: client.Response.StatusCode,
+			Status:     client.Response.Status,
+			Body:       data,
+		}
+	}
+
+	switch r := result.(type) {
+	case nil:
+		return nil
+	case *[]byte:
+		*r = data
+		return nil
+	case *string:
+		*r = string(data)
+		return nil
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
@@ -15,19 +15,6 @@ const (
 	ErrorBasePath   = "无法获取根路径"
 )
 
-type CORS struct {
-	Mode      string          `mapstructure:"mode" json:"mode" yaml:"mode"`
-	Whitelist []CORSWhitelist `mapstructure:"whitelist" json:"whitelist" yaml:"whitelist"`
-}
-
-type CORSWhitelist struct {
-	AllowOrigin      string `mapstructure:"allow-origin" json:"allow-origin" yaml:"allow-origin"`
-	AllowMethods     string `mapstructure:"allow-methods" json:"allow-methods" yaml:"allow-methods"`
-	AllowHeaders     string `mapstructure:"allow-headers" json:"allow-headers" yaml:"allow-headers"`
-	ExposeHeaders    string `mapstructure:"expose-headers" json:"expose-headers" yaml:"expose-headers"`
-	AllowCredentials bool   `mapstructure:"allow-credentials" json:"allow-credentials" yaml:"allow-credentials"`
-}
-
 var (
 	BasePath string // 定义项目的根目录
 
@@ -53,11 +40,6 @@ var (
 
 	//casbin 全局操作指针
 	Enforcer *casbin.SyncedEnforcer
-
-	// CORS
-	Cors *CORS = &CORS{
-		Mode: "allow-all",
-	}
 )
 
 func init() {
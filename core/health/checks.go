@@ -0,0 +1,35 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gophab/gophrame/core/health/config"
+	"github.com/gophab/gophrame/core/module"
+)
+
+// 生命周期执行失败的模块
+func checkModules(ctx context.Context) error {
+	failed := make([]string, 0)
+	for _, status := range module.GetModulesStatus() {
+		if status.Status == module.StatusText(module.STATUS_FAILED) {
+			failed = append(failed, status.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return errors.New("failed modules: " + strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func init() {
+	RegisterCheck("modules", checkModules)
+
+	RegisterChecker(&Checker{
+		Name:      "diskSpace",
+		Check:     checkDiskSpace,
+		Condition: func() bool { return config.Setting.DiskSpace.Enabled },
+		Readiness: true,
+	})
+}
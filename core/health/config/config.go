@@ -0,0 +1,45 @@
+package config
+
+import (
+	"time"
+
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+type DiskSpaceSetting struct {
+	Enabled   bool   `json:"enabled"`
+	Path      string `json:"path"`
+	Threshold int64  `json:"threshold"` // 可用空间低于该值（字节）时为 DOWN
+}
+
+type HealthSetting struct {
+	Enabled      bool             `json:"enabled"`
+	Path         string           `json:"path"`                             // <path>/live, <path>/ready
+	Timeout      time.Duration    `json:"timeout"`                          // 单项检查超时
+	CacheTTL     time.Duration    `json:"cacheTTL" yaml:"cacheTTL"`         // 检查结果缓存时间，0 不缓存
+	ShowDetails  bool             `json:"showDetails" yaml:"showDetails"`   // 返回各组件详情（含依赖错误信息），端点无认证，默认关闭
+	DrainDelay   time.Duration    `json:"drainDelay" yaml:"drainDelay"`     // 退出时标记下线后等待流量摘除的时间
+	DrainTimeout time.Duration    `json:"drainTimeout" yaml:"drainTimeout"` // 等待处理中请求完成的最长时间
+	DiskSpace    DiskSpaceSetting `json:"diskSpace" yaml:"diskSpace"`
+}
+
+var Setting *HealthSetting = &HealthSetting{
+	Enabled:      true,
+	Path:         "/health",
+	Timeout:      time.Second * 3,
+	CacheTTL:     time.Second * 2,
+	ShowDetails:  false,
+	DrainDelay:   time.Second * 5,
+	DrainTimeout: time.Second * 30,
+	DiskSpace: DiskSpaceSetting{
+		Enabled:   true,
+		Path:      ".",
+		Threshold: 10 * 1024 * 1024,
+	},
+}
+
+func init() {
+	logger.Debug("Register Health Config")
+	config.RegisterConfig("health", Setting, "Health Settings")
+}
@@ -0,0 +1,25 @@
+//go:build linux || darwin || freebsd
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+
+	"github.com/gophab/gophrame/core/health/config"
+)
+
+// 可用磁盘空间低于阈值时为 DOWN
+func checkDiskSpace(ctx context.Context) error {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(config.Setting.DiskSpace.Path, &stat); err != nil {
+		return err
+	}
+
+	free := uint64(stat.Bavail) * uint64(stat.Bsize)
+	if free < uint64(config.Setting.DiskSpace.Threshold) {
+		return fmt.Errorf("free disk space %d below threshold %d", free, config.Setting.DiskSpace.Threshold)
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+//go:build !linux && !darwin && !freebsd
+
+package health
+
+import (
+	"context"
+)
+
+// 当前平台不支持磁盘空间检查
+func checkDiskSpace(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,65 @@
+package health
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gophab/gophrame/core/health/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func respond(c *gin.Context, health *Health) {
+	status := http.StatusOK
+	if !health.IsUp() {
+		status = http.StatusServiceUnavailable
+	}
+
+	if config.Setting.ShowDetails {
+		c.JSON(status, health)
+	} else {
+		c.JSON(status, gin.H{"status": health.Status})
+	}
+}
+
+// 存活探针
+func LivenessHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respond(c, Liveness(c.Request.Context()))
+	}
+}
+
+// 就绪探针
+func ReadinessHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respond(c, Readiness(c.Request.Context()))
+	}
+}
+
+var inflight int64
+
+// 处理中的请求数，用于退出时等待请求完成
+func Inflight() int64 {
+	return atomic.LoadInt64(&inflight)
+}
+
+// 长连接（websocket 升级、SSE）由各自模块在退出时关闭，不计入处理中请求，
+// 否则退出时总要等满 drainTimeout
+func isStreaming(c *gin.Context) bool {
+	if strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+		return true
+	}
+	return strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+}
+
+func inflightMiddleware(c *gin.Context) {
+	if isStreaming(c) {
+		c.Next()
+		return
+	}
+
+	atomic.AddInt64(&inflight, 1)
+	defer atomic.AddInt64(&inflight, -1)
+	c.Next()
+}
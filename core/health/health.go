@@ -0,0 +1,276 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/health/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+type Status string
+
+const (
+	STATUS_UP             Status = "UP"
+	STATUS_DOWN           Status = "DOWN"
+	STATUS_STARTING       Status = "STARTING"       // 尚未启动完成，不接收流量
+	STATUS_OUT_OF_SERVICE Status = "OUT_OF_SERVICE" // 主动下线（退出中）
+	STATUS_UNKNOWN        Status = "UNKNOWN"
+)
+
+var ErrCheckTimeout = errors.New("health check timeout")
+
+// 健康检查项
+type Checker struct {
+	Name      string
+	Check     func(ctx context.Context) error
+	Condition func() bool   // 返回 false 时不参与检查，如模块未启用
+	Liveness  bool          // 参与存活检查：仅用于进程自身无法恢复的故障
+	Readiness bool          // 参与就绪检查：依赖不可用时暂停接收流量
+	Optional  bool          // 失败时仅标记组件 DOWN，不影响整体状态
+	Timeout   time.Duration // 为 0 时使用配置的超时
+}
+
+type ComponentHealth struct {
+	Status   Status        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Optional bool          `json:"optional,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+type Health struct {
+	Status     Status                      `json:"status"`
+	Components map[string]*ComponentHealth `json:"components,omitempty"`
+	Time       time.Time                   `json:"time"`
+}
+
+func (h *Health) IsUp() bool {
+	return h.Status == STATUS_UP
+}
+
+var (
+	checkers      = make([]*Checker, 0)
+	checkersMutex sync.RWMutex
+)
+
+func RegisterChecker(checker *Checker) {
+	checkersMutex.Lock()
+	defer checkersMutex.Unlock()
+
+	for i, c := range checkers {
+		if c.Name == checker.Name {
+			logger.Warn("[HEALTH] Replace health checker: ", checker.Name)
+			checkers[i] = checker
+			return
+		}
+	}
+	checkers = append(checkers, checker)
+}
+
+// 注册就绪检查
+func RegisterCheck(name string, check func(ctx context.Context) error) {
+	RegisterChecker(&Checker{Name: name, Check: check, Readiness: true})
+}
+
+// 注册存活检查
+func RegisterLivenessCheck(name string, check func(ctx context.Context) error) {
+	RegisterChecker(&Checker{Name: name, Check: check, Liveness: true})
+}
+
+func selectCheckers(liveness bool) []*Checker {
+	checkersMutex.RLock()
+	defer checkersMutex.RUnlock()
+
+	result := make([]*Checker, 0, len(checkers))
+	for _, c := range checkers {
+		if (liveness && !c.Liveness) || (!liveness && !c.Readiness) {
+			continue
+		}
+		if c.Condition != nil && !c.Condition() {
+			continue
+		}
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// 执行单项检查，panic 及超时视为失败
+func run(ctx context.Context, checker *Checker) *ComponentHealth {
+	timeout := checker.Timeout
+	if timeout <= 0 {
+		timeout = config.Setting.Timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	begin := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ErrCheckTimeout
+	}
+
+	result := &ComponentHealth{
+		Status:   STATUS_UP,
+		Optional: checker.Optional,
+		Duration: time.Since(begin),
+	}
+	if err != nil {
+		result.Status = STATUS_DOWN
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// 并行执行全部检查并汇总
+func evaluate(ctx context.Context, list []*Checker) *Health {
+	result := &Health{
+		Status:     STATUS_UP,
+		Components: make(map[string]*ComponentHealth, len(list)),
+		Time:       time.Now(),
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	for _, checker := range list {
+		wg.Add(1)
+		go func(checker *Checker) {
+			defer wg.Done()
+			component := run(ctx, checker)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			result.Components[checker.Name] = component
+			if component.Status != STATUS_UP && !checker.Optional {
+				result.Status = STATUS_DOWN
+			}
+		}(checker)
+	}
+	wg.Wait()
+	return result
+}
+
+type cachedHealth struct {
+	health *Health
+	mutex  sync.Mutex
+}
+
+func (c *cachedHealth) get(ctx context.Context, liveness bool) *Health {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.health != nil && config.Setting.CacheTTL > 0 && time.Since(c.health.Time) < config.Setting.CacheTTL {
+		return c.health
+	}
+	c.health = evaluate(ctx, selectCheckers(liveness))
+	return c.health
+}
+
+func (c *cachedHealth) reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.health = nil
+}
+
+var (
+	livenessCache  = &cachedHealth{}
+	readinessCache = &cachedHealth{}
+)
+
+// 存活状态：失败时应重启进程
+func Liveness(ctx context.Context) *Health {
+	return livenessCache.get(ctx, true)
+}
+
+// 就绪状态：启动完成前为 STARTING，退出时为 OUT_OF_SERVICE，其余时间由就绪检查决定
+func Readiness(ctx context.Context) *Health {
+	if state := State(); state != STATUS_UP {
+		return &Health{Status: state, Time: time.Now()}
+	}
+
+	result := readinessCache.get(ctx, false)
+	notify(result.Status)
+	return result
+}
+
+// 当前就绪状态，用于注册中心心跳上报
+func ReadinessStatus(ctx context.Context) Status {
+	return Readiness(ctx).Status
+}
+
+var (
+	state      = STATUS_STARTING
+	lastStatus = STATUS_STARTING
+	listeners  = make([]func(status Status), 0)
+	stateMutex sync.RWMutex
+)
+
+// 应用生命周期状态：STARTING / UP / OUT_OF_SERVICE
+func State() Status {
+	stateMutex.RLock()
+	defer stateMutex.RUnlock()
+	return state
+}
+
+func setState(status Status) {
+	stateMutex.Lock()
+	state = status
+	stateMutex.Unlock()
+
+	readinessCache.reset()
+	notify(status)
+}
+
+// 启动完成，开始接收流量
+func MarkReady() {
+	setState(STATUS_UP)
+}
+
+// 主动下线，就绪检查返回 OUT_OF_SERVICE
+func MarkOutOfService() {
+	setState(STATUS_OUT_OF_SERVICE)
+}
+
+// 就绪状态变化时回调，如向注册中心上报
+func OnReadinessChanged(listener func(status Status)) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+	listeners = append(listeners, listener)
+}
+
+func notify(status Status) {
+	stateMutex.Lock()
+	if status == lastStatus {
+		stateMutex.Unlock()
+		return
+	}
+	logger.Info("[HEALTH] Readiness changed: ", lastStatus, " -> ", status)
+	lastStatus = status
+	list := append([]func(status Status){}, listeners...)
+	stateMutex.Unlock()
+
+	for _, listener := range list {
+		listener(status)
+	}
+}
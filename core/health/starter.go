@@ -0,0 +1,58 @@
+package health
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gophab/gophrame/core/engine"
+	"github.com/gophab/gophrame/core/health/config"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/router"
+	"github.com/gophab/gophrame/core/starter"
+)
+
+func init() {
+	starter.RegisterInitializorEx(Init, -100)
+	starter.RegisterStarter(Start)
+	// 全部模块启动后才就绪
+	starter.RegisterStarterEx(MarkReady, 0x7FFFFFFF)
+	// 先于其他模块下线并等待流量摘除
+	starter.RegisterTerminaterEx(Terminate, -0x7FFFFFFF)
+}
+
+func Init() {
+	logger.Debug("Initializing Health: ...", config.Setting.Enabled)
+	if config.Setting.Enabled {
+		engine.Get().Use(inflightMiddleware)
+	}
+}
+
+func Start() {
+	if config.Setting.Enabled && config.Setting.Path != "" {
+		path := strings.TrimSuffix(config.Setting.Path, "/")
+		router.Root().GET(path, ReadinessHandler())
+		router.Root().GET(path+"/live", LivenessHandler())
+		router.Root().GET(path+"/ready", ReadinessHandler())
+	}
+}
+
+// 标记下线，等待注册中心及负载均衡摘除流量，再等待处理中的请求完成
+func Terminate() {
+	MarkOutOfService()
+	if !config.Setting.Enabled {
+		return
+	}
+
+	if config.Setting.DrainDelay > 0 {
+		logger.Info("[HEALTH] Draining traffic for ", config.Setting.DrainDelay)
+		time.Sleep(config.Setting.DrainDelay)
+	}
+
+	deadline := time.Now().Add(config.Setting.DrainTimeout)
+	for Inflight() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 100)
+	}
+	if n := Inflight(); n > 0 {
+		logger.Warn("[HEALTH] Drain timeout, ", n, " requests still in flight")
+	}
+}
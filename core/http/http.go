@@ -1,12 +1,17 @@
 package http
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"io"
-	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
+
+	"github.com/gophab/gophrame/core/resilience"
+	"github.com/gophab/gophrame/core/tracing"
 )
 
 type HttpRequest struct {
@@ -17,9 +22,11 @@ type HttpRequest struct {
 	Body               interface{}
 	Username, Password string
 	ContentType        string
+	Client             *http.Client // 为空时使用 http.DefaultClient
 	Response           *http.Response
 	StatusCode         int
 	Error              error
+	ctx                context.Context
 	bytes              []byte
 	executed           bool
 }
@@ -78,10 +85,19 @@ func (r *HttpRequest) BODY(body interface{}) *HttpRequest {
 }
 
 func (r *HttpRequest) HEADER(head string, value string) *HttpRequest {
+	if r.Headers == nil {
+		r.Headers = make(map[string]string)
+	}
 	r.Headers[head] = value
 	return r
 }
 
+// 请求上下文，用于超时及取消
+func (r *HttpRequest) CONTEXT(ctx context.Context) *HttpRequest {
+	r.ctx = ctx
+	return r
+}
+
 func (r *HttpRequest) USERNAME(username string) *HttpRequest {
 	r.Username = username
 	return r
@@ -111,51 +127,92 @@ func (r *HttpRequest) Do() *HttpRequest {
 	}
 	r.executed = true
 
-	var (
-		body io.Reader
-		req  *http.Request
-		err  error
-	)
+	var body []byte
 	if r.Body != nil {
 		switch t := r.Body.(type) {
 		case string:
-			body = strings.NewReader(t)
+			body = []byte(t)
 		case []byte:
-			body = strings.NewReader(string(t))
+			body = t
 		default:
 			if bytes, err := json.Marshal(r.Body); err == nil {
-				body = strings.NewReader(string(bytes))
+				body = bytes
 			}
 		}
 	}
-	req, err = http.NewRequest(r.Method, r.fullURL(), body)
+
+	fullURL := r.fullURL()
+	target, err := url.Parse(fullURL)
 	if err != nil {
 		r.Error = err
 		return r
 	}
 
-	for k, v := range r.Headers {
-		req.Header.Set(k, v)
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
 	}
 
-	var resp *http.Response
-	resp, err = http.DefaultClient.Do(req)
-	if err != nil {
-		r.Error = err
-		return r
+	attempt := func(ctx context.Context) (int, error) {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, r.Method, fullURL, reader)
+		if err != nil {
+			return 0, err
+		}
+		for k, v := range r.Headers {
+			req.Header.Set(k, v)
+		}
+
+		ctx, span := tracing.Start(ctx, "HTTP "+r.Method, tracing.WithKind(tracing.SPAN_KIND_CLIENT), tracing.WithAttributes(map[string]interface{}{
+			"http.method":  r.Method,
+			"http.url":     fullURL,
+			"peer.service": target.Hostname(),
+		}))
+		defer span.End()
+		tracing.Inject(ctx, tracing.HeaderCarrier(req.Header))
+
+		client := r.Client
+		if client == nil {
+			client = http.DefaultClient
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			span.RecordError(err)
+			return 0, err
+		}
+		defer resp.Body.Close()
+
+		span.SetAttribute("http.status_code", resp.StatusCode)
+		if resp.StatusCode >= http.StatusInternalServerError {
+			span.SetStatus(tracing.STATUS_ERROR, resp.Status)
+		}
+
+		r.Response = resp
+		r.StatusCode = resp.StatusCode
+		r.bytes, err = io.ReadAll(resp.Body)
+		return resp.StatusCode, err
 	}
-	defer resp.Body.Close()
 
-	r.Response = resp
-	r.StatusCode = resp.StatusCode
-	r.executed = true
+	if !resilience.Enabled() {
+		_, r.Error = attempt(ctx)
+		return r
+	}
 
-	if bytes, err := ioutil.ReadAll(resp.Body); err == nil {
-		r.bytes = bytes
-	} else {
+	idempotent := resilience.IsIdempotent(r.Method) || r.Headers[resilience.HEADER_IDEMPOTENCY_KEY] != ""
+	if err := resilience.For(target.Hostname()).Execute(ctx, idempotent, attempt); err != nil {
 		r.Error = err
+		if fallback := resilience.GetFallback(target.Hostname()); fallback != nil {
+			request, _ := http.NewRequestWithContext(ctx, r.Method, fullURL, nil)
+			if resp, e := fallback(request, err); e == nil && resp != nil {
+				defer resp.Body.Close()
+				r.Response, r.StatusCode, r.Error = resp, resp.StatusCode, nil
+				r.bytes, r.Error = io.ReadAll(resp.Body)
+			}
+		}
 	}
-
 	return r
 }
 
@@ -0,0 +1,19 @@
+package config
+
+import (
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+type I18nSetting struct {
+	DefaultLanguage string `json:"defaultLanguage" yaml:"defaultLanguage"` // 请求未指定或不支持时使用的语言
+}
+
+var Setting *I18nSetting = &I18nSetting{
+	DefaultLanguage: "zh",
+}
+
+func init() {
+	logger.Debug("Register I18n Config")
+	config.RegisterConfig("i18n", Setting, "I18n Settings")
+}
@@ -0,0 +1,180 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 位于 errors 等底层包之下，不依赖配置包，默认语言由启动时按 i18n 配置设置
+var (
+	bundles         = make(map[string]map[string]string)
+	bundlesMutex    sync.RWMutex
+	defaultLanguage = "zh"
+)
+
+// 语言标签统一为小写及连字符，如 zh_CN -> zh-cn
+func normalize(language string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(language), "_", "-"))
+}
+
+// 注册语言包，同一语言多次注册时合并，后注册的覆盖同名消息
+func RegisterMessages(language string, messages map[string]string) {
+	bundlesMutex.Lock()
+	defer bundlesMutex.Unlock()
+
+	language = normalize(language)
+	bundle := bundles[language]
+	if bundle == nil {
+		bundle = make(map[string]string, len(messages))
+		bundles[language] = bundle
+	}
+	for key, message := range messages {
+		bundle[key] = message
+	}
+}
+
+func DefaultLanguage() string {
+	bundlesMutex.RLock()
+	defer bundlesMutex.RUnlock()
+	return defaultLanguage
+}
+
+func SetDefaultLanguage(language string) {
+	if language = normalize(language); language != "" {
+		bundlesMutex.Lock()
+		defaultLanguage = language
+		bundlesMutex.Unlock()
+	}
+}
+
+// 已注册的语言
+func Languages() []string {
+	bundlesMutex.RLock()
+	defer bundlesMutex.RUnlock()
+
+	result := make([]string, 0, len(bundles))
+	for language := range bundles {
+		result = append(result, language)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func lookup(language, key string) (string, bool) {
+	bundlesMutex.RLock()
+	defer bundlesMutex.RUnlock()
+
+	if bundle := bundles[language]; bundle != nil {
+		if message, ok := bundle[key]; ok {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+// 查找顺序：指定语言 -> 基础语言（zh-cn -> zh）-> 默认语言
+func Lookup(language, key string) (string, bool) {
+	language = normalize(language)
+	if message, ok := lookup(language, key); ok {
+		return message, true
+	}
+	if i := strings.Index(language, "-"); i > 0 {
+		if message, ok := lookup(language[:i], key); ok {
+			return message, true
+		}
+	}
+	return lookup(DefaultLanguage(), key)
+}
+
+// 翻译消息，args 不为空时按 fmt 格式化；未找到时返回 key
+func Translate(language, key string, args ...interface{}) string {
+	message, ok := Lookup(language, key)
+	if !ok {
+		message = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+func supported(language string) bool {
+	bundlesMutex.RLock()
+	defer bundlesMutex.RUnlock()
+	_, ok := bundles[language]
+	return ok
+}
+
+// 按 Accept-Language 及权重选择已注册的语言，均不支持时返回默认语言
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		language string
+		q        float64
+	}
+
+	candidates := make([]candidate, 0)
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		language := normalize(fields[0])
+		if language == "" || language == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, field := range fields[1:] {
+			if v := strings.TrimSpace(field); strings.HasPrefix(v, "q=") {
+				if f, err := strconv.ParseFloat(v[2:], 64); err == nil {
+					q = f
+				}
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{language, q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+
+	for _, c := range candidates {
+		if supported(c.language) {
+			return c.language
+		}
+		if i := strings.Index(c.language, "-"); i > 0 && supported(c.language[:i]) {
+			return c.language[:i]
+		}
+	}
+	return DefaultLanguage()
+}
+
+func RequestLanguage(r *http.Request) string {
+	if r == nil {
+		return DefaultLanguage()
+	}
+	return Negotiate(r.Header.Get("Accept-Language"))
+}
+
+// 当前请求的语言，支持 *gin.Context 及由其派生的 context
+func Language(ctx context.Context) string {
+	if c, ok := ctx.(*gin.Context); ok {
+		return RequestLanguage(c.Request)
+	}
+	if ctx != nil {
+		if c, ok := ctx.Value(gin.ContextKey).(*gin.Context); ok {
+			return RequestLanguage(c.Request)
+		}
+	}
+	return DefaultLanguage()
+}
+
+// 按当前请求的语言翻译
+func T(ctx context.Context, key string, args ...interface{}) string {
+	return Translate(Language(ctx), key, args...)
+}
@@ -0,0 +1,222 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gophab/gophrame/core/kafka/config"
+	"github.com/gophab/gophrame/core/logger"
+
+	"github.com/Shopify/sarama"
+)
+
+var (
+	ErrClientClosed = errors.New("kafka client closed")
+)
+
+// Kafka 客户端：共享 broker 连接，按需创建同步/异步生产者，管理消费组
+type Client struct {
+	client        sarama.Client
+	producer      sarama.SyncProducer
+	asyncProducer sarama.AsyncProducer
+	groups        []*ConsumerGroup
+	closed        bool
+	mutex         sync.RWMutex
+}
+
+func NewClient(setting *config.KafkaSetting) (*Client, error) {
+	cfg, err := saramaConfig(setting)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := sarama.NewClient(setting.Brokers, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{client: client}, nil
+}
+
+var (
+	defaultClient *Client
+	defaultMutex  sync.Mutex
+)
+
+// 默认客户端，使用 kafka 配置，连接失败时返回 nil
+func Default() *Client {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+
+	if defaultClient == nil {
+		client, err := NewClient(config.Setting)
+		if err != nil {
+			logger.Error("[KAFKA] Connect error: ", err.Error())
+			return nil
+		}
+		defaultClient = client
+	}
+	return defaultClient
+}
+
+func saramaConfig(setting *config.KafkaSetting) (*sarama.Config, error) {
+	result := sarama.NewConfig()
+	result.ClientID = setting.ClientId
+
+	if setting.Version != "" {
+		version, err := sarama.ParseKafkaVersion(setting.Version)
+		if err != nil {
+			return nil, err
+		}
+		result.Version = version
+	}
+
+	// 生产者
+	switch strings.ToLower(setting.Producer.RequiredAcks) {
+	case "none", "0":
+		result.Producer.RequiredAcks = sarama.NoResponse
+	case "leader", "1":
+		result.Producer.RequiredAcks = sarama.WaitForLocal
+	default:
+		result.Producer.RequiredAcks = sarama.WaitForAll
+	}
+
+	switch strings.ToLower(setting.Producer.Compression) {
+	case "gzip":
+		result.Producer.Compression = sarama.CompressionGZIP
+	case "snappy":
+		result.Producer.Compression = sarama.CompressionSnappy
+	case "lz4":
+		result.Producer.Compression = sarama.CompressionLZ4
+	case "zstd":
+		result.Producer.Compression = sarama.CompressionZSTD
+	default:
+		result.Producer.Compression = sarama.CompressionNone
+	}
+
+	if setting.Producer.MaxRetries > 0 {
+		result.Producer.Retry.Max = setting.Producer.MaxRetries
+	}
+	if setting.Producer.Timeout > 0 {
+		result.Producer.Timeout = setting.Producer.Timeout
+	}
+	if setting.Producer.Idempotent {
+		// 幂等生产要求：acks=all、单连接单请求、协议版本 >= 0.11
+		result.Producer.Idempotent = true
+		result.Producer.RequiredAcks = sarama.WaitForAll
+		result.Net.MaxOpenRequests = 1
+		if result.Producer.Retry.Max < 1 {
+			result.Producer.Retry.Max = 1
+		}
+		if !result.Version.IsAtLeast(sarama.V0_11_0_0) {
+			result.Version = sarama.V0_11_0_0
+		}
+	}
+	result.Producer.Return.Successes = true
+	result.Producer.Return.Errors = true
+
+	// 消费者
+	if strings.ToLower(setting.Consumer.InitialOffset) == "oldest" {
+		result.Consumer.Offsets.Initial = sarama.OffsetOldest
+	} else {
+		result.Consumer.Offsets.Initial = sarama.OffsetNewest
+	}
+	result.Consumer.Offsets.AutoCommit.Enable = setting.Consumer.AutoCommit
+	if setting.Consumer.AutoCommitInterval > 0 {
+		result.Consumer.Offsets.AutoCommit.Interval = setting.Consumer.AutoCommitInterval
+	}
+	if setting.Consumer.SessionTimeout > 0 {
+		result.Consumer.Group.Session.Timeout = setting.Consumer.SessionTimeout
+	}
+
+	// 安全
+	if setting.SASL.Enabled {
+		result.Net.SASL.Enable = true
+		result.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+		result.Net.SASL.User = setting.SASL.Username
+		result.Net.SASL.Password = setting.SASL.Password
+	}
+
+	if setting.TLS.Enabled {
+		tlsConfig, err := tlsConfig(&setting.TLS)
+		if err != nil {
+			return nil, err
+		}
+		result.Net.TLS.Enable = true
+		result.Net.TLS.Config = tlsConfig
+	}
+
+	return result, result.Validate()
+}
+
+func tlsConfig(setting *config.KafkaTLSSetting) (*tls.Config, error) {
+	result := &tls.Config{
+		ServerName:         setting.ServerName,
+		InsecureSkipVerify: setting.InsecureSkipVerify,
+	}
+
+	if setting.CAFile != "" {
+		data, err := os.ReadFile(setting.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		result.RootCAs = x509.NewCertPool()
+		if !result.RootCAs.AppendCertsFromPEM(data) {
+			return nil, errors.New("invalid kafka CA file: " + setting.CAFile)
+		}
+	}
+
+	if setting.CertFile != "" && setting.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(setting.CertFile, setting.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		result.Certificates = []tls.Certificate{cert}
+	}
+
+	return result, nil
+}
+
+// 底层 sarama 客户端
+func (c *Client) Sarama() sarama.Client {
+	return c.client
+}
+
+// 检测 broker 连接
+func (c *Client) Ping() error {
+	c.mutex.RLock()
+	closed := c.closed
+	c.mutex.RUnlock()
+
+	if closed || c.client.Closed() {
+		return ErrClientClosed
+	}
+	return c.client.RefreshMetadata()
+}
+
+func (c *Client) Close() error {
+	c.mutex.Lock()
+	if c.closed {
+		c.mutex.Unlock()
+		return nil
+	}
+	c.closed = true
+	groups := c.groups
+	c.groups = nil
+	c.mutex.Unlock()
+
+	// 先停止消费，再关闭生产者（等待异步消息发出），最后关闭连接
+	for _, group := range groups {
+		_ = group.Close()
+	}
+	if c.asyncProducer != nil {
+		_ = c.asyncProducer.Close()
+	}
+	if c.producer != nil {
+		_ = c.producer.Close()
+	}
+	return c.client.Close()
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"time"
+
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+type KafkaTLSSetting struct {
+	Enabled            bool   `json:"enabled"`
+	CAFile             string `json:"caFile" yaml:"caFile"`
+	CertFile           string `json:"certFile" yaml:"certFile"`
+	KeyFile            string `json:"keyFile" yaml:"keyFile"`
+	ServerName         string `json:"serverName" yaml:"serverName"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify" yaml:"insecureSkipVerify"`
+}
+
+type KafkaSASLSetting struct {
+	Enabled  bool   `json:"enabled"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type KafkaProducerSetting struct {
+	RequiredAcks string        `json:"requiredAcks" yaml:"requiredAcks"` // none / leader / all
+	Idempotent   bool          `json:"idempotent"`                       // 幂等生产，要求 requiredAcks=all
+	Compression  string        `json:"compression"`                      // none / gzip / snappy / lz4 / zstd
+	MaxRetries   int           `json:"maxRetries" yaml:"maxRetries"`
+	Timeout      time.Duration `json:"timeout"`
+}
+
+type KafkaConsumerSetting struct {
+	Group              string        `json:"group"`                              // 默认消费组
+	InitialOffset      string        `json:"initialOffset" yaml:"initialOffset"` // newest / oldest，无已提交位点时的起始位置
+	AutoCommit         bool          `json:"autoCommit" yaml:"autoCommit"`       // 定时自动提交，关闭时每条消息处理成功后同步提交
+	AutoCommitInterval time.Duration `json:"autoCommitInterval" yaml:"autoCommitInterval"`
+	SessionTimeout     time.Duration `json:"sessionTimeout" yaml:"sessionTimeout"`
+	MaxRetries         int           `json:"maxRetries" yaml:"maxRetries"` // 处理失败重试次数，超过后跳过，-1 无限重试
+	RetryBackoff       time.Duration `json:"retryBackoff" yaml:"retryBackoff"`
+}
+
+type KafkaSetting struct {
+	Enabled  bool                 `json:"enabled"`
+	Brokers  []string             `json:"brokers"`
+	ClientId string               `json:"clientId" yaml:"clientId"`
+	Version  string               `json:"version"` // Kafka 协议版本，如 2.8.0
+	TLS      KafkaTLSSetting      `json:"tls"`
+	SASL     KafkaSASLSetting     `json:"sasl"`
+	Producer KafkaProducerSetting `json:"producer"`
+	Consumer KafkaConsumerSetting `json:"consumer"`
+}
+
+var Setting *KafkaSetting = &KafkaSetting{
+	Enabled:  false,
+	ClientId: "gophrame",
+	Version:  "2.1.0",
+	Producer: KafkaProducerSetting{
+		RequiredAcks: "all",
+		Compression:  "none",
+		MaxRetries:   3,
+		Timeout:      time.Second * 10,
+	},
+	Consumer: KafkaConsumerSetting{
+		InitialOffset:      "newest",
+		AutoCommit:         true,
+		AutoCommitInterval: time.Second,
+		SessionTimeout:     time.Second * 10,
+		MaxRetries:         3,
+		RetryBackoff:       time.Second,
+	},
+}
+
+func init() {
+	logger.Debug("Register Kafka Config")
+	config.RegisterConfig("kafka", Setting, "Kafka Settings")
+}
@@ -0,0 +1,270 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/kafka/config"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/util"
+
+	"github.com/Shopify/sarama"
+)
+
+var ErrGroupRequired = errors.New("kafka consumer group required")
+
+type Message struct {
+	*sarama.ConsumerMessage
+}
+
+// 解码消息体，*[]byte/*string 原样读取，其他类型按 JSON 解码
+func (m *Message) Decode(v interface{}) error {
+	switch value := v.(type) {
+	case *[]byte:
+		*value = append((*value)[:0], m.Value...)
+		return nil
+	case *string:
+		*value = string(m.Value)
+		return nil
+	}
+	return json.Unmarshal(m.Value, v)
+}
+
+func (m *Message) Header(key string) string {
+	for _, header := range m.ConsumerMessage.Headers {
+		if header != nil && string(header.Key) == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (m *Message) Headers() map[string]string {
+	result := make(map[string]string, len(m.ConsumerMessage.Headers))
+	for _, header := range m.ConsumerMessage.Headers {
+		if header != nil {
+			result[string(header.Key)] = string(header.Value)
+		}
+	}
+	return result
+}
+
+// 消息处理函数，返回 nil 时标记位点；返回错误时按 consumer.maxRetries 重试，超过后跳过
+type Handler func(ctx context.Context, msg *Message) error
+
+// 消费组，rebalance 及连接中断后由 sarama 自动恢复
+type ConsumerGroup struct {
+	client    *Client
+	groupId   string
+	topics    []string
+	handler   Handler
+	group     sarama.ConsumerGroup
+	ctx       context.Context
+	cancel    context.CancelFunc
+	anonymous bool
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// 加入消费组订阅主题，groupId 为空时使用配置 consumer.group
+func (c *Client) Subscribe(groupId string, topics []string, handler Handler) (*ConsumerGroup, error) {
+	if groupId == "" {
+		groupId = config.Setting.Consumer.Group
+	}
+	if groupId == "" {
+		return nil, ErrGroupRequired
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return nil, ErrClientClosed
+	}
+
+	group, err := sarama.NewConsumerGroupFromClient(groupId, c.client)
+	if err != nil {
+		return nil, err
+	}
+	return c.startGroup(groupId, topics, handler, group, false), nil
+}
+
+// 匿名订阅：使用独立的临时消费组，每个订阅都收到全部消息，
+// 从最新位点开始消费，Close 时删除该消费组
+func (c *Client) SubscribeAnonymous(topics []string, handler Handler) (*ConsumerGroup, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return nil, ErrClientClosed
+	}
+
+	// 起始位点与配置无关，不能修改共享连接的配置，使用独立连接
+	cfg := *c.client.Config()
+	cfg.Consumer.Offsets.Initial = sarama.OffsetNewest
+
+	groupId := "anonymous-" + util.UUID()
+	group, err := sarama.NewConsumerGroup(c.brokerAddrs(), groupId, &cfg)
+	if err != nil {
+		return nil, err
+	}
+	return c.startGroup(groupId, topics, handler, group, true), nil
+}
+
+func (c *Client) brokerAddrs() []string {
+	brokers := c.client.Brokers()
+	result := make([]string, 0, len(brokers))
+	for _, broker := range brokers {
+		result = append(result, broker.Addr())
+	}
+	return result
+}
+
+// 调用方持有 c.mutex
+func (c *Client) startGroup(groupId string, topics []string, handler Handler, group sarama.ConsumerGroup, anonymous bool) *ConsumerGroup {
+	ctx, cancel := context.WithCancel(context.Background())
+	result := &ConsumerGroup{
+		client:    c,
+		groupId:   groupId,
+		topics:    topics,
+		handler:   handler,
+		group:     group,
+		anonymous: anonymous,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	c.groups = append(c.groups, result)
+
+	result.wg.Add(1)
+	go result.run()
+	return result
+}
+
+func (g *ConsumerGroup) run() {
+	defer g.wg.Done()
+
+	for {
+		// rebalance 后 Consume 返回，需要重新加入
+		if err := g.group.Consume(g.ctx, g.topics, g); err != nil {
+			if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+				return
+			}
+			logger.Warn("[KAFKA] Consume group [", g.groupId, "] error: ", err.Error())
+
+			select {
+			case <-g.ctx.Done():
+			case <-time.After(time.Second):
+			}
+		}
+
+		if g.ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (g *ConsumerGroup) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (g *ConsumerGroup) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (g *ConsumerGroup) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for {
+		select {
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+			if !g.handle(session, msg) {
+				return nil
+			}
+		case <-session.Context().Done():
+			return nil
+		}
+	}
+}
+
+// 处理消息并标记位点，会话结束（rebalance/停止）时返回 false，消息由新的分区持有者重新消费
+func (g *ConsumerGroup) handle(session sarama.ConsumerGroupSession, msg *sarama.ConsumerMessage) bool {
+	setting := config.Setting.Consumer
+
+	for attempt := 0; ; attempt++ {
+		err := g.call(session.Context(), &Message{ConsumerMessage: msg})
+		if err == nil {
+			break
+		}
+
+		logger.Warn("[KAFKA] Handle message ", msg.Topic, "/", msg.Partition, "/", msg.Offset, " attempt ", attempt+1, " error: ", err.Error())
+		if setting.MaxRetries >= 0 && attempt >= setting.MaxRetries {
+			logger.Error("[KAFKA] Skip message ", msg.Topic, "/", msg.Partition, "/", msg.Offset, " after ", attempt+1, " attempts")
+			break
+		}
+
+		select {
+		case <-session.Context().Done():
+			return false
+		case <-time.After(setting.RetryBackoff):
+		}
+	}
+
+	session.MarkMessage(msg, "")
+	if !setting.AutoCommit {
+		session.Commit()
+	}
+	return true
+}
+
+func (g *ConsumerGroup) call(ctx context.Context, msg *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("[KAFKA] Handle message of topic [", msg.Topic, "] panic: ", r)
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return g.handler(ctx, msg)
+}
+
+// 停止消费，等待处理中的消息完成并提交位点
+func (g *ConsumerGroup) Close() error {
+	var err error
+	g.closeOnce.Do(func() {
+		g.cancel()
+		g.wg.Wait()
+		err = g.group.Close()
+
+		if g.anonymous {
+			g.deleteGroup()
+		}
+
+		g.client.mutex.Lock()
+		for i, group := range g.client.groups {
+			if group == g {
+				g.client.groups = append(g.client.groups[:i], g.client.groups[i+1:]...)
+				break
+			}
+		}
+		g.client.mutex.Unlock()
+	})
+	return err
+}
+
+// 删除匿名消费组，失败时由 Kafka 按 offsets.retention 过期清理
+func (g *ConsumerGroup) deleteGroup() {
+	admin, err := sarama.NewClusterAdmin(g.client.brokerAddrs(), g.client.client.Config())
+	if err != nil {
+		logger.Warn("[KAFKA] Delete consumer group [", g.groupId, "] error: ", err.Error())
+		return
+	}
+	defer admin.Close()
+
+	if err := admin.DeleteConsumerGroup(g.groupId); err != nil {
+		logger.Warn("[KAFKA] Delete consumer group [", g.groupId, "] error: ", err.Error())
+	}
+}
@@ -0,0 +1,58 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gophab/gophrame/core/health"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/kafka/config"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/messaging"
+	MessagingConfig "github.com/gophab/gophrame/core/messaging/config"
+	"github.com/gophab/gophrame/core/module"
+	"github.com/gophab/gophrame/core/starter"
+)
+
+func init() {
+	starter.RegisterInitializor(Init)
+	starter.RegisterTerminater(Terminate)
+
+	module.RegisterBuiltinModule("kafka", "Kafka", func() bool {
+		return config.Setting.Enabled
+	}, func() error {
+		if defaultClient == nil {
+			return errors.New("kafka not available")
+		}
+		return defaultClient.Ping()
+	})
+
+	health.RegisterChecker(&health.Checker{
+		Name: "kafka",
+		Check: func(ctx context.Context) error {
+			if defaultClient == nil {
+				return errors.New("kafka not available")
+			}
+			return defaultClient.Ping()
+		},
+		Condition: func() bool { return config.Setting.Enabled },
+		Readiness: true,
+	})
+}
+
+func Init() {
+	logger.Debug("Initializing Kafka: ...", config.Setting.Enabled)
+	if config.Setting.Enabled {
+		if client := Default(); client != nil {
+			inject.InjectValue("kafka", client)
+			messaging.RegisterBroker(MessagingConfig.TRANSPORT_KAFKA, NewBroker(client))
+		}
+	}
+}
+
+func Terminate() {
+	if defaultClient != nil {
+		logger.Info("Closing Kafka client...")
+		_ = defaultClient.Close()
+	}
+}
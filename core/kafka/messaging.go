@@ -0,0 +1,48 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/gophab/gophrame/core/messaging"
+)
+
+// messaging.Broker 的 Kafka 实现：topic 即 Kafka 主题，group 即消费组
+type Broker struct {
+	client *Client
+}
+
+func NewBroker(client *Client) *Broker {
+	return &Broker{client: client}
+}
+
+func (b *Broker) Publish(ctx context.Context, topic string, msg *messaging.Message) error {
+	options := []SendOption{WithHeaders(msg.Headers)}
+	if msg.Key != "" {
+		options = append(options, WithKey(msg.Key))
+	}
+
+	_, _, err := b.client.Send(ctx, topic, msg.Body, options...)
+	return err
+}
+
+// group 为空时为匿名订阅，每个订阅独立成组收到全部消息
+func (b *Broker) Subscribe(topic, group string, handler messaging.Handler) (messaging.Subscription, error) {
+	wrapper := func(ctx context.Context, msg *Message) error {
+		return handler(ctx, &messaging.Message{
+			Topic:     msg.Topic,
+			Key:       string(msg.Key),
+			Headers:   msg.Headers(),
+			Body:      msg.Value,
+			Timestamp: msg.Timestamp,
+		})
+	}
+
+	if group == "" {
+		return b.client.SubscribeAnonymous([]string{topic}, wrapper)
+	}
+	return b.client.Subscribe(group, []string{topic}, wrapper)
+}
+
+func (b *Broker) Close() error {
+	return b.client.Close()
+}
@@ -0,0 +1,174 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gophab/gophrame/core/logger"
+
+	"github.com/Shopify/sarama"
+)
+
+type SendOption func(*sarama.ProducerMessage)
+
+// 消息键，相同键的消息进入同一分区并保持顺序
+func WithKey(key string) SendOption {
+	return func(msg *sarama.ProducerMessage) {
+		msg.Key = sarama.StringEncoder(key)
+	}
+}
+
+func WithHeader(key, value string) SendOption {
+	return func(msg *sarama.ProducerMessage) {
+		msg.Headers = append(msg.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+}
+
+func WithHeaders(headers map[string]string) SendOption {
+	return func(msg *sarama.ProducerMessage) {
+		for k, v := range headers {
+			WithHeader(k, v)(msg)
+		}
+	}
+}
+
+// 编码消息，[]byte/string 原样发送，其他类型编码为 JSON
+func NewMessage(topic string, value interface{}, options ...SendOption) (*sarama.ProducerMessage, error) {
+	result := &sarama.ProducerMessage{Topic: topic}
+
+	switch v := value.(type) {
+	case []byte:
+		result.Value = sarama.ByteEncoder(v)
+	case string:
+		result.Value = sarama.StringEncoder(v)
+	case sarama.Encoder:
+		result.Value = v
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		result.Value = sarama.ByteEncoder(data)
+	}
+
+	for _, option := range options {
+		option(result)
+	}
+	return result, nil
+}
+
+func (c *Client) syncProducer() (sarama.SyncProducer, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return nil, ErrClientClosed
+	}
+	if c.producer == nil {
+		producer, err := sarama.NewSyncProducerFromClient(c.client)
+		if err != nil {
+			return nil, err
+		}
+		c.producer = producer
+	}
+	return c.producer, nil
+}
+
+// 同步发送，等待 broker 按 requiredAcks 确认，返回消息所在分区及位点
+func (c *Client) Send(ctx context.Context, topic string, value interface{}, options ...SendOption) (partition int32, offset int64, err error) {
+	msg, err := NewMessage(topic, value, options...)
+	if err != nil {
+		return -1, -1, err
+	}
+
+	producer, err := c.syncProducer()
+	if err != nil {
+		return -1, -1, err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := producer.SendMessage(msg)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return -1, -1, err
+		}
+		return msg.Partition, msg.Offset, nil
+	case <-ctx.Done():
+		// 消息可能仍会发出
+		return -1, -1, ctx.Err()
+	}
+}
+
+// 异步发送结果回调，err 为 nil 表示发送成功
+type Callback func(msg *sarama.ProducerMessage, err error)
+
+func (c *Client) asyncProducerOf() (sarama.AsyncProducer, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return nil, ErrClientClosed
+	}
+	if c.asyncProducer == nil {
+		producer, err := sarama.NewAsyncProducerFromClient(c.client)
+		if err != nil {
+			return nil, err
+		}
+		c.asyncProducer = producer
+		go c.dispatch(producer)
+	}
+	return c.asyncProducer, nil
+}
+
+func (c *Client) dispatch(producer sarama.AsyncProducer) {
+	successes, errors := producer.Successes(), producer.Errors()
+	for successes != nil || errors != nil {
+		select {
+		case msg, ok := <-successes:
+			if !ok {
+				successes = nil
+				continue
+			}
+			if callback, b := msg.Metadata.(Callback); b && callback != nil {
+				callback(msg, nil)
+			}
+		case perr, ok := <-errors:
+			if !ok {
+				errors = nil
+				continue
+			}
+			if callback, b := perr.Msg.Metadata.(Callback); b && callback != nil {
+				callback(perr.Msg, perr.Err)
+			} else {
+				logger.Error("[KAFKA] Send message to [", perr.Msg.Topic, "] error: ", perr.Err.Error())
+			}
+		}
+	}
+}
+
+// 异步发送，结果通过 callback 返回（可为 nil，失败时记录日志）
+func (c *Client) SendAsync(topic string, value interface{}, callback Callback, options ...SendOption) error {
+	msg, err := NewMessage(topic, value, options...)
+	if err != nil {
+		return err
+	}
+	msg.Metadata = callback
+
+	producer, err := c.asyncProducerOf()
+	if err != nil {
+		return err
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if c.closed {
+		return ErrClientClosed
+	}
+	producer.Input() <- msg
+	return nil
+}
@@ -0,0 +1,185 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+)
+
+var (
+	// 锁已被其他持有者占用
+	ErrNotAcquired = errors.New("lock not acquired")
+	// 锁已不属于当前持有者（已过期或被释放）
+	ErrNotHeld = errors.New("lock not held")
+)
+
+// 分布式锁
+type Lock interface {
+	// 锁名称
+	Key() string
+
+	// 持有者令牌，仅令牌匹配时才能续期/释放
+	Token() string
+
+	// 续期
+	Refresh(ctx context.Context, ttl time.Duration) error
+
+	// 释放，锁已不属于当前持有者时返回 ErrNotHeld
+	Unlock(ctx context.Context) error
+}
+
+type Locker interface {
+	// 尝试获取锁，锁被占用时立即返回 ErrNotAcquired
+	TryLock(ctx context.Context, key string, options ...Option) (Lock, error)
+
+	// 阻塞获取锁，直到成功或 ctx 结束
+	Lock(ctx context.Context, key string, options ...Option) (Lock, error)
+}
+
+type Options struct {
+	TTL      time.Duration // 租约时长
+	Watchdog bool          // 持有期间自动续期
+	RetryMin time.Duration // 阻塞获取时最小重试间隔
+	RetryMax time.Duration // 阻塞获取时最大重试间隔
+}
+
+type Option func(*Options)
+
+func WithTTL(ttl time.Duration) Option {
+	return func(o *Options) {
+		o.TTL = ttl
+	}
+}
+
+func WithWatchdog(watchdog bool) Option {
+	return func(o *Options) {
+		o.Watchdog = watchdog
+	}
+}
+
+func WithRetryBackoff(min, max time.Duration) Option {
+	return func(o *Options) {
+		o.RetryMin = min
+		o.RetryMax = max
+	}
+}
+
+var DefaultOptions = Options{
+	TTL:      30 * time.Second,
+	Watchdog: true,
+	RetryMin: 10 * time.Millisecond,
+	RetryMax: 500 * time.Millisecond,
+}
+
+func NewOptions(options ...Option) *Options {
+	result := DefaultOptions
+	for _, option := range options {
+		option(&result)
+	}
+	if result.RetryMin <= 0 {
+		result.RetryMin = DefaultOptions.RetryMin
+	}
+	if result.RetryMax < result.RetryMin {
+		result.RetryMax = result.RetryMin
+	}
+	return &result
+}
+
+// 生成持有者令牌
+func NewToken() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(buf)
+}
+
+// 阻塞获取：指数退避 + 随机抖动，ctx 结束时返回 ctx.Err()
+func Acquire(ctx context.Context, options *Options, try func() (Lock, error)) (Lock, error) {
+	backoff := options.RetryMin
+	for {
+		result, err := try()
+		if err == nil {
+			return result, nil
+		}
+		if err != ErrNotAcquired {
+			return nil, err
+		}
+
+		wait := backoff/2 + jitter(backoff/2)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		if backoff *= 2; backoff > options.RetryMax {
+			backoff = options.RetryMax
+		}
+	}
+}
+
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	if n, err := rand.Int(rand.Reader, big.NewInt(int64(max))); err == nil {
+		return time.Duration(n.Int64())
+	}
+	return 0
+}
+
+// 看门狗：每 ttl/3 续期一次，续期失败或 Stop 后退出
+type Watchdog struct {
+	stop chan struct{}
+	once sync.Once
+}
+
+func StartWatchdog(l Lock, ttl time.Duration) *Watchdog {
+	result := &Watchdog{stop: make(chan struct{})}
+
+	interval := ttl / 3
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-result.stop:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), interval)
+				err := l.Refresh(ctx, ttl)
+				cancel()
+				if err != nil {
+					logger.Warn("[LOCK] Refresh lock [", l.Key(), "] error: ", err.Error())
+					if err == ErrNotHeld {
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return result
+}
+
+func (w *Watchdog) Stop() {
+	if w != nil {
+		w.once.Do(func() {
+			close(w.stop)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	token    string
+	expireAt time.Time
+}
+
+// 进程内锁，语义与分布式锁一致，用于单机部署及测试
+type MemoryLocker struct {
+	entries map[string]*memoryEntry
+	mutex   sync.Mutex
+}
+
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{
+		entries: make(map[string]*memoryEntry),
+	}
+}
+
+func (m *MemoryLocker) TryLock(ctx context.Context, key string, options ...Option) (Lock, error) {
+	return m.tryLock(key, NewOptions(options...))
+}
+
+func (m *MemoryLocker) Lock(ctx context.Context, key string, options ...Option) (Lock, error) {
+	opts := NewOptions(options...)
+	return Acquire(ctx, opts, func() (Lock, error) {
+		return m.tryLock(key, opts)
+	})
+}
+
+func (m *MemoryLocker) tryLock(key string, options *Options) (Lock, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	if entry, b := m.entries[key]; b && entry.expireAt.After(now) {
+		return nil, ErrNotAcquired
+	}
+
+	result := &memoryLock{locker: m, key: key, token: NewToken()}
+	m.entries[key] = &memoryEntry{token: result.token, expireAt: now.Add(options.TTL)}
+
+	if options.Watchdog {
+		result.watchdog = StartWatchdog(result, options.TTL)
+	}
+	return result, nil
+}
+
+type memoryLock struct {
+	locker   *MemoryLocker
+	key      string
+	token    string
+	watchdog *Watchdog
+}
+
+func (l *memoryLock) Key() string {
+	return l.key
+}
+
+func (l *memoryLock) Token() string {
+	return l.token
+}
+
+func (l *memoryLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	l.locker.mutex.Lock()
+	defer l.locker.mutex.Unlock()
+
+	if entry, b := l.locker.entries[l.key]; b && entry.token == l.token && entry.expireAt.After(time.Now()) {
+		entry.expireAt = time.Now().Add(ttl)
+		return nil
+	}
+	return ErrNotHeld
+}
+
+func (l *memoryLock) Unlock(ctx context.Context) error {
+	l.watchdog.Stop()
+
+	l.locker.mutex.Lock()
+	defer l.locker.mutex.Unlock()
+
+	if entry, b := l.locker.entries[l.key]; b && entry.token == l.token {
+		delete(l.locker.entries, l.key)
+		if entry.expireAt.After(time.Now()) {
+			return nil
+		}
+	}
+	return ErrNotHeld
+}
@@ -0,0 +1,219 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryLockerTryLock(t *testing.T) {
+	tests := []struct {
+		name    string
+		hold    bool          // 先由另一持有者获取
+		ttl     time.Duration // 另一持有者的租约
+		wait    time.Duration // 获取前等待
+		wantErr error
+	}{
+		{name: "free", wantErr: nil},
+		{name: "held", hold: true, ttl: time.Minute, wantErr: ErrNotAcquired},
+		{name: "expired", hold: true, ttl: 20 * time.Millisecond, wait: 50 * time.Millisecond, wantErr: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locker := NewMemoryLocker()
+			ctx := context.Background()
+
+			if tt.hold {
+				if _, err := locker.TryLock(ctx, "key", WithTTL(tt.ttl), WithWatchdog(false)); err != nil {
+					t.Fatalf("first TryLock error: %v", err)
+				}
+			}
+			time.Sleep(tt.wait)
+
+			l, err := locker.TryLock(ctx, "key", WithTTL(time.Minute), WithWatchdog(false))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("TryLock error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (l.Key() != "key" || l.Token() == "") {
+				t.Fatalf("unexpected lock key=%q token=%q", l.Key(), l.Token())
+			}
+		})
+	}
+}
+
+func TestMemoryLockUnlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		wait    time.Duration
+		steal   bool // 过期后被其他持有者获取
+		wantErr error
+	}{
+		{name: "held", ttl: time.Minute, wantErr: nil},
+		{name: "expired", ttl: 20 * time.Millisecond, wait: 50 * time.Millisecond, wantErr: ErrNotHeld},
+		{name: "stolen", ttl: 20 * time.Millisecond, wait: 50 * time.Millisecond, steal: true, wantErr: ErrNotHeld},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locker := NewMemoryLocker()
+			ctx := context.Background()
+
+			l, err := locker.TryLock(ctx, "key", WithTTL(tt.ttl), WithWatchdog(false))
+			if err != nil {
+				t.Fatalf("TryLock error: %v", err)
+			}
+			time.Sleep(tt.wait)
+
+			var other Lock
+			if tt.steal {
+				if other, err = locker.TryLock(ctx, "key", WithTTL(time.Minute), WithWatchdog(false)); err != nil {
+					t.Fatalf("steal TryLock error: %v", err)
+				}
+			}
+
+			if err := l.Unlock(ctx); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Unlock error = %v, want %v", err, tt.wantErr)
+			}
+
+			// 释放不能删除其他持有者的锁
+			if other != nil {
+				if _, err := locker.TryLock(ctx, "key", WithWatchdog(false)); !errors.Is(err, ErrNotAcquired) {
+					t.Fatalf("lock of other holder released, TryLock error = %v", err)
+				}
+				if err := other.Unlock(ctx); err != nil {
+					t.Fatalf("other Unlock error: %v", err)
+				}
+			}
+
+			if _, err := locker.TryLock(ctx, "key", WithWatchdog(false)); err != nil {
+				t.Fatalf("TryLock after release error: %v", err)
+			}
+		})
+	}
+}
+
+func TestMemoryLockRefresh(t *testing.T) {
+	locker := NewMemoryLocker()
+	ctx := context.Background()
+
+	l, err := locker.TryLock(ctx, "key", WithTTL(30*time.Millisecond), WithWatchdog(false))
+	if err != nil {
+		t.Fatalf("TryLock error: %v", err)
+	}
+	if err := l.Refresh(ctx, time.Minute); err != nil {
+		t.Fatalf("Refresh error: %v", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if _, err := locker.TryLock(ctx, "key", WithWatchdog(false)); !errors.Is(err, ErrNotAcquired) {
+		t.Fatalf("TryLock after refresh error = %v, want %v", err, ErrNotAcquired)
+	}
+
+	if err := l.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock error: %v", err)
+	}
+	if err := l.Refresh(ctx, time.Minute); !errors.Is(err, ErrNotHeld) {
+		t.Fatalf("Refresh after unlock error = %v, want %v", err, ErrNotHeld)
+	}
+}
+
+func TestMemoryLockWatchdog(t *testing.T) {
+	locker := NewMemoryLocker()
+	ctx := context.Background()
+
+	l, err := locker.TryLock(ctx, "key", WithTTL(60*time.Millisecond), WithWatchdog(true))
+	if err != nil {
+		t.Fatalf("TryLock error: %v", err)
+	}
+
+	// 超过多个租约周期后仍由看门狗续期持有
+	time.Sleep(200 * time.Millisecond)
+	if _, err := locker.TryLock(ctx, "key", WithWatchdog(false)); !errors.Is(err, ErrNotAcquired) {
+		t.Fatalf("TryLock while watchdog running error = %v, want %v", err, ErrNotAcquired)
+	}
+
+	if err := l.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock error: %v", err)
+	}
+	if _, err := locker.TryLock(ctx, "key", WithWatchdog(false)); err != nil {
+		t.Fatalf("TryLock after unlock error: %v", err)
+	}
+}
+
+func TestMemoryLockerLock(t *testing.T) {
+	t.Run("wait for release", func(t *testing.T) {
+		locker := NewMemoryLocker()
+		ctx := context.Background()
+
+		l, err := locker.TryLock(ctx, "key", WithWatchdog(false))
+		if err != nil {
+			t.Fatalf("TryLock error: %v", err)
+		}
+		time.AfterFunc(30*time.Millisecond, func() { _ = l.Unlock(ctx) })
+
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		if _, err := locker.Lock(ctx, "key", WithWatchdog(false), WithRetryBackoff(5*time.Millisecond, 20*time.Millisecond)); err != nil {
+			t.Fatalf("Lock error: %v", err)
+		}
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		locker := NewMemoryLocker()
+		if _, err := locker.TryLock(context.Background(), "key", WithWatchdog(false)); err != nil {
+			t.Fatalf("TryLock error: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+		defer cancel()
+		if _, err := locker.Lock(ctx, "key", WithWatchdog(false)); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Lock error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("mutual exclusion", func(t *testing.T) {
+		locker := NewMemoryLocker()
+		ctx := context.Background()
+
+		var (
+			wg      sync.WaitGroup
+			mutex   sync.Mutex
+			holders int
+			maxSeen int
+		)
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				l, err := locker.Lock(ctx, "key", WithWatchdog(false), WithRetryBackoff(time.Millisecond, 5*time.Millisecond))
+				if err != nil {
+					t.Errorf("Lock error: %v", err)
+					return
+				}
+
+				mutex.Lock()
+				holders++
+				if holders > maxSeen {
+					maxSeen = holders
+				}
+				mutex.Unlock()
+
+				time.Sleep(2 * time.Millisecond)
+
+				mutex.Lock()
+				holders--
+				mutex.Unlock()
+				_ = l.Unlock(ctx)
+			}()
+		}
+		wg.Wait()
+
+		if maxSeen != 1 {
+			t.Fatalf("concurrent holders = %d, want 1", maxSeen)
+		}
+	})
+}
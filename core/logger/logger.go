@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -65,3 +66,63 @@ func Fatal(args ...interface{}) {
 	Logger.Output(2, fmt.Sprintln(args...))
 	os.Exit(1)
 }
+
+// 从上下文提取附加到日志行的字段（如链路 ID）
+type ContextFields func(ctx context.Context) string
+
+var contextFields []ContextFields
+
+func RegisterContextFields(f ContextFields) {
+	contextFields = append(contextFields, f)
+}
+
+// 上下文中的日志字段，多个以空格分隔
+func Fields(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	result := ""
+	for _, f := range contextFields {
+		if fields := f(ctx); fields != "" {
+			if result != "" {
+				result += " "
+			}
+			result += fields
+		}
+	}
+	return result
+}
+
+// 携带上下文字段的日志
+type ContextLogger struct {
+	fields string
+}
+
+func WithContext(ctx context.Context) *ContextLogger {
+	return &ContextLogger{fields: Fields(ctx)}
+}
+
+func (l *ContextLogger) output(prefix string, args []interface{}) {
+	if l.fields != "" {
+		args = append([]interface{}{"[" + l.fields + "]"}, args...)
+	}
+	Logger.SetPrefix(prefix)
+	Logger.Output(3, fmt.Sprintln(args...))
+}
+
+func (l *ContextLogger) Info(args ...interface{}) {
+	l.output("[INFO]\t", args)
+}
+
+func (l *ContextLogger) Warn(args ...interface{}) {
+	l.output("[WARNING]\t", args)
+}
+
+func (l *ContextLogger) Debug(args ...interface{}) {
+	l.output("[DEBUG]\t", args)
+}
+
+func (l *ContextLogger) Error(args ...interface{}) {
+	l.output("[ERROR]\t", args)
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+const (
+	TRANSPORT_MEMORY   = "memory"
+	TRANSPORT_RABBITMQ = "rabbitmq"
+	TRANSPORT_KAFKA    = "kafka"
+)
+
+type MessagingSetting struct {
+	Transport string `json:"transport"` // memory / rabbitmq / kafka
+}
+
+var Setting *MessagingSetting = &MessagingSetting{
+	Transport: TRANSPORT_MEMORY,
+}
+
+func init() {
+	logger.Debug("Register Messaging Config")
+	config.RegisterConfig("messaging", Setting, "Messaging Settings")
+}
@@ -0,0 +1,180 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gophab/gophrame/core/logger"
+)
+
+var ErrBrokerClosed = errors.New("messaging broker closed")
+
+// 进程内传输，用于测试及单机部署，消息不持久化
+type MemoryBroker struct {
+	topics   map[string]map[string]*memoryGroup
+	sequence int64
+	closed   bool
+	mutex    sync.RWMutex
+}
+
+type memoryGroup struct {
+	subscriptions []*memorySubscription
+	next          uint32
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		topics: make(map[string]map[string]*memoryGroup),
+	}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, topic string, msg *Message) error {
+	b.mutex.RLock()
+	if b.closed {
+		b.mutex.RUnlock()
+		return ErrBrokerClosed
+	}
+
+	// 每个 group 轮询选择一个订阅者
+	targets := make([]*memorySubscription, 0)
+	for _, group := range b.topics[topic] {
+		if len(group.subscriptions) > 0 {
+			n := atomic.AddUint32(&group.next, 1)
+			targets = append(targets, group.subscriptions[int(n)%len(group.subscriptions)])
+		}
+	}
+	b.mutex.RUnlock()
+
+	for _, target := range targets {
+		copied := *msg
+		copied.Topic = topic
+		select {
+		case target.queue <- &copied:
+		case <-target.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(topic, group string, handler Handler) (Subscription, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return nil, ErrBrokerClosed
+	}
+
+	if group == "" {
+		// 匿名订阅独立成组
+		group = fmt.Sprintf("~%d", atomic.AddInt64(&b.sequence, 1))
+	}
+
+	groups := b.topics[topic]
+	if groups == nil {
+		groups = make(map[string]*memoryGroup)
+		b.topics[topic] = groups
+	}
+	if groups[group] == nil {
+		groups[group] = &memoryGroup{}
+	}
+
+	result := &memorySubscription{
+		broker:  b,
+		topic:   topic,
+		group:   group,
+		handler: handler,
+		queue:   make(chan *Message, 256),
+		done:    make(chan struct{}),
+	}
+	groups[group].subscriptions = append(groups[group].subscriptions, result)
+
+	result.wg.Add(1)
+	go result.run()
+	return result, nil
+}
+
+func (b *MemoryBroker) remove(s *memorySubscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	group := b.topics[s.topic][s.group]
+	if group == nil {
+		return
+	}
+	for i, subscription := range group.subscriptions {
+		if subscription == s {
+			group.subscriptions = append(group.subscriptions[:i], group.subscriptions[i+1:]...)
+			break
+		}
+	}
+	if len(group.subscriptions) == 0 {
+		delete(b.topics[s.topic], s.group)
+	}
+}
+
+func (b *MemoryBroker) Close() error {
+	b.mutex.Lock()
+	b.closed = true
+	subscriptions := make([]*memorySubscription, 0)
+	for _, groups := range b.topics {
+		for _, group := range groups {
+			subscriptions = append(subscriptions, group.subscriptions...)
+		}
+	}
+	b.mutex.Unlock()
+
+	for _, subscription := range subscriptions {
+		_ = subscription.Close()
+	}
+	return nil
+}
+
+type memorySubscription struct {
+	broker    *MemoryBroker
+	topic     string
+	group     string
+	handler   Handler
+	queue     chan *Message
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+func (s *memorySubscription) run() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case msg := <-s.queue:
+			s.handle(msg)
+		}
+	}
+}
+
+func (s *memorySubscription) handle(msg *Message) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("[MESSAGING] Handle message of topic [", s.topic, "] panic: ", r)
+		}
+	}()
+
+	if err := s.handler(context.Background(), msg); err != nil {
+		logger.Warn("[MESSAGING] Handle message of topic [", s.topic, "] error: ", err.Error())
+	}
+}
+
+func (s *memorySubscription) Close() error {
+	s.closeOnce.Do(func() {
+		s.broker.remove(s)
+		close(s.done)
+		s.wg.Wait()
+	})
+	return nil
+}
@@ -0,0 +1,196 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testSubscriber struct {
+	topic string
+	group string
+}
+
+// 等待处理完成，超时返回 false
+func eventually(condition func() bool) bool {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return condition()
+}
+
+func TestMemoryBrokerGroups(t *testing.T) {
+	tests := []struct {
+		name        string
+		subscribers []testSubscriber
+		publish     int
+		want        []int // 每个订阅者收到的消息数，-1 表示不检查单个订阅者
+		wantTotal   int
+	}{
+		{
+			name:        "anonymous subscribers each receive all",
+			subscribers: []testSubscriber{{"orders", ""}, {"orders", ""}, {"orders", ""}},
+			publish:     4,
+			want:        []int{4, 4, 4},
+			wantTotal:   12,
+		},
+		{
+			name:        "same group shares messages round robin",
+			subscribers: []testSubscriber{{"orders", "billing"}, {"orders", "billing"}},
+			publish:     4,
+			want:        []int{2, 2},
+			wantTotal:   4,
+		},
+		{
+			name:        "different groups each receive all",
+			subscribers: []testSubscriber{{"orders", "billing"}, {"orders", "billing"}, {"orders", "audit"}},
+			publish:     6,
+			want:        []int{-1, -1, 6},
+			wantTotal:   12,
+		},
+		{
+			name:        "other topics not delivered",
+			subscribers: []testSubscriber{{"orders", ""}, {"users", ""}},
+			publish:     3,
+			want:        []int{3, 0},
+			wantTotal:   3,
+		},
+		{
+			name:        "no subscribers",
+			subscribers: []testSubscriber{},
+			publish:     3,
+			want:        []int{},
+			wantTotal:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := NewMemoryBroker()
+			defer broker.Close()
+
+			counts := make([]int64, len(tt.subscribers))
+			for i, s := range tt.subscribers {
+				i, s := i, s
+				if _, err := broker.Subscribe(s.topic, s.group, func(ctx context.Context, msg *Message) error {
+					if msg.Topic != s.topic {
+						t.Errorf("message topic = %q, want %q", msg.Topic, s.topic)
+					}
+					atomic.AddInt64(&counts[i], 1)
+					return nil
+				}); err != nil {
+					t.Fatalf("Subscribe error: %v", err)
+				}
+			}
+
+			for i := 0; i < tt.publish; i++ {
+				if err := broker.Publish(context.Background(), "orders", &Message{Body: []byte("order")}); err != nil {
+					t.Fatalf("Publish error: %v", err)
+				}
+			}
+
+			total := func() int {
+				result := 0
+				for i := range counts {
+					result += int(atomic.LoadInt64(&counts[i]))
+				}
+				return result
+			}
+			if !eventually(func() bool { return total() >= tt.wantTotal }) || total() != tt.wantTotal {
+				t.Fatalf("total delivered = %d, want %d", total(), tt.wantTotal)
+			}
+
+			for i, want := range tt.want {
+				if got := int(atomic.LoadInt64(&counts[i])); want >= 0 && got != want {
+					t.Fatalf("subscriber %d received %d, want %d", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMemorySubscriptionClose(t *testing.T) {
+	broker := NewMemoryBroker()
+	defer broker.Close()
+
+	var closedCount, activeCount int64
+	closed, err := broker.Subscribe("orders", "billing", func(ctx context.Context, msg *Message) error {
+		atomic.AddInt64(&closedCount, 1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Subscribe error: %v", err)
+	}
+	if _, err := broker.Subscribe("orders", "billing", func(ctx context.Context, msg *Message) error {
+		atomic.AddInt64(&activeCount, 1)
+		return nil
+	}); err != nil {
+		t.Fatalf("Subscribe error: %v", err)
+	}
+
+	if err := closed.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+
+	// 关闭的订阅者退出分组，消息全部由剩余成员处理
+	for i := 0; i < 4; i++ {
+		if err := broker.Publish(context.Background(), "orders", &Message{}); err != nil {
+			t.Fatalf("Publish error: %v", err)
+		}
+	}
+	if !eventually(func() bool { return atomic.LoadInt64(&activeCount) == 4 }) {
+		t.Fatalf("active subscriber received %d, want 4", atomic.LoadInt64(&activeCount))
+	}
+	if n := atomic.LoadInt64(&closedCount); n != 0 {
+		t.Fatalf("closed subscriber received %d, want 0", n)
+	}
+}
+
+func TestMemoryBrokerHandlerFailure(t *testing.T) {
+	broker := NewMemoryBroker()
+	defer broker.Close()
+
+	var handled int64
+	if _, err := broker.Subscribe("orders", "", func(ctx context.Context, msg *Message) error {
+		n := atomic.AddInt64(&handled, 1)
+		switch n {
+		case 1:
+			panic("handler panic")
+		case 2:
+			return errors.New("handler error")
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("Subscribe error: %v", err)
+	}
+
+	// 处理函数出错或 panic 不影响后续消息
+	for i := 0; i < 3; i++ {
+		if err := broker.Publish(context.Background(), "orders", &Message{}); err != nil {
+			t.Fatalf("Publish error: %v", err)
+		}
+	}
+	if !eventually(func() bool { return atomic.LoadInt64(&handled) == 3 }) {
+		t.Fatalf("handled %d messages, want 3", atomic.LoadInt64(&handled))
+	}
+}
+
+func TestMemoryBrokerClosed(t *testing.T) {
+	broker := NewMemoryBroker()
+	if err := broker.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+
+	if err := broker.Publish(context.Background(), "orders", &Message{}); !errors.Is(err, ErrBrokerClosed) {
+		t.Fatalf("Publish error = %v, want %v", err, ErrBrokerClosed)
+	}
+	if _, err := broker.Subscribe("orders", "", func(ctx context.Context, msg *Message) error { return nil }); !errors.Is(err, ErrBrokerClosed) {
+		t.Fatalf("Subscribe error = %v, want %v", err, ErrBrokerClosed)
+	}
+}
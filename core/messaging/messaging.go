@@ -0,0 +1,131 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/messaging/config"
+)
+
+// 与传输无关的消息
+type Message struct {
+	Topic     string            // 接收时填充
+	Key       string            // 分区/顺序键
+	Headers   map[string]string //
+	Body      []byte
+	Timestamp time.Time
+}
+
+// 创建消息，[]byte/string 原样发送，其他类型编码为 JSON
+func NewMessage(body interface{}) (*Message, error) {
+	result := &Message{
+		Headers:   map[string]string{},
+		Timestamp: time.Now(),
+	}
+
+	switch value := body.(type) {
+	case []byte:
+		result.Body = value
+	case string:
+		result.Body = []byte(value)
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		result.Body = data
+	}
+	return result, nil
+}
+
+func (m *Message) WithKey(key string) *Message {
+	m.Key = key
+	return m
+}
+
+func (m *Message) WithHeader(key, value string) *Message {
+	if m.Headers == nil {
+		m.Headers = map[string]string{}
+	}
+	m.Headers[key] = value
+	return m
+}
+
+// 解码消息体，*[]byte/*string 原样读取，其他类型按 JSON 解码
+func (m *Message) Decode(v interface{}) error {
+	switch value := v.(type) {
+	case *[]byte:
+		*value = append((*value)[:0], m.Body...)
+		return nil
+	case *string:
+		*value = string(m.Body)
+		return nil
+	}
+	return json.Unmarshal(m.Body, v)
+}
+
+// 消息处理函数，返回 nil 表示处理成功（确认/提交），返回错误由具体传输决定重试策略
+type Handler func(ctx context.Context, msg *Message) error
+
+type Publisher interface {
+	Publish(ctx context.Context, topic string, msg *Message) error
+}
+
+type Subscription interface {
+	// 停止订阅，等待处理中的消息完成
+	Close() error
+}
+
+type Subscriber interface {
+	// 订阅主题，同一 group 的订阅者分摊消息，不同 group 各自收到全部消息
+	Subscribe(topic, group string, handler Handler) (Subscription, error)
+}
+
+// 消息传输：RabbitMQ / Kafka / 内存
+type Broker interface {
+	Publisher
+	Subscriber
+	Close() error
+}
+
+var (
+	brokers      = make(map[string]Broker)
+	brokersMutex sync.RWMutex
+)
+
+// 注册传输实现，由各模块在初始化时注册
+func RegisterBroker(name string, broker Broker) {
+	brokersMutex.Lock()
+	defer brokersMutex.Unlock()
+	brokers[name] = broker
+}
+
+func GetBroker(name string) Broker {
+	brokersMutex.RLock()
+	defer brokersMutex.RUnlock()
+	return brokers[name]
+}
+
+// 配置 messaging.transport 指定的传输，未注册（模块未启用）时使用内存实现
+func Default() Broker {
+	if broker := GetBroker(config.Setting.Transport); broker != nil {
+		return broker
+	}
+	logger.Warn("[MESSAGING] Transport not available, fallback to memory: ", config.Setting.Transport)
+	return GetBroker(config.TRANSPORT_MEMORY)
+}
+
+func Publish(ctx context.Context, topic string, msg *Message) error {
+	return Default().Publish(ctx, topic, msg)
+}
+
+func Subscribe(topic, group string, handler Handler) (Subscription, error) {
+	return Default().Subscribe(topic, group, handler)
+}
+
+func init() {
+	RegisterBroker(config.TRANSPORT_MEMORY, NewMemoryBroker())
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+type MetricsSetting struct {
+	Enabled      bool      `json:"enabled"`
+	Path         string    `json:"path"`
+	Buckets      []float64 `json:"buckets"`                          // 请求耗时直方图分桶（秒）
+	ExcludePaths []string  `json:"excludePaths" yaml:"excludePaths"` // 不统计的请求路径，以 /** 结尾时包含子路径
+}
+
+var Setting *MetricsSetting = &MetricsSetting{
+	Enabled:      false,
+	Path:         "/metrics",
+	Buckets:      []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	ExcludePaths: []string{"/metrics", "/health/**"},
+}
+
+func init() {
+	logger.Debug("Register Metrics Config")
+	config.RegisterConfig("metrics", Setting, "Metrics Settings")
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gophab/gophrame/core/metrics/config"
+	"github.com/gophab/gophrame/core/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	httpRequests = NewCounterVec("http_requests_total",
+		"HTTP requests by route template and status", "method", "route", "status")
+	httpDuration = NewHistogramVec("http_request_duration_seconds",
+		"HTTP request latency by route template and status", DefaultBuckets, "method", "route", "status")
+	httpInflight = NewGaugeVec("http_requests_in_flight",
+		"HTTP requests being served")
+)
+
+func init() {
+	MustRegister(httpRequests, httpDuration, httpInflight)
+}
+
+func excluded(path string) bool {
+	return util.MatchAnyPath(config.Setting.ExcludePaths, path)
+}
+
+// 按路由模板统计请求数及耗时，未匹配路由统一记为 unmatched 避免标签膨胀
+func Middleware() gin.HandlerFunc {
+	inflight := httpInflight.WithLabelValues()
+
+	return func(c *gin.Context) {
+		if excluded(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		inflight.Inc()
+		defer inflight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// 输出注册表中的全部指标
+func Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Type", CONTENT_TYPE_TEXT)
+		c.Status(200)
+		_ = DefaultRegistry.WriteText(c.Writer)
+	}
+}
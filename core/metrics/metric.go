@@ -0,0 +1,367 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type MetricType string
+
+const (
+	COUNTER   MetricType = "counter"
+	GAUGE     MetricType = "gauge"
+	HISTOGRAM MetricType = "histogram"
+)
+
+// 默认耗时分桶（秒）
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type LabelPair struct {
+	Name  string
+	Value string
+}
+
+// 一个采样值，Suffix 用于直方图的 _bucket/_sum/_count
+type Sample struct {
+	Suffix string
+	Labels []LabelPair
+	Value  float64
+}
+
+type MetricFamily struct {
+	Name    string
+	Help    string
+	Type    MetricType
+	Samples []*Sample
+}
+
+// 采集器，每次抓取时调用
+type Collector interface {
+	Collect() []*MetricFamily
+}
+
+type collectorFunc struct {
+	f func() []*MetricFamily
+}
+
+func (c *collectorFunc) Collect() []*MetricFamily {
+	return c.f()
+}
+
+// 以函数实现采集器，用于抓取时读取连接池状态等
+func NewCollector(f func() []*MetricFamily) Collector {
+	return &collectorFunc{f: f}
+}
+
+// 抓取时计算值的 Gauge
+func NewGaugeFunc(name, help string, f func() float64) Collector {
+	return NewCollector(func() []*MetricFamily {
+		return []*MetricFamily{{
+			Name:    name,
+			Help:    help,
+			Type:    GAUGE,
+			Samples: []*Sample{{Value: f()}},
+		}}
+	})
+}
+
+// 抓取时计算值的 Counter，f 返回累计值
+func NewCounterFunc(name, help string, f func() float64) Collector {
+	return NewCollector(func() []*MetricFamily {
+		return []*MetricFamily{{
+			Name:    name,
+			Help:    help,
+			Type:    COUNTER,
+			Samples: []*Sample{{Value: f()}},
+		}}
+	})
+}
+
+func labelPairs(names, values []string) []LabelPair {
+	result := make([]LabelPair, len(names))
+	for i, name := range names {
+		result[i] = LabelPair{Name: name, Value: values[i]}
+	}
+	return result
+}
+
+// 按标签值索引子指标，标签值个数须与标签名一致
+type metricVec struct {
+	name       string
+	help       string
+	labelNames []string
+	children   map[string]interface{}
+	values     map[string][]string
+	mutex      sync.RWMutex
+}
+
+func newMetricVec(name, help string, labelNames []string) metricVec {
+	return metricVec{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		children:   make(map[string]interface{}),
+		values:     make(map[string][]string),
+	}
+}
+
+func (v *metricVec) child(values []string, create func() interface{}) interface{} {
+	if len(values) != len(v.labelNames) {
+		panic("metrics: " + v.name + " expects " + strings.Join(v.labelNames, ",") + " labels")
+	}
+
+	key := strings.Join(values, "\xff")
+
+	v.mutex.RLock()
+	result, ok := v.children[key]
+	v.mutex.RUnlock()
+	if ok {
+		return result
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if result, ok := v.children[key]; ok {
+		return result
+	}
+	result = create()
+	v.children[key] = result
+	v.values[key] = append([]string(nil), values...)
+	return result
+}
+
+// 按标签值排序遍历，保证输出稳定
+func (v *metricVec) each(f func(values []string, child interface{})) {
+	v.mutex.RLock()
+	keys := make([]string, 0, len(v.children))
+	for key := range v.children {
+		keys = append(keys, key)
+	}
+	v.mutex.RUnlock()
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		v.mutex.RLock()
+		child, values := v.children[key], v.values[key]
+		v.mutex.RUnlock()
+		f(values, child)
+	}
+}
+
+func (v *metricVec) Reset() {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	v.children = make(map[string]interface{})
+	v.values = make(map[string][]string)
+}
+
+// 并发安全的 float64
+type atomicFloat struct {
+	bits uint64
+}
+
+func (f *atomicFloat) Add(delta float64) {
+	for {
+		old := atomic.LoadUint64(&f.bits)
+		updated := math.Float64bits(math.Float64frombits(old) + delta)
+		if atomic.CompareAndSwapUint64(&f.bits, old, updated) {
+			return
+		}
+	}
+}
+
+func (f *atomicFloat) Set(value float64) {
+	atomic.StoreUint64(&f.bits, math.Float64bits(value))
+}
+
+func (f *atomicFloat) Load() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&f.bits))
+}
+
+// 只增计数
+type Counter struct {
+	value atomicFloat
+}
+
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+// delta 须非负
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+	c.value.Add(delta)
+}
+
+func (c *Counter) Value() float64 {
+	return c.value.Load()
+}
+
+type CounterVec struct {
+	metricVec
+}
+
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	return &CounterVec{newMetricVec(name, help, labelNames)}
+}
+
+func (v *CounterVec) WithLabelValues(values ...string) *Counter {
+	return v.child(values, func() interface{} { return &Counter{} }).(*Counter)
+}
+
+func (v *CounterVec) Collect() []*MetricFamily {
+	result := &MetricFamily{Name: v.name, Help: v.help, Type: COUNTER}
+	v.each(func(values []string, child interface{}) {
+		result.Samples = append(result.Samples, &Sample{
+			Labels: labelPairs(v.labelNames, values),
+			Value:  child.(*Counter).Value(),
+		})
+	})
+	return []*MetricFamily{result}
+}
+
+// 可增可减的瞬时值
+type Gauge struct {
+	value atomicFloat
+}
+
+func (g *Gauge) Set(value float64) {
+	g.value.Set(value)
+}
+
+func (g *Gauge) Add(delta float64) {
+	g.value.Add(delta)
+}
+
+func (g *Gauge) Inc() {
+	g.value.Add(1)
+}
+
+func (g *Gauge) Dec() {
+	g.value.Add(-1)
+}
+
+func (g *Gauge) Value() float64 {
+	return g.value.Load()
+}
+
+type GaugeVec struct {
+	metricVec
+}
+
+func NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	return &GaugeVec{newMetricVec(name, help, labelNames)}
+}
+
+func (v *GaugeVec) WithLabelValues(values ...string) *Gauge {
+	return v.child(values, func() interface{} { return &Gauge{} }).(*Gauge)
+}
+
+func (v *GaugeVec) Collect() []*MetricFamily {
+	result := &MetricFamily{Name: v.name, Help: v.help, Type: GAUGE}
+	v.each(func(values []string, child interface{}) {
+		result.Samples = append(result.Samples, &Sample{
+			Labels: labelPairs(v.labelNames, values),
+			Value:  child.(*Gauge).Value(),
+		})
+	})
+	return []*MetricFamily{result}
+}
+
+// 分桶统计，buckets 为各桶上界（升序）
+type Histogram struct {
+	buckets []float64
+	counts  []uint64
+	count   uint64
+	sum     float64
+	mutex   sync.Mutex
+}
+
+func (h *Histogram) Observe(value float64) {
+	i := sort.SearchFloat64s(h.buckets, value)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += value
+}
+
+// 各桶累计计数、总数、总和
+func (h *Histogram) snapshot() ([]uint64, uint64, float64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	cumulative := make([]uint64, len(h.counts))
+	var total uint64
+	for i, n := range h.counts {
+		total += n
+		cumulative[i] = total
+	}
+	return cumulative, h.count, h.sum
+}
+
+type HistogramVec struct {
+	metricVec
+	buckets []float64
+}
+
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	return &HistogramVec{metricVec: newMetricVec(name, help, labelNames), buckets: sortedBuckets(buckets)}
+}
+
+func sortedBuckets(buckets []float64) []float64 {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	result := append([]float64(nil), buckets...)
+	sort.Float64s(result)
+	return result
+}
+
+// 修改分桶，清除已有统计
+func (v *HistogramVec) SetBuckets(buckets []float64) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	v.buckets = sortedBuckets(buckets)
+	v.children = make(map[string]interface{})
+	v.values = make(map[string][]string)
+}
+
+func (v *HistogramVec) WithLabelValues(values ...string) *Histogram {
+	return v.child(values, func() interface{} {
+		return &Histogram{buckets: v.buckets, counts: make([]uint64, len(v.buckets))}
+	}).(*Histogram)
+}
+
+func (v *HistogramVec) Collect() []*MetricFamily {
+	result := &MetricFamily{Name: v.name, Help: v.help, Type: HISTOGRAM}
+	v.each(func(values []string, child interface{}) {
+		labels := labelPairs(v.labelNames, values)
+		cumulative, count, sum := child.(*Histogram).snapshot()
+
+		for i, bound := range v.buckets {
+			result.Samples = append(result.Samples, &Sample{
+				Suffix: "_bucket",
+				Labels: append(append([]LabelPair(nil), labels...), LabelPair{Name: "le", Value: formatFloat(bound)}),
+				Value:  float64(cumulative[i]),
+			})
+		}
+		result.Samples = append(result.Samples,
+			&Sample{Suffix: "_bucket", Labels: append(append([]LabelPair(nil), labels...), LabelPair{Name: "le", Value: "+Inf"}), Value: float64(count)},
+			&Sample{Suffix: "_sum", Labels: labels, Value: sum},
+			&Sample{Suffix: "_count", Labels: labels, Value: float64(count)},
+		)
+	})
+	return []*MetricFamily{result}
+}
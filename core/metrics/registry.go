@@ -0,0 +1,171 @@
+package metrics
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var ErrDuplicateMetric = errors.New("duplicate metric")
+
+// 采集器注册表，抓取时汇总全部采集器的输出
+type Registry struct {
+	collectors map[Collector][]string
+	names      map[string]bool
+	mutex      sync.RWMutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		collectors: make(map[Collector][]string),
+		names:      make(map[string]bool),
+	}
+}
+
+// 注册采集器，指标名与已注册的重复时返回 ErrDuplicateMetric
+func (r *Registry) Register(c Collector) error {
+	families := c.Collect()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.collectors[c]; ok {
+		return ErrDuplicateMetric
+	}
+
+	names := make([]string, 0, len(families))
+	for _, family := range families {
+		if r.names[family.Name] {
+			return errors.New(ErrDuplicateMetric.Error() + ": " + family.Name)
+		}
+		names = append(names, family.Name)
+	}
+
+	for _, name := range names {
+		r.names[name] = true
+	}
+	r.collectors[c] = names
+	return nil
+}
+
+func (r *Registry) MustRegister(collectors ...Collector) {
+	for _, c := range collectors {
+		if err := r.Register(c); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (r *Registry) Unregister(c Collector) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	names, ok := r.collectors[c]
+	if !ok {
+		return false
+	}
+	for _, name := range names {
+		delete(r.names, name)
+	}
+	delete(r.collectors, c)
+	return true
+}
+
+// 汇总全部指标，按名称排序
+func (r *Registry) Gather() []*MetricFamily {
+	r.mutex.RLock()
+	collectors := make([]Collector, 0, len(r.collectors))
+	for c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.mutex.RUnlock()
+
+	merged := make(map[string]*MetricFamily)
+	for _, c := range collectors {
+		for _, family := range c.Collect() {
+			if existing, ok := merged[family.Name]; ok {
+				existing.Samples = append(existing.Samples, family.Samples...)
+			} else {
+				merged[family.Name] = family
+			}
+		}
+	}
+
+	result := make([]*MetricFamily, 0, len(merged))
+	for _, family := range merged {
+		result = append(result, family)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// Prometheus 文本格式 0.0.4
+const CONTENT_TYPE_TEXT = "text/plain; version=0.0.4; charset=utf-8"
+
+func (r *Registry) WriteText(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	for _, family := range r.Gather() {
+		if family.Help != "" {
+			writer.WriteString("# HELP " + family.Name + " " + escapeHelp(family.Help) + "\n")
+		}
+		writer.WriteString("# TYPE " + family.Name + " " + string(family.Type) + "\n")
+
+		for _, sample := range family.Samples {
+			writer.WriteString(family.Name + sample.Suffix)
+			if len(sample.Labels) > 0 {
+				writer.WriteString("{")
+				for i, label := range sample.Labels {
+					if i > 0 {
+						writer.WriteString(",")
+					}
+					writer.WriteString(label.Name + "=\"" + escapeLabelValue(label.Value) + "\"")
+				}
+				writer.WriteString("}")
+			}
+			writer.WriteString(" " + formatFloat(sample.Value) + "\n")
+		}
+	}
+	return writer.Flush()
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer("\\", `\\`, "\n", `\n`).Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return strings.NewReplacer("\\", `\\`, "\n", `\n`, "\"", `\"`).Replace(s)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// 框架内置指标及应用自定义指标共用的注册表
+var DefaultRegistry = NewRegistry()
+
+func Register(c Collector) error {
+	return DefaultRegistry.Register(c)
+}
+
+func MustRegister(collectors ...Collector) {
+	DefaultRegistry.MustRegister(collectors...)
+}
+
+func Unregister(c Collector) bool {
+	return DefaultRegistry.Unregister(c)
+}
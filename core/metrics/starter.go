@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"github.com/gophab/gophrame/core/engine"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/metrics/config"
+	"github.com/gophab/gophrame/core/router"
+	"github.com/gophab/gophrame/core/starter"
+)
+
+func init() {
+	// 先于其他模块注册路由
+	starter.RegisterInitializorEx(Init, -100)
+	starter.RegisterStarter(Start)
+}
+
+func Init() {
+	logger.Debug("Initializing Metrics: ...", config.Setting.Enabled)
+	if config.Setting.Enabled {
+		httpDuration.SetBuckets(config.Setting.Buckets)
+		engine.Get().Use(Middleware())
+	}
+}
+
+func Start() {
+	if config.Setting.Enabled && config.Setting.Path != "" {
+		router.Root().GET(config.Setting.Path, Handler())
+	}
+}
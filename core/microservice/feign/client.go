@@ -1,58 +1,74 @@
 package feign
 
 import (
+	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/gophab/gophrame/core/feign"
 	"github.com/gophab/gophrame/core/inject"
 	"github.com/gophab/gophrame/core/microservice/registry"
-	"github.com/gophab/gophrame/core/microservice/registry/config"
 
 	"github.com/patrickmn/go-cache"
 )
 
 type RegistryFeignClientInterceptor struct {
-	RegistryClient registry.Client
+	RegistryClient registry.Client `inject:"registryClient"`
 	Cache          *cache.Cache
+	cacheOnce      sync.Once
 }
 
+// 按服务名解析请求地址：每次请求由负载均衡选择实例，并根据结果更新实例健康状态
 func (in *RegistryFeignClientInterceptor) Do(chain *feign.FeignClientInterceptorChain, method string, urlPath string, urlValues url.Values, bodyValue interface{}, options ...*feign.RequestOptions) *feign.FeignClient {
-	if in.RegistryClient != nil {
-		url, err := url.Parse(urlPath)
-		if err == nil && url.Host != "" {
-			if v, b := in.getCache().Get(url.Host); b {
-				urlPrefix := v.(string)
-				if urlPrefix != "-" {
-					// - 标识非服务名，忽略
-					urlPath = urlPrefix + url.Path
-				}
-			} else {
-				// 假设Host为ServiceName
-				urlPrefix, _ := in.RegistryClient.GetServiceEntry(url.Host)
-				if urlPrefix != "" {
-					urlPath = urlPrefix + url.Path
-					in.getCache().SetDefault(url.Host, urlPrefix)
-				} else {
-					in.getCache().SetDefault(url.Host, "-")
-				}
-			}
-		}
+	if in.RegistryClient == nil {
+		return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+	}
+
+	target, err := url.Parse(urlPath)
+	if err != nil || target.Host == "" {
+		return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+	}
+
+	if _, b := in.getCache().Get(target.Host); b {
+		// 已确认非服务名，忽略
+		return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+	}
+
+	// 假设Host为ServiceName
+	selection, err := in.RegistryClient.Select(target.Host)
+	if err != nil {
+		return chain.Exit(err)
+	}
+	if selection == nil {
+		in.getCache().SetDefault(target.Host, "-")
+		return chain.Next(method, urlPath, urlValues, bodyValue, options...)
 	}
-	return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+
+	entry, _ := url.Parse(selection.Instance.Entry())
+	target.Scheme = entry.Scheme
+	target.Host = entry.Host
+
+	result := chain.Next(method, target.String(), urlValues, bodyValue, options...)
+	selection.Done(result.Error == nil && (result.Response == nil || result.Response.StatusCode < http.StatusInternalServerError))
+	return result
 }
 
+// 并发请求共享，未指定时首次使用创建
 func (in *RegistryFeignClientInterceptor) getCache() *cache.Cache {
-	if in.Cache == nil {
-		in.Cache = cache.New(time.Minute*5, time.Minute)
-	}
+	in.cacheOnce.Do(func() {
+		if in.Cache == nil {
+			in.Cache = cache.New(time.Minute*5, time.Minute)
+		}
+	})
 	return in.Cache
 }
 
 func init() {
-	if config.Setting.Enabled {
-		var interceptor = &RegistryFeignClientInterceptor{}
-		inject.InjectValue("registryFeignInterceptor", interceptor)
-		feign.RegisterGlobalFeignClientInterceptor(interceptor)
+	// 注册中心启动后注入 registryClient，未启用时直接放行
+	var interceptor = &RegistryFeignClientInterceptor{
+		Cache: cache.New(time.Minute*5, time.Minute),
 	}
+	inject.InjectValue("registryFeignInterceptor", interceptor)
+	feign.RegisterGlobalFeignClientInterceptor(interceptor)
 }
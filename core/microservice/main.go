@@ -1,12 +1,17 @@
 package microservice
 
 import (
-	"log"
 	"net"
-	"os"
-	"os/signal"
 	"strconv"
-	"syscall"
+
+	_ "github.com/gophab/gophrame/core/microservice/feign"
+	_ "github.com/gophab/gophrame/core/microservice/registry/starter"
+
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/microservice/registry"
+	"github.com/gophab/gophrame/core/microservice/registry/nacos"
+	"github.com/gophab/gophrame/core/starter"
 
 	"github.com/nacos-group/nacos-sdk-go/clients"
 	"github.com/nacos-group/nacos-sdk-go/clients/naming_client"
@@ -14,26 +19,43 @@ import (
 	"github.com/nacos-group/nacos-sdk-go/vo"
 )
 
+// 当前注册中心客户端，注册中心未启用时为 nil
+func RegistryClient() *registry.RegistryClient {
+	client, _ := inject.GetValue("registryClient").(*registry.RegistryClient)
+	return client
+}
+
 var app naming_client.INamingClient
 
+// Nacos 命名客户端：优先使用注册中心（registry.type=nacos）创建的客户端，其次为 Service 创建的客户端
+//
+// Deprecated: 通过 RegistryClient 及 registry.DiscoveryClient 访问注册中心
 func Application() naming_client.INamingClient {
+	if client, ok := inject.GetValue("discoveryClient").(*nacos.NacosDiscoveryClient); ok {
+		return client.NamingClient
+	}
 	return app
 }
 
+// 创建 Nacos 命名客户端并注册当前实例
+//
+// Deprecated: 配置 registry（type=nacos），实例随应用启动自动注册、退出时注销
 func Service(configs []constant.ServerConfig, serverName, group string, serverPort uint64) {
-	client, _ := clients.CreateNamingClient(map[string]interface{}{
-		"serverConfigs": configs,
-		"clientConfig": constant.ClientConfig{
-			NamespaceId:  "phsc",
-			TimeoutMs:    10 * 1000,
-			BeatInterval: 5 * 1000,
-			//CacheDir:            "data/nacos/cache",
+	client, err := clients.NewNamingClient(vo.NacosClientParam{
+		ClientConfig: &constant.ClientConfig{
+			NamespaceId:         "phsc",
+			TimeoutMs:           10 * 1000,
+			BeatInterval:        5 * 1000,
 			NotLoadCacheAtStart: true,
 		},
+		ServerConfigs: configs,
 	})
-	ip := getIpAddr()
+	if err != nil {
+		logger.Fatal("[MICROSERVICE] Create nacos naming client error: ", err.Error())
+	}
+
 	RegisterServiceInstance(client, vo.RegisterInstanceParam{
-		Ip:          ip,
+		Ip:          getIpAddr(),
 		Port:        serverPort,
 		ServiceName: serverName,
 		Weight:      1,
@@ -42,12 +64,11 @@ func Service(configs []constant.ServerConfig, serverName, group string, serverPo
 		Healthy:     true,
 		Ephemeral:   true,
 		Metadata: map[string]string{
-			"preserved.heart.beat.interval": strconv.Itoa(1000 * 10), //25s
+			"preserved.heart.beat.interval": strconv.Itoa(1000 * 10),
 			"preserved.register.source":     "SPRING_CLOUD",
 		},
 	})
 
-	//连接
 	app = client
 }
 
@@ -63,27 +84,26 @@ func getIpAddr() string {
 	return "127.0.0.1"
 }
 
+// 注册实例，应用退出（starter.Terminate）时注销
+//
+// Deprecated: 使用 registry.DiscoveryClient 的 Register / Deregister
 func RegisterServiceInstance(client naming_client.INamingClient, param vo.RegisterInstanceParam) {
-	success, _ := client.RegisterInstance(param)
-	if success {
-		log.Printf("[INFO] 服务名 [%s] 注册成功  address [%s:%d] \n", param.ServiceName, param.Ip, param.Port)
-	} else {
-		log.Fatalf("[ERROR] 服务名 [%s] 注册失败  address [%s:%d] \n", param.ServiceName, param.Ip, param.Port)
+	if success, err := client.RegisterInstance(param); !success {
+		logger.Fatal("[MICROSERVICE] Register service [", param.ServiceName, "] address [", param.Ip, ":", param.Port, "] failed: ", err)
 	}
-	go func() {
-		exitChan := make(chan os.Signal, 100)
-		signal.Notify(exitChan, os.Interrupt, syscall.SIGTERM, os.Kill)
-		<-exitChan
-		log.Printf("[EXIT] 服务关闭 [%s]  address [%s:%d] \n", param.ServiceName, param.Ip, param.Port)
-		_, _ = client.DeregisterInstance(vo.DeregisterInstanceParam{
+	logger.Info("[MICROSERVICE] Registered service [", param.ServiceName, "] address [", param.Ip, ":", param.Port, "]")
+
+	starter.RegisterTerminater(func() {
+		logger.Info("[MICROSERVICE] Deregister service [", param.ServiceName, "] address [", param.Ip, ":", param.Port, "]")
+		if _, err := client.DeregisterInstance(vo.DeregisterInstanceParam{
 			Ip:          param.Ip,
 			Port:        param.Port,
 			Cluster:     param.ClusterName,
 			ServiceName: param.ServiceName,
 			GroupName:   param.GroupName,
-			Ephemeral:   true, //立刻删除服务
-		})
-		os.Exit(1)
-	}()
-
+			Ephemeral:   true,
+		}); err != nil {
+			logger.Warn("[MICROSERVICE] Deregister service [", param.ServiceName, "] error: ", err.Error())
+		}
+	})
 }
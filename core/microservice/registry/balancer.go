@@ -0,0 +1,224 @@
+package registry
+
+import (
+	"math/rand"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/microservice/registry/config"
+)
+
+const (
+	BALANCER_RANDOM          = "random"
+	BALANCER_ROUND_ROBIN     = "round_robin"
+	BALANCER_WEIGHTED        = "weighted"
+	BALANCER_LEAST_IN_FLIGHT = "least_in_flight"
+	BALANCER_ZONE_AWARE      = "zone_aware"
+)
+
+// 负载均衡：从可用实例中选择一个
+type Balancer interface {
+	Choose(instances []*InstanceInfo) *InstanceInfo
+}
+
+type RandomBalancer struct{}
+
+func (*RandomBalancer) Choose(instances []*InstanceInfo) *InstanceInfo {
+	if len(instances) == 0 {
+		return nil
+	}
+	return instances[rand.Intn(len(instances))]
+}
+
+type RoundRobinBalancer struct {
+	next uint64
+}
+
+func (b *RoundRobinBalancer) Choose(instances []*InstanceInfo) *InstanceInfo {
+	if len(instances) == 0 {
+		return nil
+	}
+	n := atomic.AddUint64(&b.next, 1)
+	return instances[int((n-1)%uint64(len(instances)))]
+}
+
+// 按权重随机，权重取实例 Weight，其次 metadata.weight，默认为 1
+type WeightedBalancer struct{}
+
+func (*WeightedBalancer) Choose(instances []*InstanceInfo) *InstanceInfo {
+	if len(instances) == 0 {
+		return nil
+	}
+
+	weights := make([]float64, len(instances))
+	total := 0.0
+	for i, instance := range instances {
+		weights[i] = instance.GetWeight()
+		total += weights[i]
+	}
+	if total <= 0 {
+		return instances[rand.Intn(len(instances))]
+	}
+
+	r := rand.Float64() * total
+	for i, weight := range weights {
+		if r < weight {
+			return instances[i]
+		}
+		r -= weight
+	}
+	return instances[len(instances)-1]
+}
+
+// 选择进行中调用最少的实例，相同时随机
+type LeastInFlightBalancer struct{}
+
+func (*LeastInFlightBalancer) Choose(instances []*InstanceInfo) *InstanceInfo {
+	var (
+		result []*InstanceInfo
+		least  int64 = -1
+	)
+	for _, instance := range instances {
+		inflight := defaultHealthTracker.Inflight(instance)
+		switch {
+		case least < 0 || inflight < least:
+			least = inflight
+			result = []*InstanceInfo{instance}
+		case inflight == least:
+			result = append(result, instance)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result[rand.Intn(len(result))]
+}
+
+// 优先选择同区域实例，同区域无可用实例时使用全部实例
+type ZoneAwareBalancer struct {
+	Zone     string
+	Delegate Balancer
+}
+
+func (b *ZoneAwareBalancer) Choose(instances []*InstanceInfo) *InstanceInfo {
+	if b.Zone != "" {
+		local := make([]*InstanceInfo, 0, len(instances))
+		for _, instance := range instances {
+			if instance.Zone() == b.Zone {
+				local = append(local, instance)
+			}
+		}
+		if len(local) > 0 {
+			instances = local
+		}
+	}
+	return b.Delegate.Choose(instances)
+}
+
+type BalancerFactory func() Balancer
+
+var (
+	balancerFactories = map[string]BalancerFactory{
+		BALANCER_RANDOM:          func() Balancer { return &RandomBalancer{} },
+		BALANCER_ROUND_ROBIN:     func() Balancer { return &RoundRobinBalancer{} },
+		BALANCER_WEIGHTED:        func() Balancer { return &WeightedBalancer{} },
+		BALANCER_LEAST_IN_FLIGHT: func() Balancer { return &LeastInFlightBalancer{} },
+		BALANCER_ZONE_AWARE: func() Balancer {
+			return &ZoneAwareBalancer{Zone: config.Setting.Zone(), Delegate: &RoundRobinBalancer{}}
+		},
+	}
+	balancers     = make(map[string]Balancer)
+	balancerMutex sync.RWMutex
+)
+
+// 注册自定义负载均衡策略
+func RegisterBalancerFactory(name string, factory BalancerFactory) {
+	balancerMutex.Lock()
+	defer balancerMutex.Unlock()
+	balancerFactories[name] = factory
+}
+
+// 为服务指定负载均衡
+func SetBalancer(serviceId string, balancer Balancer) {
+	balancerMutex.Lock()
+	defer balancerMutex.Unlock()
+	balancers[serviceId] = balancer
+}
+
+// 服务的负载均衡：显式指定 > loadBalancer.services > loadBalancer.strategy
+func GetBalancer(serviceId string) Balancer {
+	balancerMutex.RLock()
+	balancer, ok := balancers[serviceId]
+	balancerMutex.RUnlock()
+	if ok {
+		return balancer
+	}
+
+	balancerMutex.Lock()
+	defer balancerMutex.Unlock()
+
+	if balancer, ok := balancers[serviceId]; ok {
+		return balancer
+	}
+
+	strategy := config.Setting.LoadBalancer.Services[serviceId]
+	if strategy == "" {
+		strategy = config.Setting.LoadBalancer.Strategy
+	}
+	factory, ok := balancerFactories[strategy]
+	if !ok {
+		if strategy != "" {
+			logger.Warn("Unknown load balancer strategy: ", strategy)
+		}
+		factory = balancerFactories[BALANCER_ROUND_ROBIN]
+	}
+
+	balancer = factory()
+	balancers[serviceId] = balancer
+	return balancer
+}
+
+// 按服务的负载均衡策略选择一个可用实例
+func PickInstance(instances []InstanceInfo) *InstanceInfo {
+	if len(instances) == 0 {
+		return nil
+	}
+	return GetBalancer(instances[0].ServiceName).Choose(AvailableInstances(instances))
+}
+
+func (i *InstanceInfo) GetWeight() float64 {
+	if i.Weight > 0 {
+		return i.Weight
+	}
+	if weight, err := strconv.ParseFloat(i.Meta["weight"], 64); err == nil && weight >= 0 {
+		return weight
+	}
+	return 1
+}
+
+// 实例所在区域：metadata.zone，其次数据中心名称
+func (i *InstanceInfo) Zone() string {
+	if zone := i.Meta["zone"]; zone != "" {
+		return zone
+	}
+	return i.DataCenterInfo.Name
+}
+
+// 一次调用选中的实例，调用结束后须调用 Done 反馈结果
+type Selection struct {
+	Instance *InstanceInfo
+	once     sync.Once
+}
+
+func NewSelection(instance *InstanceInfo) *Selection {
+	defaultHealthTracker.Begin(instance)
+	return &Selection{Instance: instance}
+}
+
+func (s *Selection) Done(success bool) {
+	s.once.Do(func() {
+		defaultHealthTracker.End(s.Instance, success)
+	})
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	ConsulConfig "github.com/gophab/gophrame/core/microservice/registry/consul/config"
 	DubboConfig "github.com/gophab/gophrame/core/microservice/registry/dubbo/config"
 	EurekaConfig "github.com/gophab/gophrame/core/microservice/registry/eureka/config"
@@ -9,13 +11,34 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	REGISTRY_EUREKA = "eureka"
+	REGISTRY_CONSUL = "consul"
+	REGISTRY_NACOS  = "nacos"
+)
+
+type LoadBalancerSetting struct {
+	Strategy         string            `json:"strategy"`                                 // random / round_robin / weighted / least_in_flight / zone_aware
+	Services         map[string]string `json:"services"`                                 // 按服务指定策略
+	Zone             string            `json:"zone"`                                     // 本实例所在区域，为空时取 metadata.zone
+	FailureThreshold int               `json:"failureThreshold" yaml:"failureThreshold"` // 连续失败次数达到后摘除实例
+	EjectionCooldown time.Duration     `json:"ejectionCooldown" yaml:"ejectionCooldown"` // 摘除时长
+}
+
 type RegistrySetting struct {
 	Enabled            bool
+	Type               string `json:"type"` // eureka / consul / nacos，为空时使用第一个启用的注册中心
 	EnableAutoRegister bool   `json:"enableAutoRegister" yaml:"enabledAutoRegister"`
 	ServiceName        string `json:"serviceName" yaml:"serviceName"`
 	InstanceId         string `json:"instanceId" yaml:"instanceId"`
 	PreferIP           string `json:"perferIp" yaml:"preferIp"`
 	Port               int
+	Secure             bool                `json:"secure"`                                     // 实例使用 https 访问
+	Metadata           map[string]string   `json:"metadata"`                                   // 注册时携带的元数据
+	HealthCheckPath    string              `json:"healthCheckPath" yaml:"healthCheckPath"`     // 健康检查路径，为空时使用心跳（TTL）
+	HeartbeatInterval  time.Duration       `json:"heartbeatInterval" yaml:"heartbeatInterval"` // 心跳间隔
+	RefreshInterval    time.Duration       `json:"refreshInterval" yaml:"refreshInterval"`     // 服务列表刷新间隔
+	LoadBalancer       LoadBalancerSetting `json:"loadBalancer" yaml:"loadBalancer"`
 	Eureka             *EurekaConfig.EurekaSetting
 	Consul             *ConsulConfig.ConsulSetting
 	Nacos              *NacosConfig.NacosSetting
@@ -26,4 +49,39 @@ var Setting = &RegistrySetting{
 	Enabled:            false,
 	EnableAutoRegister: false,
 	InstanceId:         uuid.NewString(),
+	HeartbeatInterval:  time.Second * 30,
+	RefreshInterval:    time.Minute,
+	LoadBalancer: LoadBalancerSetting{
+		Strategy:         "round_robin",
+		FailureThreshold: 3,
+		EjectionCooldown: time.Second * 30,
+	},
+	Eureka: EurekaConfig.Setting,
+	Consul: ConsulConfig.Setting,
+	Nacos:  NacosConfig.Setting,
+	Dubbo:  DubboConfig.Setting,
+}
+
+// 本实例所在区域
+func (s *RegistrySetting) Zone() string {
+	if s.LoadBalancer.Zone != "" {
+		return s.LoadBalancer.Zone
+	}
+	return s.Metadata["zone"]
+}
+
+// 当前使用的注册中心
+func (s *RegistrySetting) Backend() string {
+	if s.Type != "" {
+		return s.Type
+	}
+	switch {
+	case s.Eureka != nil && s.Eureka.Enabled:
+		return REGISTRY_EUREKA
+	case s.Consul != nil && s.Consul.Enabled:
+		return REGISTRY_CONSUL
+	case s.Nacos != nil && s.Nacos.Enabled:
+		return REGISTRY_NACOS
+	}
+	return ""
 }
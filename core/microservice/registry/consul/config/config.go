@@ -1,9 +1,28 @@
 package config
 
+import "time"
+
 type ConsulSetting struct {
-	Enabled bool
+	Enabled                        bool
+	Address                        string        `json:"address"` // host:port
+	Scheme                         string        `json:"scheme"`
+	Datacenter                     string        `json:"datacenter"`
+	Token                          string        `json:"token"`
+	Tags                           []string      `json:"tags"`
+	CheckInterval                  time.Duration `json:"checkInterval" yaml:"checkInterval"` // HTTP 检查间隔
+	CheckTimeout                   time.Duration `json:"checkTimeout" yaml:"checkTimeout"`
+	TTL                            time.Duration `json:"ttl"`                                                                  // 心跳检查超时，需大于心跳间隔
+	DeregisterCriticalServiceAfter time.Duration `json:"deregisterCriticalServiceAfter" yaml:"deregisterCriticalServiceAfter"` // 持续不健康后自动注销
+	WatchWaitTime                  time.Duration `json:"watchWaitTime" yaml:"watchWaitTime"`                                   // 阻塞查询最长等待
 }
 
 var Setting *ConsulSetting = &ConsulSetting{
-	Enabled: false,
+	Enabled:                        false,
+	Address:                        "127.0.0.1:8500",
+	Scheme:                         "http",
+	CheckInterval:                  time.Second * 10,
+	CheckTimeout:                   time.Second * 5,
+	TTL:                            time.Second * 90,
+	DeregisterCriticalServiceAfter: time.Minute * 5,
+	WatchWaitTime:                  time.Minute * 5,
 }
@@ -1,42 +1,282 @@
 package consul
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"math/rand"
-	"net/url"
 	"strconv"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/microservice/registry"
+	"github.com/gophab/gophrame/core/microservice/registry/consul/config"
 
 	"github.com/hashicorp/consul/api"
 )
 
-type ConsulRegistryClient struct {
+const (
+	META_INSTANCE_ID = "instanceId"
+	META_SECURE      = "secure"
+)
+
+func CreateConsulDiscoveryClient() (*ConsulDiscoveryClient, error) {
+	client, err := api.NewClient(&api.Config{
+		Address:    config.Setting.Address,
+		Scheme:     config.Setting.Scheme,
+		Datacenter: config.Setting.Datacenter,
+		Token:      config.Setting.Token,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ConsulDiscoveryClient{ConsulClient: client}, nil
+}
+
+type ConsulDiscoveryClient struct {
+	registry.AbstractDiscoveryClient
 	ConsulClient *api.Client
 }
 
-func (m *ConsulRegistryClient) GetServiceEntry(service string) (string, error) {
-	serviceEntries, _, err := m.ConsulClient.Health().Service(service, "", true, nil)
+func checkId(instance *registry.InstanceInfo) string {
+	return "service:" + instance.InstanceId
+}
+
+func (c *ConsulDiscoveryClient) Register(instance *registry.InstanceInfo) (bool, error) {
+	meta := make(map[string]string)
+	for k, v := range instance.Meta {
+		meta[k] = v
+	}
+	meta[META_INSTANCE_ID] = instance.InstanceId
+	if instance.Secure() {
+		meta[META_SECURE] = "true"
+	}
+
+	port := instance.Port.Port
+	if instance.Secure() {
+		port = instance.SecurePort.Port
+	}
+
+	// 配置了健康检查地址时由 consul 主动检查，否则使用 TTL 由心跳维持
+	check := &api.AgentServiceCheck{
+		CheckID:                        checkId(instance),
+		DeregisterCriticalServiceAfter: config.Setting.DeregisterCriticalServiceAfter.String(),
+	}
+	if instance.HealthCheckUrl != "" {
+		check.HTTP = instance.HealthCheckUrl
+		check.Interval = config.Setting.CheckInterval.String()
+		check.Timeout = config.Setting.CheckTimeout.String()
+	} else {
+		check.TTL = config.Setting.TTL.String()
+		check.Status = api.HealthPassing
+		if instance.Status != "" && instance.Status != registry.STATUS_UP {
+			check.Status = api.HealthCritical
+		}
+	}
+
+	err := c.ConsulClient.Agent().ServiceRegister(&api.AgentServiceRegistration{
+		ID:      instance.InstanceId,
+		Name:    instance.ServiceName,
+		Address: instance.Host(),
+		Port:    port,
+		Tags:    config.Setting.Tags,
+		Meta:    meta,
+		Check:   check,
+	})
+	return err == nil, err
+}
+
+func (c *ConsulDiscoveryClient) Deregister(instance *registry.InstanceInfo) error {
+	return c.ConsulClient.Agent().ServiceDeregister(instance.InstanceId)
+}
+
+func (c *ConsulDiscoveryClient) SendHeartBeat(instance *registry.InstanceInfo, status string) (bool, error) {
+	services, err := c.ConsulClient.Agent().Services()
 	if err != nil {
-		return "", err
+		return false, err
+	}
+	if _, ok := services[instance.InstanceId]; !ok {
+		// agent 已丢失实例，需重新注册
+		return false, nil
+	}
+
+	if instance.HealthCheckUrl != "" {
+		return true, nil
+	}
+
+	health := api.HealthPassing
+	if status != registry.STATUS_UP {
+		health = api.HealthCritical
 	}
+	if err := c.ConsulClient.Agent().UpdateTTL(checkId(instance), status, health); err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
-	if len(serviceEntries) == 0 {
-		return "", errors.New("no service to connect")
+// 主动下线时进入维护模式，consul 立即将实例从健康列表移除
+func (c *ConsulDiscoveryClient) UpdateStatus(instance *registry.InstanceInfo, status string) error {
+	if status == registry.STATUS_OUT_OF_SERVICE {
+		return c.ConsulClient.Agent().EnableServiceMaintenance(instance.InstanceId, "out of service")
 	}
 
-	serviceEntry := serviceEntries[rand.Intn(len(serviceEntries))]
+	if err := c.ConsulClient.Agent().DisableServiceMaintenance(instance.InstanceId); err != nil {
+		return err
+	}
+
+	if instance.HealthCheckUrl != "" {
+		return nil
+	}
 
-	scheme := "http"
-	if meta, ok := serviceEntry.Service.Meta["secure"]; ok {
-		if secure, err := strconv.ParseBool(meta); err == nil && secure {
-			scheme = "https"
+	health := api.HealthPassing
+	if status != registry.STATUS_UP {
+		health = api.HealthCritical
+	}
+	return c.ConsulClient.Agent().UpdateTTL(checkId(instance), status, health)
+}
+
+func (c *ConsulDiscoveryClient) GetServices() ([]registry.ServiceInfo, error) {
+	services, _, err := c.ConsulClient.Catalog().Services(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]registry.ServiceInfo, 0, len(services))
+	for name := range services {
+		if name == "consul" {
+			continue
 		}
+		result = append(result, registry.ServiceInfo{Name: name})
+	}
+	return result, nil
+}
+
+func (c *ConsulDiscoveryClient) GetService(serviceId string) (*registry.ServiceInfo, error) {
+	instances, err := c.GetInstances(serviceId)
+	if err != nil || len(instances) == 0 {
+		return nil, err
+	}
+	return &registry.ServiceInfo{Name: serviceId, Instances: instances}, nil
+}
+
+func (c *ConsulDiscoveryClient) GetInstances(serviceId string) ([]registry.InstanceInfo, error) {
+	entries, _, err := c.ConsulClient.Health().Service(serviceId, "", false, nil)
+	if err != nil {
+		return nil, err
 	}
+	return mapInstanceInfos(entries), nil
+}
 
-	url := &url.URL{
-		Scheme: scheme,
-		Host:   fmt.Sprintf("%s:%d", serviceEntry.Service.Address, serviceEntry.Service.Port),
+func (c *ConsulDiscoveryClient) GetInstance(serviceId string) *registry.InstanceInfo {
+	if instances, err := c.GetInstances(serviceId); err == nil {
+		return registry.PickInstance(instances)
+	}
+	return nil
+}
+
+func (c *ConsulDiscoveryClient) GetInstanceById(serviceId string, instanceId string) *registry.InstanceInfo {
+	if instances, err := c.GetInstances(serviceId); err == nil {
+		for i := range instances {
+			if instances[i].InstanceId == instanceId {
+				return &instances[i]
+			}
+		}
 	}
+	return nil
+}
+
+// 使用阻塞查询监听实例变化
+func (c *ConsulDiscoveryClient) Watch(serviceId string, listener func(instances []registry.InstanceInfo)) (func(), error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		var index uint64
+		for {
+			options := (&api.QueryOptions{
+				WaitIndex: index,
+				WaitTime:  config.Setting.WatchWaitTime,
+			}).WithContext(ctx)
+
+			entries, meta, err := c.ConsulClient.Health().Service(serviceId, "", false, options)
+			if err != nil {
+				if errors.Is(ctx.Err(), context.Canceled) {
+					return
+				}
+				logger.Warn("[CONSUL] Watch service [", serviceId, "] error: ", err.Error())
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Second * 5):
+				}
+				continue
+			}
 
-	return url.String(), nil
+			if meta.LastIndex < index {
+				// 索引回退时重新开始
+				index = 0
+				continue
+			}
+			if meta.LastIndex != index {
+				index = meta.LastIndex
+				listener(mapInstanceInfos(entries))
+			}
+		}
+	}()
+
+	return cancel, nil
+}
+
+func mapStatus(status string) string {
+	switch status {
+	case api.HealthPassing, api.HealthWarning:
+		return registry.STATUS_UP
+	case api.HealthCritical:
+		return registry.STATUS_DOWN
+	case api.HealthMaint:
+		return registry.STATUS_OUT_OF_SERVICE
+	}
+	return registry.STATUS_UNKOWN
+}
+
+func mapInstanceInfo(entry *api.ServiceEntry) *registry.InstanceInfo {
+	address := entry.Service.Address
+	if address == "" {
+		address = entry.Node.Address
+	}
+
+	instanceId := entry.Service.ID
+	if id, ok := entry.Service.Meta[META_INSTANCE_ID]; ok && id != "" {
+		instanceId = id
+	}
+
+	result := &registry.InstanceInfo{
+		ServiceName: entry.Service.Service,
+		InstanceId:  instanceId,
+		HostName:    entry.Node.Node,
+		IpAddr:      address,
+		Port: registry.PortInfo{
+			Port:    entry.Service.Port,
+			Enabled: true,
+		},
+		Status:     mapStatus(entry.Checks.AggregatedStatus()),
+		Meta:       entry.Service.Meta,
+		Weight:     float64(entry.Service.Weights.Passing),
+		VipAddress: entry.Service.Service,
+		DataCenterInfo: registry.DataCenterInfo{
+			Name: entry.Node.Datacenter,
+		},
+	}
+	if secure, err := strconv.ParseBool(entry.Service.Meta[META_SECURE]); err == nil && secure {
+		result.SecurePort = registry.PortInfo{
+			Port:    entry.Service.Port,
+			Enabled: true,
+		}
+	}
+	return result
+}
+
+func mapInstanceInfos(entries []*api.ServiceEntry) []registry.InstanceInfo {
+	result := make([]registry.InstanceInfo, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, *mapInstanceInfo(entry))
+	}
+	return result
 }
@@ -1 +1,13 @@
 package starter
+
+import (
+	"github.com/gophab/gophrame/core/microservice/registry"
+	"github.com/gophab/gophrame/core/microservice/registry/config"
+	"github.com/gophab/gophrame/core/microservice/registry/consul"
+)
+
+func init() {
+	registry.RegisterDiscoveryClient(config.REGISTRY_CONSUL, func() (registry.DiscoveryClient, error) {
+		return consul.CreateConsulDiscoveryClient()
+	})
+}
@@ -102,7 +102,7 @@ func (hc *EurekaClient) StatusUpdate(appName, instanceId, status, lastDirtyTimes
 			"instanceId":         instanceId,
 			"status":             status,
 			"lastDirtyTimestamp": lastDirtyTimestamp,
-		}); result.Error != nil {
+		}).Do(); result.Error != nil {
 		return false, result.Error
 	} else {
 		return result.StatusCode == http.StatusOK, nil
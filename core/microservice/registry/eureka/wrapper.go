@@ -2,7 +2,6 @@ package eureka
 
 import (
 	"fmt"
-	"math/rand"
 	"net/http"
 	"sync"
 	"time"
@@ -12,7 +11,10 @@ import (
 )
 
 func CreateEurekaDiscoveryClient() (*EurekaDiscoveryClient, error) {
-	return nil, nil
+	if len(config.Setting.ServiceUrls) == 0 {
+		return nil, errorParams
+	}
+	return &EurekaDiscoveryClient{}, nil
 }
 
 type EurekaDiscoveryClient struct {
@@ -70,11 +72,10 @@ func (c *EurekaDiscoveryClient) GetInstances(serviceId string) ([]registry.Insta
 }
 
 func (c *EurekaDiscoveryClient) GetInstance(serviceId string) *registry.InstanceInfo {
-	if appInfo, err := c.Client().GetApplication(serviceId); err == nil && appInfo != nil {
-		return mapInstanceInfo(&appInfo.Application.Instance[rand.Intn(len(appInfo.Application.Instance))])
-	} else {
-		return nil
+	if instances, err := c.GetInstances(serviceId); err == nil {
+		return registry.PickInstance(instances)
 	}
+	return nil
 }
 
 func (c *EurekaDiscoveryClient) GetInstanceById(serviceId string, instanceId string) *registry.InstanceInfo {
@@ -98,6 +99,16 @@ func (c *EurekaDiscoveryClient) SendHeartBeat(instance *registry.InstanceInfo, s
 	}
 }
 
+// 主动更新实例状态，OUT_OF_SERVICE 时 Eureka 立即停止向该实例分发流量
+func (c *EurekaDiscoveryClient) UpdateStatus(instance *registry.InstanceInfo, status string) error {
+	if success, err := c.Client().StatusUpdate(instance.ServiceName, instance.InstanceId, status, instance.LastDirtyTimestamp); err != nil {
+		return err
+	} else if !success {
+		return fmt.Errorf("eureka update status %s failed", status)
+	}
+	return nil
+}
+
 func wrapperInstanceInfo(instance *registry.InstanceInfo) *WrapperInstanceInfo {
 	renewalIntervalInSecs := defaultLeaseRenewalInterval
 	durationInSecs := defaultLeaseDuration
@@ -115,12 +126,17 @@ func wrapperInstanceInfo(instance *registry.InstanceInfo) *WrapperInstanceInfo {
 
 	currentTimeStr := fmt.Sprintf("%d", time.Now().UnixNano()/1000000)
 
+	status := statusUp
+	if instance.Status != "" && instance.Status != "DIRTY" {
+		status = instance.Status
+	}
+
 	return &WrapperInstanceInfo{
 		InstanceId: instance.InstanceId,
 		HostName:   hostName,
 		App:        instance.ServiceName,
 		IpAddr:     instance.IpAddr,
-		Status:     statusUp,
+		Status:     status,
 		Port: &WrapperPort{
 			Port:    instance.Port.Port,
 			Enabled: true,
@@ -0,0 +1,122 @@
+package registry
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/microservice/registry/config"
+)
+
+type instanceHealth struct {
+	inflight     int64
+	failures     int
+	ejectedUntil time.Time
+}
+
+// 被动健康检查：根据调用结果统计连续失败，超过阈值的实例在冷却期内不参与负载均衡
+type HealthTracker struct {
+	instances map[string]*instanceHealth
+	mutex     sync.Mutex
+}
+
+func NewHealthTracker() *HealthTracker {
+	return &HealthTracker{
+		instances: make(map[string]*instanceHealth),
+	}
+}
+
+func instanceKey(instance *InstanceInfo) string {
+	return instance.ServiceName + "/" + instance.Entry()
+}
+
+func (t *HealthTracker) get(key string) *instanceHealth {
+	health := t.instances[key]
+	if health == nil {
+		health = &instanceHealth{}
+		t.instances[key] = health
+	}
+	return health
+}
+
+// 调用开始
+func (t *HealthTracker) Begin(instance *InstanceInfo) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.get(instanceKey(instance)).inflight++
+}
+
+// 调用结束：连接错误或 5xx 视为失败
+func (t *HealthTracker) End(instance *InstanceInfo, success bool) {
+	key := instanceKey(instance)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	health := t.get(key)
+	if health.inflight > 0 {
+		health.inflight--
+	}
+
+	if success {
+		health.failures = 0
+	} else {
+		health.failures++
+		if threshold := config.Setting.LoadBalancer.FailureThreshold; threshold > 0 && health.failures >= threshold {
+			health.failures = 0
+			health.ejectedUntil = time.Now().Add(config.Setting.LoadBalancer.EjectionCooldown)
+			logger.Warn("Eject instance ", key, " until ", health.ejectedUntil.Format(time.RFC3339))
+		}
+	}
+
+	if health.inflight == 0 && health.failures == 0 && time.Now().After(health.ejectedUntil) {
+		delete(t.instances, key)
+	}
+}
+
+func (t *HealthTracker) IsEjected(instance *InstanceInfo) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if health := t.instances[instanceKey(instance)]; health != nil {
+		return time.Now().Before(health.ejectedUntil)
+	}
+	return false
+}
+
+// 进行中的调用数
+func (t *HealthTracker) Inflight(instance *InstanceInfo) int64 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if health := t.instances[instanceKey(instance)]; health != nil {
+		return health.inflight
+	}
+	return 0
+}
+
+var defaultHealthTracker = NewHealthTracker()
+
+func DefaultHealthTracker() *HealthTracker {
+	return defaultHealthTracker
+}
+
+// 可用实例：状态为 UP 且未被摘除；全部被摘除时退回全部 UP 实例
+func AvailableInstances(instances []InstanceInfo) []*InstanceInfo {
+	up := make([]*InstanceInfo, 0, len(instances))
+	available := make([]*InstanceInfo, 0, len(instances))
+	for i := range instances {
+		if instances[i].Status != STATUS_UP {
+			continue
+		}
+		up = append(up, &instances[i])
+		if !defaultHealthTracker.IsEjected(&instances[i]) {
+			available = append(available, &instances[i])
+		}
+	}
+	if len(available) == 0 {
+		return up
+	}
+	return available
+}
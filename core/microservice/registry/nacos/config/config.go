@@ -1,9 +1,28 @@
 package config
 
 type NacosSetting struct {
-	Enabled bool
+	Enabled      bool
+	ServerAddrs  []string `json:"serverAddrs" yaml:"serverAddrs"` // host:port
+	ContextPath  string   `json:"contextPath" yaml:"contextPath"`
+	NamespaceId  string   `json:"namespaceId" yaml:"namespaceId"`
+	Group        string   `json:"group"`
+	Cluster      string   `json:"cluster"`
+	Username     string   `json:"username"`
+	Password     string   `json:"password"`
+	TimeoutMs    uint64   `json:"timeoutMs" yaml:"timeoutMs"`
+	BeatInterval int64    `json:"beatInterval" yaml:"beatInterval"` // 临时实例心跳间隔（毫秒），由 SDK 发送
+	CacheDir     string   `json:"cacheDir" yaml:"cacheDir"`
+	LogDir       string   `json:"logDir" yaml:"logDir"`
+	LogLevel     string   `json:"logLevel" yaml:"logLevel"`
 }
 
 var Setting *NacosSetting = &NacosSetting{
-	Enabled: false,
+	Enabled:      false,
+	ServerAddrs:  []string{"127.0.0.1:8848"},
+	ContextPath:  "/nacos",
+	Group:        "DEFAULT_GROUP",
+	Cluster:      "DEFAULT",
+	TimeoutMs:    10 * 1000,
+	BeatInterval: 5 * 1000,
+	LogLevel:     "warn",
 }
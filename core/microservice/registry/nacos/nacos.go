@@ -0,0 +1,275 @@
+package nacos
+
+import (
+	"errors"
+	"net"
+	"strconv"
+
+	"github.com/gophab/gophrame/core/microservice/registry"
+	"github.com/gophab/gophrame/core/microservice/registry/nacos/config"
+
+	"github.com/nacos-group/nacos-sdk-go/clients"
+	"github.com/nacos-group/nacos-sdk-go/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/model"
+	"github.com/nacos-group/nacos-sdk-go/vo"
+)
+
+const (
+	META_INSTANCE_ID = "instanceId"
+	META_SECURE      = "secure"
+)
+
+func CreateNacosDiscoveryClient() (*NacosDiscoveryClient, error) {
+	serverConfigs := make([]constant.ServerConfig, 0, len(config.Setting.ServerAddrs))
+	for _, addr := range config.Setting.ServerAddrs {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		p, err := strconv.ParseUint(port, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		serverConfigs = append(serverConfigs, *constant.NewServerConfig(host, p, constant.WithContextPath(config.Setting.ContextPath)))
+	}
+
+	client, err := clients.NewNamingClient(vo.NacosClientParam{
+		ClientConfig: constant.NewClientConfig(
+			constant.WithNamespaceId(config.Setting.NamespaceId),
+			constant.WithTimeoutMs(config.Setting.TimeoutMs),
+			constant.WithBeatInterval(config.Setting.BeatInterval),
+			constant.WithUsername(config.Setting.Username),
+			constant.WithPassword(config.Setting.Password),
+			constant.WithCacheDir(config.Setting.CacheDir),
+			constant.WithLogDir(config.Setting.LogDir),
+			constant.WithLogLevel(config.Setting.LogLevel),
+			constant.WithNotLoadCacheAtStart(true),
+		),
+		ServerConfigs: serverConfigs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &NacosDiscoveryClient{NamingClient: client}, nil
+}
+
+type NacosDiscoveryClient struct {
+	registry.AbstractDiscoveryClient
+	NamingClient naming_client.INamingClient
+}
+
+func clusters() []string {
+	if config.Setting.Cluster != "" {
+		return []string{config.Setting.Cluster}
+	}
+	return nil
+}
+
+func instancePort(instance *registry.InstanceInfo) uint64 {
+	if instance.Secure() {
+		return uint64(instance.SecurePort.Port)
+	}
+	return uint64(instance.Port.Port)
+}
+
+// 注册为临时实例，心跳由 SDK 按 beatInterval 发送
+func instanceMeta(instance *registry.InstanceInfo) map[string]string {
+	meta := make(map[string]string)
+	for k, v := range instance.Meta {
+		meta[k] = v
+	}
+	meta[META_INSTANCE_ID] = instance.InstanceId
+	if instance.Secure() {
+		meta[META_SECURE] = "true"
+	}
+	return meta
+}
+
+func (c *NacosDiscoveryClient) Register(instance *registry.InstanceInfo) (bool, error) {
+	return c.NamingClient.RegisterInstance(vo.RegisterInstanceParam{
+		Ip:          instance.Host(),
+		Port:        instancePort(instance),
+		ServiceName: instance.ServiceName,
+		GroupName:   config.Setting.Group,
+		ClusterName: config.Setting.Cluster,
+		Weight:      instance.GetWeight(),
+		Enable:      instance.Status == "" || instance.Status == registry.STATUS_UP,
+		Healthy:     true,
+		Ephemeral:   true,
+		Metadata:    instanceMeta(instance),
+	})
+}
+
+// 非 UP 状态时禁用实例，消费方不再选中该实例
+func (c *NacosDiscoveryClient) UpdateStatus(instance *registry.InstanceInfo, status string) error {
+	if success, err := c.NamingClient.UpdateInstance(vo.UpdateInstanceParam{
+		Ip:          instance.Host(),
+		Port:        instancePort(instance),
+		ServiceName: instance.ServiceName,
+		GroupName:   config.Setting.Group,
+		ClusterName: config.Setting.Cluster,
+		Weight:      instance.GetWeight(),
+		Enable:      status == registry.STATUS_UP,
+		Ephemeral:   true,
+		Metadata:    instanceMeta(instance),
+	}); err != nil {
+		return err
+	} else if !success {
+		return errors.New("nacos update instance failed")
+	}
+	return nil
+}
+
+func (c *NacosDiscoveryClient) Deregister(instance *registry.InstanceInfo) error {
+	_, err := c.NamingClient.DeregisterInstance(vo.DeregisterInstanceParam{
+		Ip:          instance.Host(),
+		Port:        instancePort(instance),
+		ServiceName: instance.ServiceName,
+		GroupName:   config.Setting.Group,
+		Cluster:     config.Setting.Cluster,
+		Ephemeral:   true,
+	})
+	return err
+}
+
+// SDK 已维持心跳，这里仅检查实例是否仍在注册中心
+func (c *NacosDiscoveryClient) SendHeartBeat(instance *registry.InstanceInfo, status string) (bool, error) {
+	instances, err := c.GetInstances(instance.ServiceName)
+	if err != nil {
+		return false, err
+	}
+	for _, ii := range instances {
+		if ii.InstanceId == instance.InstanceId {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *NacosDiscoveryClient) GetServices() ([]registry.ServiceInfo, error) {
+	result := make([]registry.ServiceInfo, 0)
+	for page := uint32(1); ; page++ {
+		services, err := c.NamingClient.GetAllServicesInfo(vo.GetAllServiceInfoParam{
+			NameSpace: config.Setting.NamespaceId,
+			GroupName: config.Setting.Group,
+			PageNo:    page,
+			PageSize:  100,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range services.Doms {
+			result = append(result, registry.ServiceInfo{Name: name})
+		}
+		if len(services.Doms) < 100 || int64(len(result)) >= services.Count {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (c *NacosDiscoveryClient) GetService(serviceId string) (*registry.ServiceInfo, error) {
+	instances, err := c.GetInstances(serviceId)
+	if err != nil || len(instances) == 0 {
+		return nil, err
+	}
+	return &registry.ServiceInfo{Name: serviceId, Instances: instances}, nil
+}
+
+func (c *NacosDiscoveryClient) GetInstances(serviceId string) ([]registry.InstanceInfo, error) {
+	instances, err := c.NamingClient.SelectAllInstances(vo.SelectAllInstancesParam{
+		ServiceName: serviceId,
+		GroupName:   config.Setting.Group,
+		Clusters:    clusters(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]registry.InstanceInfo, 0, len(instances))
+	for _, instance := range instances {
+		result = append(result, *mapInstanceInfo(serviceId, instance.InstanceId, instance.Ip, instance.Port, instance.Weight, instance.Enable, instance.Healthy, instance.Metadata))
+	}
+	return result, nil
+}
+
+func (c *NacosDiscoveryClient) GetInstance(serviceId string) *registry.InstanceInfo {
+	if instances, err := c.GetInstances(serviceId); err == nil {
+		return registry.PickInstance(instances)
+	}
+	return nil
+}
+
+func (c *NacosDiscoveryClient) GetInstanceById(serviceId string, instanceId string) *registry.InstanceInfo {
+	if instances, err := c.GetInstances(serviceId); err == nil {
+		for i := range instances {
+			if instances[i].InstanceId == instanceId {
+				return &instances[i]
+			}
+		}
+	}
+	return nil
+}
+
+func (c *NacosDiscoveryClient) Watch(serviceId string, listener func(instances []registry.InstanceInfo)) (func(), error) {
+	param := &vo.SubscribeParam{
+		ServiceName: serviceId,
+		GroupName:   config.Setting.Group,
+		Clusters:    clusters(),
+		SubscribeCallback: func(services []model.SubscribeService, err error) {
+			if err != nil {
+				return
+			}
+			result := make([]registry.InstanceInfo, 0, len(services))
+			for _, service := range services {
+				result = append(result, *mapInstanceInfo(serviceId, service.InstanceId, service.Ip, service.Port, service.Weight, service.Enable, service.Healthy, service.Metadata))
+			}
+			listener(result)
+		},
+	}
+
+	if err := c.NamingClient.Subscribe(param); err != nil {
+		return nil, err
+	}
+	return func() {
+		_ = c.NamingClient.Unsubscribe(param)
+	}, nil
+}
+
+func mapStatus(enable, healthy bool) string {
+	switch {
+	case !enable:
+		return registry.STATUS_OUT_OF_SERVICE
+	case !healthy:
+		return registry.STATUS_DOWN
+	}
+	return registry.STATUS_UP
+}
+
+func mapInstanceInfo(serviceId, instanceId, ip string, port uint64, weight float64, enable, healthy bool, meta map[string]string) *registry.InstanceInfo {
+	if id, ok := meta[META_INSTANCE_ID]; ok && id != "" {
+		instanceId = id
+	}
+
+	result := &registry.InstanceInfo{
+		ServiceName: serviceId,
+		InstanceId:  instanceId,
+		IpAddr:      ip,
+		Port: registry.PortInfo{
+			Port:    int(port),
+			Enabled: true,
+		},
+		Status:     mapStatus(enable, healthy),
+		Meta:       meta,
+		Weight:     weight,
+		VipAddress: serviceId,
+	}
+	if secure, err := strconv.ParseBool(meta[META_SECURE]); err == nil && secure {
+		result.SecurePort = registry.PortInfo{
+			Port:    int(port),
+			Enabled: true,
+		}
+	}
+	return result
+}
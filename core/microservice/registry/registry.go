@@ -1,13 +1,16 @@
 package registry
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"math/rand"
 	"net"
+	"os"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/gophab/gophrame/core/health"
 	"github.com/gophab/gophrame/core/logger"
 	"github.com/gophab/gophrame/core/microservice/registry/config"
 
@@ -52,33 +55,61 @@ type Metadata struct {
 }
 
 type InstanceInfo struct {
-	ServiceName                   string         `json:"app,omitempty"`
-	InstanceId                    string         `json:"instanceId,omitempty"`
-	HostName                      string         `json:"hostName,omitempty"`
-	IpAddr                        string         `json:"ipAddr,omitempty"`
-	Port                          PortInfo       `json:"port,omitempty"`
-	SecurePort                    PortInfo       `json:"securePort,omitempty"`
-	Status                        string         `json:"status,omitempty"`
-	OverriddenStatus              string         `json:"overriddenStatus,omitempty"`
-	CountryId                     int            `json:"countryId,omitempty"`
-	DataCenterInfo                DataCenterInfo `json:"dataCenterInfo,omitempty"`
-	LeaseInfo                     LeaseInfo      `json:"leaseInfo,omitempty"`
-	Metadata                      Metadata       `json:"metadata,omitempty"`
-	HomePageUrl                   string         `json:"homePageUrl,omitempty"`
-	StatusPageUrl                 string         `json:"statusPageUrl,omitempty"`
-	HealthCheckUrl                string         `json:"healthCheckUrl,omitempty"`
-	VipAddress                    string         `json:"vipAddress,omitempty"`
-	SecureVipAddress              string         `json:"secureVipAddress,omitempty"`
-	IsCoordinatingDiscoveryServer string         `json:"isCoordinatingDiscoveryServer,omitempty"`
-	LastUpdatedTimestamp          string         `json:"lastUpdatedTimestamp,omitempty"`
-	LastDirtyTimestamp            string         `json:"lastDirtyTimestamp,omitempty"`
-	ActionType                    string         `json:"actionType,omitempty"`
+	ServiceName                   string            `json:"app,omitempty"`
+	InstanceId                    string            `json:"instanceId,omitempty"`
+	HostName                      string            `json:"hostName,omitempty"`
+	IpAddr                        string            `json:"ipAddr,omitempty"`
+	Port                          PortInfo          `json:"port,omitempty"`
+	SecurePort                    PortInfo          `json:"securePort,omitempty"`
+	Status                        string            `json:"status,omitempty"`
+	OverriddenStatus              string            `json:"overriddenStatus,omitempty"`
+	CountryId                     int               `json:"countryId,omitempty"`
+	DataCenterInfo                DataCenterInfo    `json:"dataCenterInfo,omitempty"`
+	LeaseInfo                     LeaseInfo         `json:"leaseInfo,omitempty"`
+	Metadata                      Metadata          `json:"metadata,omitempty"`
+	Meta                          map[string]string `json:"meta,omitempty"`   // 注册中心通用元数据
+	Weight                        float64           `json:"weight,omitempty"` // 负载均衡权重
+	HomePageUrl                   string            `json:"homePageUrl,omitempty"`
+	StatusPageUrl                 string            `json:"statusPageUrl,omitempty"`
+	HealthCheckUrl                string            `json:"healthCheckUrl,omitempty"`
+	VipAddress                    string            `json:"vipAddress,omitempty"`
+	SecureVipAddress              string            `json:"secureVipAddress,omitempty"`
+	IsCoordinatingDiscoveryServer string            `json:"isCoordinatingDiscoveryServer,omitempty"`
+	LastUpdatedTimestamp          string            `json:"lastUpdatedTimestamp,omitempty"`
+	LastDirtyTimestamp            string            `json:"lastDirtyTimestamp,omitempty"`
+	ActionType                    string            `json:"actionType,omitempty"`
+}
+
+// 实例访问地址，优先使用 IP
+func (i *InstanceInfo) Host() string {
+	if i.IpAddr != "" {
+		return i.IpAddr
+	}
+	return i.HostName
+}
+
+func (i *InstanceInfo) Secure() bool {
+	enabled, _ := i.SecurePort.Enabled.(bool)
+	return enabled && i.SecurePort.Port > 0
+}
+
+// 实例访问入口：scheme://host:port
+func (i *InstanceInfo) Entry() string {
+	if i.Secure() {
+		return fmt.Sprintf("https://%s:%d", i.Host(), i.SecurePort.Port)
+	}
+	return fmt.Sprintf("http://%s:%d", i.Host(), i.Port.Port)
 }
 
+var (
+	ErrWatchNotSupported = errors.New("watch not supported")
+	ErrNoInstance        = errors.New("no instance")
+)
+
 // 服务发现
 type DiscoveryClient interface {
-	Register() (bool, error)
-	Deregister() error
+	Register(instance *InstanceInfo) (bool, error)
+	Deregister(instance *InstanceInfo) error
 
 	GetServices() ([]ServiceInfo, error)
 	GetService(serviceId string) (*ServiceInfo, error)
@@ -87,7 +118,16 @@ type DiscoveryClient interface {
 	GetInstance(serviceId string) *InstanceInfo
 	GetInstanceById(serviceId string, instanceId string) *InstanceInfo
 
+	// 发送心跳，返回 false 表示注册中心已丢失该实例，需要重新注册
 	SendHeartBeat(instance *InstanceInfo, status string) (bool, error)
+
+	// 监听服务实例变化，返回取消函数；不支持时返回 ErrWatchNotSupported，由定时刷新兜底
+	Watch(serviceId string, listener func(instances []InstanceInfo)) (func(), error)
+}
+
+// 主动更新实例状态（UP / DOWN / OUT_OF_SERVICE / STARTING），未实现时状态随心跳上报
+type StatusUpdater interface {
+	UpdateStatus(instance *InstanceInfo, status string) error
 }
 
 type AbstractDiscoveryClient struct {
@@ -113,7 +153,7 @@ func (c *AbstractDiscoveryClient) GetService(serviceId string) (*ServiceInfo, er
 }
 
 func (c *AbstractDiscoveryClient) GetInstances(serviceId string) ([]InstanceInfo, error) {
-	if service, err := c.GetService(serviceId); err == nil {
+	if service, err := c.GetService(serviceId); err == nil && service != nil {
 		return service.Instances, nil
 	} else {
 		return nil, err
@@ -121,63 +161,85 @@ func (c *AbstractDiscoveryClient) GetInstances(serviceId string) ([]InstanceInfo
 }
 
 func (c *AbstractDiscoveryClient) GetInstance(serviceId string) *InstanceInfo {
-	if instances, err := c.GetInstances(serviceId); err == nil && len(instances) > 0 {
-		activeInstances := []*InstanceInfo{}
-		for i, ii := range instances {
-			if ii.Status == "UP" {
-				activeInstances = append(activeInstances, &instances[i])
-			}
-		}
-		if len(activeInstances) > 0 {
-			return activeInstances[rand.Intn(len(activeInstances))]
-		}
+	if instances, err := c.GetInstances(serviceId); err == nil {
+		return PickInstance(instances)
 	}
 	return nil
 }
 
 func (c *AbstractDiscoveryClient) GetInstanceById(serviceId string, instanceId string) *InstanceInfo {
 	if instances, err := c.GetInstances(serviceId); err == nil && len(instances) > 0 {
-		for _, instance := range instances {
-			if instance.InstanceId == instanceId {
-				return &instance
+		for i := range instances {
+			if instances[i].InstanceId == instanceId {
+				return &instances[i]
 			}
 		}
 	}
 	return nil
 }
 
-func (*AbstractDiscoveryClient) SendHeartBeat() (bool, error) {
+func (*AbstractDiscoveryClient) SendHeartBeat(instance *InstanceInfo, status string) (bool, error) {
 	return true, nil
 }
 
-// type DiscoveryClient interface {
-// 	DiscoveryStatusUpdate(status string) (bool, error)
-// 	GetApplications(regions ...string) (*ApplicationList, error)
-// 	GetApplication(appName string) (*ApplicationInfo, error)
-// 	GetInstance(appName, instanceId string) (*InstanceInfo, error)
-// 	GetInstanceById(instanceId string) (*InstanceInfo, error)
-// 	Shutdown()
-// }
+func (*AbstractDiscoveryClient) Watch(serviceId string, listener func(instances []InstanceInfo)) (func(), error) {
+	return nil, ErrWatchNotSupported
+}
+
+// 注册中心实现工厂，各注册中心在 init 中注册，由 registry.type 选择
+type DiscoveryClientFactory func() (DiscoveryClient, error)
+
+var (
+	factories      = make(map[string]DiscoveryClientFactory)
+	factoriesMutex sync.RWMutex
+)
+
+func RegisterDiscoveryClient(name string, factory DiscoveryClientFactory) {
+	factoriesMutex.Lock()
+	defer factoriesMutex.Unlock()
+	factories[name] = factory
+}
+
+func CreateDiscoveryClient(name string) (DiscoveryClient, error) {
+	factoriesMutex.RLock()
+	factory, ok := factories[name]
+	factoriesMutex.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown registry type: %s", name)
+	}
+	return factory()
+}
 
 type Client interface {
 	GetServiceEntry(service string) (string, error)
+
+	// 为一次调用选择实例，服务不存在时返回 nil
+	Select(service string) (*Selection, error)
 }
 
 type RegistryClient struct {
 	InstanceInfo
-	discoveryClient DiscoveryClient `inject:"discoveryClient"`
+	discoveryClient DiscoveryClient
 	services        *cache.Cache
+	watches         map[string]func()
+	registered      bool
+	reportedStatus  string
+	mutex           sync.Mutex
+	statusMutex     sync.Mutex
 	wg              sync.WaitGroup
 	closeChan       chan struct{}
 }
 
-func NewRegistryClient() *RegistryClient {
+func NewRegistryClient(discoveryClient DiscoveryClient) *RegistryClient {
 	currentTimeStr := fmt.Sprintf("%d", time.Now().UnixNano()/1000000)
-	return &RegistryClient{
+	hostName, _ := os.Hostname()
+
+	result := &RegistryClient{
 		InstanceInfo: InstanceInfo{
 			ServiceName: config.Setting.ServiceName,
-			InstanceId:  "",
-			HostName:    "",
+			InstanceId:  config.Setting.InstanceId,
+			HostName:    hostName,
 			IpAddr:      getPreferIP(),
 			Status:      STATUS_UP,
 			Port: PortInfo{
@@ -185,81 +247,168 @@ func NewRegistryClient() *RegistryClient {
 				Enabled: true,
 			},
 			OverriddenStatus:     STATUS_UP,
+			Meta:                 config.Setting.Metadata,
 			VipAddress:           config.Setting.ServiceName,
 			SecureVipAddress:     config.Setting.ServiceName,
 			LastUpdatedTimestamp: currentTimeStr,
 			LastDirtyTimestamp:   currentTimeStr,
 		},
-		services:  cache.New(time.Minute*5, time.Minute),
-		closeChan: make(chan struct{}),
+		discoveryClient: discoveryClient,
+		services:        cache.New(time.Minute*5, time.Minute),
+		watches:         make(map[string]func()),
+		closeChan:       make(chan struct{}),
+	}
+
+	if zone := config.Setting.Zone(); zone != "" {
+		meta := map[string]string{"zone": zone}
+		for k, v := range config.Setting.Metadata {
+			meta[k] = v
+		}
+		result.Meta = meta
+	}
+	if config.Setting.Secure {
+		result.SecurePort = PortInfo{
+			Port:    config.Setting.Port,
+			Enabled: true,
+		}
+	}
+	if config.Setting.HealthCheckPath != "" {
+		result.HealthCheckUrl = fmt.Sprintf("http://%s:%d%s", result.IpAddr, config.Setting.Port, config.Setting.HealthCheckPath)
+		if config.Setting.Secure {
+			result.HealthCheckUrl = "https" + strings.TrimPrefix(result.HealthCheckUrl, "http")
+		}
 	}
+	return result
 }
 
 func (s *RegistryClient) Init() {
 	if config.Setting.EnableAutoRegister {
-		// 1. 自动注册
-		s.Register()
+		// 1. 自动注册，按当前就绪状态注册，就绪状态变化时立即上报
+		s.Status = healthStatus(health.State())
+		s.reportedStatus = s.Status
+		if _, err := s.Register(); err != nil {
+			logger.Error("RegistryClient register error, ", err.Error())
+		}
+		health.OnReadinessChanged(func(status health.Status) {
+			s.UpdateStatus(healthStatus(status))
+		})
+
+		// 2. 和RegistryServer保持心跳
+		s.wg.Add(1)
+		go s.heartbeatTask()
 	}
 
-	// 2. 定时拉取服务
+	// 3. 定时拉取服务
+	s.wg.Add(1)
 	go s.loadServicesTask()
+}
 
-	// 3. 和RegistryServer保持心跳
-	go s.heartbeatTask()
+func interval(value, defaultValue time.Duration) time.Duration {
+	if value > 0 {
+		return value
+	}
+	return defaultValue
 }
 
 func (s *RegistryClient) loadServicesTask() {
-	ticker := time.NewTicker(time.Minute)
-	s.wg.Add(1)
-	for terminated := false; !terminated; {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(interval(config.Setting.RefreshInterval, time.Minute))
+	defer ticker.Stop()
+
+	for {
 		select {
 		case <-ticker.C:
 			_, err := s.loadServices()
 			if err != nil {
-				logger.Warn("RegistryClientStub load services error, ", err.Error())
+				logger.Warn("RegistryClient load services error, ", err.Error())
 			}
 		case <-s.closeChan:
-			terminated = true
+			return
 		}
 	}
-	s.wg.Done()
 }
 
 func (s *RegistryClient) heartbeatTask() {
-	ticker := time.NewTicker(time.Minute)
-	s.wg.Add(1)
-	for terminated := false; !terminated; {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(interval(config.Setting.HeartbeatInterval, time.Second*30))
+	defer ticker.Stop()
+
+	for {
 		select {
 		case <-ticker.C:
 			_, err := s.sendHeartBeat()
 			if err != nil {
-				logger.Warn("RegistryClientStub send heartbeat error, ", err.Error())
+				logger.Warn("RegistryClient send heartbeat error, ", err.Error())
 			}
 		case <-s.closeChan:
-			terminated = true
+			return
 		}
 	}
-	s.wg.Done()
 }
 
-// send heartbeat to eureka service
+func healthStatus(status health.Status) string {
+	switch status {
+	case health.STATUS_UP:
+		return STATUS_UP
+	case health.STATUS_DOWN:
+		return STATUS_DOWN
+	case health.STATUS_STARTING:
+		return STATUS_STARTING
+	case health.STATUS_OUT_OF_SERVICE:
+		return STATUS_OUT_OF_SERVICE
+	}
+	return STATUS_UNKOWN
+}
+
+// 向注册中心上报实例状态，状态未变化时忽略
+func (s *RegistryClient) UpdateStatus(status string) {
+	s.statusMutex.Lock()
+	defer s.statusMutex.Unlock()
+
+	if status == s.reportedStatus {
+		return
+	}
+
+	if updater, ok := s.discoveryClient.(StatusUpdater); ok && s.registered {
+		if err := updater.UpdateStatus(&s.InstanceInfo, status); err != nil {
+			logger.Warn("RegistryClient update status [", status, "] error, ", err.Error())
+			return
+		}
+	}
+
+	logger.Info("RegistryClient instance status: ", s.reportedStatus, " -> ", status)
+	s.reportedStatus = status
+	s.Status = status
+	s.OverriddenStatus = status
+}
+
+// send heartbeat to registry service
 func (s *RegistryClient) sendHeartBeat() (success bool, err error) {
-	success, err = s.discoveryClient.SendHeartBeat(&s.InstanceInfo, s.OverriddenStatus)
+	// 按就绪检查结果上报状态
+	s.UpdateStatus(healthStatus(health.ReadinessStatus(context.Background())))
+
+	s.statusMutex.Lock()
+	defer s.statusMutex.Unlock()
+
+	status := s.reportedStatus
+	success, err = s.discoveryClient.SendHeartBeat(&s.InstanceInfo, status)
 	if err != nil {
 		return false, err
 	}
 
 	if !success {
 		s.Status = "DIRTY"
-		s.OverriddenStatus = "UP"
 		s.LastDirtyTimestamp = fmt.Sprintf("%d", time.Now().UnixNano()/1000000)
 
 		// try register
-		if success, _ = s.Register(); success {
-			s.Status = "UP"
+		s.Status = status
+		if success, _ = s.Register(); !success {
+			s.Status = "DIRTY"
 		}
 	} else {
-		s.Status = s.OverriddenStatus
+		s.Status = status
 	}
 	return true, nil
 }
@@ -270,7 +419,8 @@ func (s *RegistryClient) loadServices() (bool, error) {
 
 	// load current services
 	if services, err := s.discoveryClient.GetServices(); err == nil {
-		for _, si := range services {
+		for i := range services {
+			si := services[i]
 			if instances, err := s.discoveryClient.GetInstances(si.Name); err == nil {
 				si.Instances = instances
 			}
@@ -282,6 +432,27 @@ func (s *RegistryClient) loadServices() (bool, error) {
 	}
 }
 
+// 首次访问服务时开始监听实例变化
+func (s *RegistryClient) watch(name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.watches[name]; ok {
+		return
+	}
+
+	cancel, err := s.discoveryClient.Watch(name, func(instances []InstanceInfo) {
+		s.services.SetDefault(name, &ServiceInfo{Name: name, Instances: instances})
+	})
+	if err != nil {
+		if err != ErrWatchNotSupported {
+			logger.Warn("RegistryClient watch service [", name, "] error, ", err.Error())
+		}
+		cancel = nil
+	}
+	s.watches[name] = cancel
+}
+
 func (s *RegistryClient) GetServices() []ServiceInfo {
 	result := make([]ServiceInfo, 0)
 	for _, item := range s.services.Items() {
@@ -296,11 +467,12 @@ func (s *RegistryClient) GetService(name string) *ServiceInfo {
 	}
 
 	// 从注册中心获取服务
-	if service, err := s.discoveryClient.GetService(name); err == nil {
+	if service, err := s.discoveryClient.GetService(name); err == nil && service != nil {
 		if instances, err := s.discoveryClient.GetInstances(service.Name); err == nil {
 			service.Instances = instances
 		}
 		s.services.SetDefault(name, service)
+		s.watch(name)
 		return service
 	}
 	return nil
@@ -315,20 +487,27 @@ func (s *RegistryClient) GetInstances(serviceName string) []InstanceInfo {
 
 func (s *RegistryClient) GetInstance(serviceName string) *InstanceInfo {
 	if si := s.GetService(serviceName); si != nil {
-		if len(si.Instances) > 0 {
-			return &si.Instances[rand.Intn(len(si.Instances))]
-		}
+		return PickInstance(si.Instances)
 	}
 	return nil
 }
 
+func (s *RegistryClient) Select(serviceName string) (*Selection, error) {
+	si := s.GetService(serviceName)
+	if si == nil {
+		return nil, nil
+	}
+	if instance := PickInstance(si.Instances); instance != nil {
+		return NewSelection(instance), nil
+	}
+	return nil, ErrNoInstance
+}
+
 func (s *RegistryClient) GetInstanceById(serviceName string, instanceId string) *InstanceInfo {
 	if si := s.GetService(serviceName); si != nil {
-		if len(si.Instances) > 0 {
-			for _, ii := range si.Instances {
-				if ii.InstanceId == instanceId {
-					return &ii
-				}
+		for i := range si.Instances {
+			if si.Instances[i].InstanceId == instanceId {
+				return &si.Instances[i]
 			}
 		}
 	}
@@ -337,21 +516,26 @@ func (s *RegistryClient) GetInstanceById(serviceName string, instanceId string)
 
 func (s *RegistryClient) Register() (bool, error) {
 	// 微服务应用启动时调用此进行注册
-	return s.discoveryClient.Register()
+	success, err := s.discoveryClient.Register(&s.InstanceInfo)
+	if success {
+		s.registered = true
+		logger.Info("Registered service [", s.ServiceName, "] instance [", s.InstanceId, "] at ", s.Entry())
+	}
+	return success, err
 }
 
 func (s *RegistryClient) Deregister() error {
-	// 微服务应用启动时调用此进行注册
-	return s.discoveryClient.Deregister()
+	// 微服务应用退出时调用此进行注销
+	s.registered = false
+	return s.discoveryClient.Deregister(&s.InstanceInfo)
 }
 
-func (s *RegistryClient) GetSerivceEntry(serviceName string) (string, error) {
+func (s *RegistryClient) GetServiceEntry(serviceName string) (string, error) {
 	if si := s.GetService(serviceName); si != nil {
-		if len(si.Instances) > 0 {
-			instanceInfo := si.Instances[rand.Intn(len(si.Instances))]
-			return fmt.Sprintf("http://%s:%d", instanceInfo.HostName, instanceInfo.Port.Port), nil
+		if instanceInfo := PickInstance(si.Instances); instanceInfo != nil {
+			return instanceInfo.Entry(), nil
 		} else {
-			return "", errors.New("no instance")
+			return "", ErrNoInstance
 		}
 	}
 	return "", nil
@@ -359,7 +543,21 @@ func (s *RegistryClient) GetSerivceEntry(serviceName string) (string, error) {
 
 func (s *RegistryClient) Shutdown() {
 	close(s.closeChan)
-	s.Deregister()
+
+	s.mutex.Lock()
+	for _, cancel := range s.watches {
+		if cancel != nil {
+			cancel()
+		}
+	}
+	s.watches = make(map[string]func())
+	s.mutex.Unlock()
+
+	if s.registered {
+		if err := s.Deregister(); err != nil {
+			logger.Warn("RegistryClient deregister error, ", err.Error())
+		}
+	}
 	s.wg.Wait()
 }
 
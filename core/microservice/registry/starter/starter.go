@@ -1,27 +1,64 @@
 package starter
 
 import (
+	"context"
+
 	_ "github.com/gophab/gophrame/core/microservice/registry/consul/starter"
 	_ "github.com/gophab/gophrame/core/microservice/registry/dubbo/starter"
 	_ "github.com/gophab/gophrame/core/microservice/registry/eureka/starter"
 	_ "github.com/gophab/gophrame/core/microservice/registry/nacos/starter"
 
+	"github.com/gophab/gophrame/core/health"
 	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/logger"
 	"github.com/gophab/gophrame/core/microservice/registry"
 	"github.com/gophab/gophrame/core/microservice/registry/config"
 	"github.com/gophab/gophrame/core/starter"
 )
 
+var registryClient *registry.RegistryClient
+
 func init() {
 	starter.RegisterStarter(Start)
+	starter.RegisterTerminater(Terminate)
+
+	// 注册中心不可用时不影响已注册实例接收流量，仅作为可选检查项
+	health.RegisterChecker(&health.Checker{
+		Name: "registry",
+		Check: func(ctx context.Context) error {
+			if discoveryClient, ok := inject.GetValue("discoveryClient").(registry.DiscoveryClient); ok {
+				_, err := discoveryClient.GetServices()
+				return err
+			}
+			return nil
+		},
+		Condition: func() bool { return config.Setting.Enabled },
+		Readiness: true,
+		Optional:  true,
+	})
 }
 
 func Start() {
 	if config.Setting.Enabled {
+		backend := config.Setting.Backend()
+		discoveryClient, err := registry.CreateDiscoveryClient(backend)
+		if err != nil {
+			logger.Error("Create discovery client [", backend, "] error: ", err.Error())
+			return
+		}
+		inject.InjectValue("discoveryClient", discoveryClient)
+
 		// 启动RegistryClient
-		registryClient := registry.NewRegistryClient()
+		registryClient = registry.NewRegistryClient(discoveryClient)
 		inject.InjectValue("registryClient", registryClient)
 
 		registryClient.Init()
 	}
 }
+
+// 应用退出时注销实例
+func Terminate() {
+	if registryClient != nil {
+		registryClient.Shutdown()
+	}
+}
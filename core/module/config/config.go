@@ -0,0 +1,31 @@
+package config
+
+import (
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+type ModuleSetting struct {
+	Enabled bool `json:"enabled"`
+}
+
+// 模块开关，未配置的模块默认启用
+//
+//	modules:
+//	  redis:
+//	    enabled: false
+type ModulesSetting map[string]*ModuleSetting
+
+var Setting ModulesSetting = make(ModulesSetting)
+
+func (s ModulesSetting) IsEnabled(name string) bool {
+	if setting, b := s[name]; b && setting != nil {
+		return setting.Enabled
+	}
+	return true
+}
+
+func init() {
+	logger.Debug("Register Module Config")
+	config.RegisterConfig("modules", &Setting, "Module Settings")
+}
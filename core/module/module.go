@@ -1,10 +1,15 @@
 package module
 
 import (
+	"errors"
+	"fmt"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/module/config"
 	"github.com/gophab/gophrame/core/starter"
 )
 
@@ -13,13 +18,13 @@ type IModule interface {
 	Register()
 
 	// Call when initializing, after init config
-	Init()
+	Init() error
 
 	// Call when start, after root router initialized
-	Start()
+	Start() error
 
 	// Call before exit
-	Terminate()
+	Terminate() error
 }
 
 const (
@@ -28,80 +33,459 @@ const (
 	STATUS_INITIALIZED
 	STATUS_STARTED
 	STATUS_TERMINATED
+	STATUS_DISABLED // 配置关闭或条件不满足
+	STATUS_FAILED   // 本模块生命周期函数执行失败
+	STATUS_SKIPPED  // 依赖模块不可用，本模块未执行
 )
 
+var statusText = map[int]string{
+	STATUS:             "UNKNOWN",
+	STATUS_REGISTERED:  "REGISTERED",
+	STATUS_INITIALIZED: "INITIALIZED",
+	STATUS_STARTED:     "STARTED",
+	STATUS_TERMINATED:  "TERMINATED",
+	STATUS_DISABLED:    "DISABLED",
+	STATUS_FAILED:      "FAILED",
+	STATUS_SKIPPED:     "SKIPPED",
+}
+
+func StatusText(status int) string {
+	return statusText[status]
+}
+
+// 状态机：允许的状态迁移
+var transitions = map[int][]int{
+	STATUS:             {STATUS_REGISTERED},
+	STATUS_REGISTERED:  {STATUS_INITIALIZED, STATUS_DISABLED, STATUS_FAILED, STATUS_SKIPPED},
+	STATUS_INITIALIZED: {STATUS_STARTED, STATUS_FAILED, STATUS_SKIPPED, STATUS_TERMINATED},
+	STATUS_STARTED:     {STATUS_TERMINATED, STATUS_FAILED},
+	STATUS_FAILED:      {STATUS_TERMINATED},
+	STATUS_SKIPPED:     {STATUS_TERMINATED},
+}
+
+const (
+	PHASE_REGISTER  = "register"
+	PHASE_INIT      = "init"
+	PHASE_START     = "start"
+	PHASE_TERMINATE = "terminate"
+)
+
+// 每个生命周期阶段的执行情况
+type PhaseStatus struct {
+	Phase    string        `json:"phase"`
+	Status   string        `json:"status"`
+	Time     time.Time     `json:"time"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+type ModuleStatus struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Status      string        `json:"status"`
+	Builtin     bool          `json:"builtin,omitempty"`
+	Depends     []string      `json:"depends,omitempty"`
+	Optional    []string      `json:"optional,omitempty"`
+	Error       string        `json:"error,omitempty"`
+	Phases      []PhaseStatus `json:"phases"`
+}
+
 type Module struct {
 	IModule
 	Name        string
 	Description string
-	Initializor func(m *Module)
-	Starter     func(m *Module)
-	Terminater  func(m *Module)
-	Priority    int
+	Depends     []string // 必需依赖：依赖模块不可用时，本模块不会初始化/启动
+	Optional    []string // 可选依赖：依赖模块存在时先于本模块执行
+	Condition   func() bool
+	Initializor func(m *Module) error
+	Starter     func(m *Module) error
+	Terminater  func(m *Module) error
+	Priority    int // 同一依赖层级内的排序
+	Builtin     bool
 	Status      int
+	Error       error
+	phases      []PhaseStatus
+	initialized bool // 已完成初始化：启动被跳过或失败时仍需终止以释放资源
+	mutex       sync.RWMutex
 }
 
-func (m *Module) Terminate() {
+func (m *Module) Terminate() error {
 	if m.Terminater != nil {
 		logger.Debug("[MODULE] Terminating module: ", m.Name)
-		m.Terminater(m)
+		return m.Terminater(m)
 	}
+	return nil
 }
 
-func (m *Module) Start() {
+func (m *Module) Start() error {
 	if m.Starter != nil {
 		logger.Debug("[MODULE] Starting module: ", m.Name)
-		m.Starter(m)
+		return m.Starter(m)
 	}
+	return nil
 }
 
-func (m *Module) Init() {
+func (m *Module) Init() error {
 	if m.Initializor != nil {
 		logger.Debug("[MODULE] Initializing module: ", m.Name)
-		m.Initializor(m)
+		return m.Initializor(m)
 	}
+	return nil
 }
 
 func (*Module) Register() {}
 
-var modules = make([]*Module, 0)
+func (m *Module) Available() bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.Status == STATUS_INITIALIZED || m.Status == STATUS_STARTED
+}
+
+func (m *Module) GetStatus() *ModuleStatus {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	result := &ModuleStatus{
+		Name:        m.Name,
+		Description: m.Description,
+		Status:      StatusText(m.Status),
+		Builtin:     m.Builtin,
+		Depends:     m.Depends,
+		Optional:    m.Optional,
+		Phases:      append([]PhaseStatus{}, m.phases...),
+	}
+	if m.Error != nil {
+		result.Error = m.Error.Error()
+	}
+	return result
+}
+
+func (m *Module) isInitialized() bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.initialized
+}
+
+func (m *Module) transit(phase string, status int, err error, begin time.Time) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	allowed := false
+	for _, s := range transitions[m.Status] {
+		if s == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		logger.Warn("[MODULE] Invalid status transition of module [", m.Name, "]: ", StatusText(m.Status), " -> ", StatusText(status))
+		return false
+	}
+
+	m.Status = status
+	if status == STATUS_INITIALIZED {
+		m.initialized = true
+	}
+	if err != nil {
+		m.Error = err
+	}
+
+	phaseStatus := PhaseStatus{
+		Phase:    phase,
+		Status:   StatusText(status),
+		Time:     begin,
+		Duration: time.Since(begin),
+	}
+	if err != nil {
+		phaseStatus.Error = err.Error()
+	}
+	m.phases = append(m.phases, phaseStatus)
+	return true
+}
+
+// 执行生命周期函数，panic 视为失败
+func (m *Module) call(phase string, f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s panic: %v", phase, r)
+		}
+	}()
+	return f()
+}
+
+var (
+	modules       = make([]*Module, 0)
+	moduleIndex   = make(map[string]*Module)
+	modulesMutex  sync.RWMutex
+	orderedModule []*Module
+)
 
 func RegisterModule(mod *Module) {
+	modulesMutex.Lock()
+	defer modulesMutex.Unlock()
+
+	if mod.Name == "" {
+		logger.Error("[MODULE] Register module without name")
+		return
+	}
+
+	if _, b := moduleIndex[mod.Name]; b {
+		logger.Warn("[MODULE] Module already registered: ", mod.Name)
+		return
+	}
+
 	modules = append(modules, mod)
-	mod.Register()
-	mod.Status = STATUS_REGISTERED
+	moduleIndex[mod.Name] = mod
+
+	if mod.IModule != nil {
+		mod.IModule.Register()
+	} else {
+		mod.Register()
+	}
+	mod.transit(PHASE_REGISTER, STATUS_REGISTERED, nil, time.Now())
+}
+
+// 内置模块：由框架子系统(database/redis/...)自行初始化，模块系统仅校验其可用性，
+// 使业务模块可以通过 Depends 声明对子系统的依赖
+func RegisterBuiltinModule(name string, description string, condition func() bool, check func() error) {
+	RegisterModule(&Module{
+		Name:        name,
+		Description: description,
+		Builtin:     true,
+		Priority:    -1,
+		Condition:   condition,
+		Initializor: func(m *Module) error {
+			if check != nil {
+				return check()
+			}
+			return nil
+		},
+	})
+}
+
+func GetModule(name string) *Module {
+	modulesMutex.RLock()
+	defer modulesMutex.RUnlock()
+	return moduleIndex[name]
+}
+
+func GetModuleStatus(name string) *ModuleStatus {
+	if mod := GetModule(name); mod != nil {
+		return mod.GetStatus()
+	}
+	return nil
+}
+
+func GetModulesStatus() []*ModuleStatus {
+	modulesMutex.RLock()
+	defer modulesMutex.RUnlock()
+
+	list := orderedModule
+	if list == nil {
+		list = modules
+	}
+
+	result := make([]*ModuleStatus, 0, len(list))
+	for _, mod := range list {
+		result = append(result, mod.GetStatus())
+	}
+	return result
+}
+
+// 按依赖关系拓扑排序，同层按 Priority 排序；返回排序结果及构成循环依赖的模块
+func sortModules(list []*Module, index map[string]*Module) ([]*Module, []*Module) {
+	inDegree := make(map[string]int)
+	dependents := make(map[string][]*Module)
+
+	for _, mod := range list {
+		inDegree[mod.Name] += 0
+		for _, dep := range append(append([]string{}, mod.Depends...), mod.Optional...) {
+			if _, b := index[dep]; b {
+				inDegree[mod.Name]++
+				dependents[dep] = append(dependents[dep], mod)
+			}
+		}
+	}
+
+	less := func(a, b *Module) bool {
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		return a.Name < b.Name
+	}
+
+	ready := make([]*Module, 0)
+	for _, mod := range list {
+		if inDegree[mod.Name] == 0 {
+			ready = append(ready, mod)
+		}
+	}
+
+	result := make([]*Module, 0, len(list))
+	for len(ready) > 0 {
+		sort.SliceStable(ready, func(i, j int) bool { return less(ready[i], ready[j]) })
+		mod := ready[0]
+		ready = ready[1:]
+		result = append(result, mod)
+
+		for _, dependent := range dependents[mod.Name] {
+			inDegree[dependent.Name]--
+			if inDegree[dependent.Name] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	cycle := make([]*Module, 0)
+	for _, mod := range list {
+		if inDegree[mod.Name] > 0 {
+			cycle = append(cycle, mod)
+		}
+	}
+	sort.SliceStable(cycle, func(i, j int) bool { return less(cycle[i], cycle[j]) })
+
+	return result, cycle
+}
+
+// 检查必需依赖是否处于给定状态
+func checkDepends(mod *Module, statuses ...int) error {
+	for _, dep := range mod.Depends {
+		depModule := moduleIndex[dep]
+		if depModule == nil {
+			return fmt.Errorf("dependency module [%s] not registered", dep)
+		}
+
+		depModule.mutex.RLock()
+		status := depModule.Status
+		depModule.mutex.RUnlock()
+
+		matched := false
+		for _, s := range statuses {
+			if s == status {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("dependency module [%s] is %s", dep, StatusText(status))
+		}
+	}
+	return nil
 }
 
 var (
-	onceInit, onceStart sync.Once
+	onceInit, onceStart, onceTerminate sync.Once
 )
 
 func Init() {
 	onceInit.Do(func() {
-		sort.Slice(modules, func(i, j int) bool {
-			return modules[i].Priority < modules[j].Priority
-		})
-		for _, mod := range modules {
-			mod.Init()
-			mod.Status = STATUS_INITIALIZED
+		modulesMutex.Lock()
+		defer modulesMutex.Unlock()
+
+		sorted, cycle := sortModules(modules, moduleIndex)
+		if len(cycle) > 0 {
+			names := make([]string, 0, len(cycle))
+			for _, mod := range cycle {
+				names = append(names, mod.Name)
+			}
+			err := errors.New("circular module dependency: " + strings.Join(names, ", "))
+			logger.Error("[MODULE] ", err.Error())
+			for _, mod := range cycle {
+				mod.transit(PHASE_INIT, STATUS_FAILED, err, time.Now())
+			}
+		}
+		orderedModule = append(sorted, cycle...)
+
+		for _, mod := range sorted {
+			begin := time.Now()
+
+			if !config.Setting.IsEnabled(mod.Name) || (mod.Condition != nil && !mod.Condition()) {
+				logger.Info("[MODULE] Module disabled: ", mod.Name)
+				mod.transit(PHASE_INIT, STATUS_DISABLED, nil, begin)
+				continue
+			}
+
+			if err := checkDepends(mod, STATUS_INITIALIZED); err != nil {
+				logger.Warn("[MODULE] Skip module [", mod.Name, "]: ", err.Error())
+				mod.transit(PHASE_INIT, STATUS_SKIPPED, err, begin)
+				continue
+			}
+
+			var err error
+			if mod.IModule != nil {
+				err = mod.call(PHASE_INIT, mod.IModule.Init)
+			} else {
+				err = mod.call(PHASE_INIT, mod.Init)
+			}
+
+			if err != nil {
+				logger.Error("[MODULE] Initializing module [", mod.Name, "] error: ", err.Error())
+				mod.transit(PHASE_INIT, STATUS_FAILED, err, begin)
+			} else {
+				mod.transit(PHASE_INIT, STATUS_INITIALIZED, nil, begin)
+			}
 		}
 	})
 }
 
 func Start() {
 	onceStart.Do(func() {
-		for _, mod := range modules {
-			mod.Start()
-			mod.Status = STATUS_STARTED
+		modulesMutex.RLock()
+		defer modulesMutex.RUnlock()
+
+		for _, mod := range orderedModule {
+			if mod.Status != STATUS_INITIALIZED {
+				continue
+			}
+
+			begin := time.Now()
+			if err := checkDepends(mod, STATUS_STARTED); err != nil {
+				logger.Warn("[MODULE] Skip module [", mod.Name, "]: ", err.Error())
+				mod.transit(PHASE_START, STATUS_SKIPPED, err, begin)
+				continue
+			}
+
+			var err error
+			if mod.IModule != nil {
+				err = mod.call(PHASE_START, mod.IModule.Start)
+			} else {
+				err = mod.call(PHASE_START, mod.Start)
+			}
+
+			if err != nil {
+				logger.Error("[MODULE] Starting module [", mod.Name, "] error: ", err.Error())
+				mod.transit(PHASE_START, STATUS_FAILED, err, begin)
+			} else {
+				mod.transit(PHASE_START, STATUS_STARTED, nil, begin)
+			}
 		}
 	})
 }
 
 func Terminate() {
-	onceStart.Do(func() {
-		for _, mod := range modules {
-			mod.Terminate()
-			mod.Status = STATUS_TERMINATED
+	onceTerminate.Do(func() {
+		modulesMutex.RLock()
+		defer modulesMutex.RUnlock()
+
+		// 逆序终止：被依赖的模块最后终止
+		for i := len(orderedModule) - 1; i >= 0; i-- {
+			mod := orderedModule[i]
+			if !mod.isInitialized() || mod.Status == STATUS_TERMINATED {
+				continue
+			}
+
+			begin := time.Now()
+			var err error
+			if mod.IModule != nil {
+				err = mod.call(PHASE_TERMINATE, mod.IModule.Terminate)
+			} else {
+				err = mod.call(PHASE_TERMINATE, mod.Terminate)
+			}
+
+			if err != nil {
+				logger.Error("[MODULE] Terminating module [", mod.Name, "] error: ", err.Error())
+			}
+			mod.transit(PHASE_TERMINATE, STATUS_TERMINATED, err, begin)
 		}
 	})
 }
@@ -1,13 +1,13 @@
 package permission
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/gophab/gophrame/core/global"
 	"github.com/gophab/gophrame/core/inject"
 	SecurityUtil "github.com/gophab/gophrame/core/security/util"
 	"github.com/gophab/gophrame/core/webservice/response"
+	"github.com/gophab/gophrame/errors"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,13 +25,9 @@ func NeedSystemUser() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tenantId := SecurityUtil.GetCurrentTenantId(c)
 		if tenantId == "" {
-			response.Unauthorized(c, "用户未登录")
+			response.AbortWithError(c, errors.ErrUnauthorized)
 		} else if tenantId != "SYSTEM" {
-			c.JSON(http.StatusForbidden, gin.H{
-				"code": http.StatusForbidden,
-				"data": "登录用户没有权限",
-				"msg":  "ok",
-			})
+			response.AbortWithError(c, errors.ErrForbidden)
 		} else {
 			c.Next()
 		}
@@ -43,19 +39,9 @@ func NeedAdmin() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user := SecurityUtil.GetCurrentUser(c)
 		if user == nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code": http.StatusUnauthorized,
-				"data": "未登录用户",
-				"msg":  "ok",
-			})
-			c.Abort()
+			response.AbortWithError(c, errors.ErrUnauthorized)
 		} else if !user.Admin {
-			c.JSON(http.StatusForbidden, gin.H{
-				"code": http.StatusForbidden,
-				"data": "登录用户没有权限",
-				"msg":  "ok",
-			})
-			c.Abort()
+			response.AbortWithError(c, errors.ErrForbidden)
 		} else {
 			c.Next()
 		}
@@ -74,7 +60,7 @@ func CheckUserRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user := SecurityUtil.GetCurrentUser(c)
 		if user == nil || user.UserId == nil {
-			response.Unauthorized(c, "用户未登录")
+			response.AbortWithError(c, errors.ErrUnauthorized)
 			return
 		}
 
@@ -84,7 +70,7 @@ func CheckUserRole(roles ...string) gin.HandlerFunc {
 				return
 			}
 		}
-		response.ErrorMessage(c, http.StatusForbidden, http.StatusMethodNotAllowed, "登录用户没有权限")
+		response.AbortWithError(c, errors.ErrForbidden)
 	}
 }
 
@@ -98,7 +84,7 @@ func CheckUserPermissions() gin.HandlerFunc {
 
 		userId := SecurityUtil.GetCurrentUserId(c)
 		if userId == "" {
-			response.Unauthorized(c, "用户未登录")
+			response.AbortWithError(c, errors.ErrUnauthorized)
 			return
 		}
 
@@ -110,10 +96,10 @@ func CheckUserPermissions() gin.HandlerFunc {
 		// 从权限服务中检查接口调用权限
 		ok, err := __.PermissionService.CheckPermission(userId, resource, action)
 		if err != nil {
-			response.Unauthorized(c, "用户未登录")
+			response.AbortWithError(c, errors.ErrUnauthorized)
 			return
 		} else if !ok {
-			response.ErrorMessage(c, http.StatusForbidden, http.StatusMethodNotAllowed, "登录用户没有权限")
+			response.AbortWithError(c, errors.ErrForbidden)
 			return
 		} else {
 			c.Next()
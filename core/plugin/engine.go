@@ -1,5 +1,13 @@
 package plugin
 
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/router"
+)
+
 type EntryPoint struct {
 	NameSpace string
 	Code      string
@@ -10,26 +18,138 @@ type CallbackPoint struct {
 	NameSpace string
 	Code      string
 	Callbacks []func(args ...interface{})
+	owners    []*Plugin
 }
 
-var EntryPoints = make(map[string]EntryPoint)
-var CallbackPoints = make(map[string]CallbackPoint)
+var EntryPoints = make(map[string]*EntryPoint)
+var CallbackPoints = make(map[string]*CallbackPoint)
+var pointsMutex sync.RWMutex
 
 func RegisterEntryPoint(entryPoint *EntryPoint) {
-	EntryPoints[entryPoint.NameSpace+":"+entryPoint.Code] = *entryPoint
+	pointsMutex.Lock()
+	defer pointsMutex.Unlock()
+	EntryPoints[entryPoint.NameSpace+":"+entryPoint.Code] = entryPoint
 }
 
 func RegisterCallbackPoint(callback *CallbackPoint) {
-	CallbackPoints[callback.NameSpace+":"+callback.Code] = *callback
+	pointsMutex.Lock()
+	defer pointsMutex.Unlock()
+
+	key := callback.NameSpace + ":" + callback.Code
+	if cp, b := CallbackPoints[key]; b {
+		// 保留已注册的回调
+		for _, f := range callback.Callbacks {
+			cp.Callbacks = append(cp.Callbacks, f)
+			cp.owners = append(cp.owners, nil)
+		}
+		return
+	}
+
+	callback.owners = make([]*Plugin, len(callback.Callbacks))
+	CallbackPoints[key] = callback
+}
+
+func registerCallback(owner *Plugin, namespace, code string, f func(args ...interface{})) {
+	pointsMutex.Lock()
+	defer pointsMutex.Unlock()
+
+	key := namespace + ":" + code
+	cp, b := CallbackPoints[key]
+	if !b {
+		cp = &CallbackPoint{NameSpace: namespace, Code: code}
+		CallbackPoints[key] = cp
+	}
+	cp.Callbacks = append(cp.Callbacks, f)
+	cp.owners = append(cp.owners, owner)
 }
 
 // call from internal
 func Callback(namespace, code string, args ...interface{}) {
+	pointsMutex.RLock()
+	var callbacks []func(args ...interface{})
 	if cp, b := CallbackPoints[namespace+":"+code]; b {
-		for _, callback := range cp.Callbacks {
-			callback(args)
+		for i, callback := range cp.Callbacks {
+			if owner := cp.owners[i]; owner == nil || owner.IsEnabled() {
+				callbacks = append(callbacks, callback)
+			}
+		}
+	}
+	pointsMutex.RUnlock()
+
+	for _, callback := range callbacks {
+		callback(args...)
+	}
+}
+
+// 类型化扩展点：由框架/业务声明，插件提供实现
+type ExtensionPoint[T any] struct {
+	NameSpace  string
+	Code       string
+	extensions []T
+	owners     []*Plugin
+	mutex      sync.RWMutex
+}
+
+var extensionPoints = make(map[string]any)
+
+// 声明扩展点，同名扩展点已存在时返回已有扩展点
+func NewExtensionPoint[T any](namespace, code string) *ExtensionPoint[T] {
+	pointsMutex.Lock()
+	defer pointsMutex.Unlock()
+
+	key := namespace + ":" + code
+	if ep, b := extensionPoints[key]; b {
+		if result, ok := ep.(*ExtensionPoint[T]); ok {
+			return result
+		}
+		panic(fmt.Sprintf("extension point [%s] already declared with another type", key))
+	}
+
+	result := &ExtensionPoint[T]{NameSpace: namespace, Code: code}
+	extensionPoints[key] = result
+	return result
+}
+
+func GetExtensionPoint[T any](namespace, code string) (*ExtensionPoint[T], bool) {
+	pointsMutex.RLock()
+	defer pointsMutex.RUnlock()
+
+	if ep, b := extensionPoints[namespace+":"+code]; b {
+		result, ok := ep.(*ExtensionPoint[T])
+		return result, ok
+	}
+	return nil, false
+}
+
+// 插件向扩展点提供实现，owner 为空表示非插件提供
+func (ep *ExtensionPoint[T]) Extend(owner *Plugin, extension T) {
+	ep.mutex.Lock()
+	defer ep.mutex.Unlock()
+	ep.extensions = append(ep.extensions, extension)
+	ep.owners = append(ep.owners, owner)
+}
+
+// 返回当前生效的扩展（忽略已禁用插件提供的扩展）
+func (ep *ExtensionPoint[T]) Extensions() []T {
+	ep.mutex.RLock()
+	defer ep.mutex.RUnlock()
+
+	result := make([]T, 0, len(ep.extensions))
+	for i, extension := range ep.extensions {
+		if owner := ep.owners[i]; owner == nil || owner.IsEnabled() {
+			result = append(result, extension)
 		}
 	}
+	return result
+}
+
+// 插件向已声明的扩展点提供实现
+func Extend[T any](owner *Plugin, namespace, code string, extension T) error {
+	if ep, b := GetExtensionPoint[T](namespace, code); b {
+		ep.Extend(owner, extension)
+		return nil
+	}
+	return fmt.Errorf("extension point [%s:%s] not found", namespace, code)
 }
 
 type Engine struct {
@@ -37,23 +157,70 @@ type Engine struct {
 
 var engine = &Engine{}
 
+func GetEngine() *Engine {
+	return engine
+}
+
 func (e *Engine) InitPlugin(plugin *Plugin) error {
+	for _, key := range plugin.RequiredConfigs {
+		if !config.HasConfig(key) {
+			err := fmt.Errorf("required config [%s] not found", key)
+			plugin.setStatus(STATUS_FAILED, err)
+			return err
+		}
+	}
+
+	if err := call(plugin.Name, plugin.Init); err != nil {
+		plugin.setStatus(STATUS_FAILED, err)
+		return err
+	}
+
+	plugin.setStatus(STATUS_INITIALIZED, nil)
 	return nil
 }
 
-func (*Engine) StartPlugin(plugin *Plugin) error {
+// 在根路由上挂载插件路由组，每个插件只挂载一次；
+// gin 路由树不支持服务期间并发修改，仅在初始化阶段调用，运行时启用/禁用由守卫控制
+func (e *Engine) MountPlugin(plugin *Plugin) {
+	plugin.mutex.Lock()
+	defer plugin.mutex.Unlock()
+
+	if plugin.mounted || plugin.IPlugin == nil {
+		return
+	}
+
+	group := router.Root().Group(plugin.IPlugin.RouterPath(), plugin.guard)
+	plugin.IPlugin.Register(group)
+	plugin.mounted = true
+}
+
+func (e *Engine) StartPlugin(plugin *Plugin) error {
+	if err := call(plugin.Name, plugin.Start); err != nil {
+		plugin.setStatus(STATUS_FAILED, err)
+		return err
+	}
+
+	plugin.setStatus(STATUS_STARTED, nil)
 	return nil
 }
 
+func (e *Engine) StopPlugin(plugin *Plugin) error {
+	err := call(plugin.Name, plugin.Terminate)
+	plugin.setStatus(STATUS_TERMINATED, err)
+	return err
+}
+
 func (*Engine) RegisterCallback(namespace, code string, f func(args ...interface{})) {
-	if cp, b := CallbackPoints[namespace+":"+code]; b {
-		cp.Callbacks = append(cp.Callbacks, f)
-	}
+	registerCallback(nil, namespace, code, f)
 }
 
 // Call from plugin
-func (*Engine) CallEntryPoint(namespace, code string, args interface{}) {
-	if entry, b := EntryPoints[namespace+":"+code]; b {
-		entry.Entry(args)
+func (*Engine) CallEntryPoint(namespace, code string, args ...interface{}) {
+	pointsMutex.RLock()
+	entry, b := EntryPoints[namespace+":"+code]
+	pointsMutex.RUnlock()
+
+	if b {
+		entry.Entry(args...)
 	}
 }
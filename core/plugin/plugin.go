@@ -1,8 +1,12 @@
 package plugin
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
 	"sort"
 	"sync"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gophab/gophrame/core/logger"
@@ -15,6 +19,25 @@ const (
 	STATUS_INITIALIZED
 	STATUS_STARTED
 	STATUS_TERMINATED
+	STATUS_FAILED
+)
+
+var statusText = map[int]string{
+	STATUS:             "UNKNOWN",
+	STATUS_REGISTERED:  "REGISTERED",
+	STATUS_INITIALIZED: "INITIALIZED",
+	STATUS_STARTED:     "STARTED",
+	STATUS_TERMINATED:  "TERMINATED",
+	STATUS_FAILED:      "FAILED",
+}
+
+func StatusText(status int) string {
+	return statusText[status]
+}
+
+var (
+	ErrPluginNotFound   = errors.New("plugin not found")
+	ErrPluginTerminated = errors.New("plugin terminated")
 )
 
 // Plugin 插件接口
@@ -28,81 +51,291 @@ type IPlugin interface {
 
 type Plugin struct {
 	IPlugin
-	Name        string
-	Description string
-	UUID        string // universal identify, used by Plugin Market
-	Priority    int
-	Status      int
-	Enabled     bool
+	Name            string
+	Description     string
+	UUID            string // universal identify, used by Plugin Market
+	Priority        int
+	Status          int
+	Enabled         bool
+	RequiredConfigs []string // 插件依赖的配置项，例如 "redis" / "security.token"
+	Initializor     func(p *Plugin) error
+	Starter         func(p *Plugin) error
+	Terminater      func(p *Plugin) error
+	Error           error
+	mounted         bool
+	mutex           sync.RWMutex
 }
 
-func (*Plugin) Terminate() {}
+// 插件信息，用于管理接口
+type PluginInfo struct {
+	Name            string   `json:"name"`
+	Description     string   `json:"description,omitempty"`
+	UUID            string   `json:"uuid,omitempty"`
+	Priority        int      `json:"priority"`
+	Status          string   `json:"status"`
+	Enabled         bool     `json:"enabled"`
+	RouterPath      string   `json:"routerPath,omitempty"`
+	RequiredConfigs []string `json:"requiredConfigs,omitempty"`
+	Error           string   `json:"error,omitempty"`
+}
 
-func (*Plugin) Start() {}
+func (p *Plugin) Terminate() error {
+	if p.Terminater != nil {
+		return p.Terminater(p)
+	}
+	return nil
+}
 
-func (*Plugin) Init() {}
+func (p *Plugin) Start() error {
+	if p.Starter != nil {
+		return p.Starter(p)
+	}
+	return nil
+}
+
+func (p *Plugin) Init() error {
+	if p.Initializor != nil {
+		return p.Initializor(p)
+	}
+	return nil
+}
 
 func (*Plugin) Register() {}
 
-var plugins = make([]*Plugin, 0)
+func (p *Plugin) IsEnabled() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.Enabled
+}
+
+func (p *Plugin) GetStatus() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.Status
+}
+
+func (p *Plugin) setStatus(status int, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.Status = status
+	p.Error = err
+}
 
+func (p *Plugin) Info() *PluginInfo {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	result := &PluginInfo{
+		Name:            p.Name,
+		Description:     p.Description,
+		UUID:            p.UUID,
+		Priority:        p.Priority,
+		Status:          StatusText(p.Status),
+		Enabled:         p.Enabled,
+		RequiredConfigs: p.RequiredConfigs,
+	}
+	if p.IPlugin != nil {
+		result.RouterPath = p.IPlugin.RouterPath()
+	}
+	if p.Error != nil {
+		result.Error = p.Error.Error()
+	}
+	return result
+}
+
+// 插件路由守卫：插件禁用时其路由返回 404
+func (p *Plugin) guard(c *gin.Context) {
+	if !p.IsEnabled() {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	c.Next()
+}
+
+// 插件在本插件名下注册回调，插件禁用时回调不被执行
+func (p *Plugin) RegisterCallback(namespace, code string, f func(args ...interface{})) {
+	registerCallback(p, namespace, code, f)
+}
+
+// 执行生命周期函数，panic 视为失败
+func call(name string, f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s panic: %v", name, r)
+		}
+	}()
+	return f()
+}
+
+var (
+	plugins      = make([]*Plugin, 0)
+	pluginsMutex sync.RWMutex
+)
+
+// 插件须在初始化前注册，之后注册的插件无法挂载路由
 func RegisterPlugin(plugin *Plugin) {
+	pluginsMutex.Lock()
+	defer pluginsMutex.Unlock()
+
+	if mounted {
+		logger.Error("[PLUGIN] Plugin registered after initialization, ignored: ", plugin.Name)
+		return
+	}
+
+	for _, p := range plugins {
+		if p.Name == plugin.Name {
+			logger.Warn("[PLUGIN] Plugin already registered: ", plugin.Name)
+			return
+		}
+	}
+
 	plugins = append(plugins, plugin)
 	plugin.Register()
 	plugin.Status = STATUS_REGISTERED
 }
 
+func GetPlugin(name string) *Plugin {
+	pluginsMutex.RLock()
+	defer pluginsMutex.RUnlock()
+
+	for _, p := range plugins {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+func GetPlugins() []*PluginInfo {
+	pluginsMutex.RLock()
+	defer pluginsMutex.RUnlock()
+
+	result := make([]*PluginInfo, 0, len(plugins))
+	for _, p := range plugins {
+		result = append(result, p.Info())
+	}
+	return result
+}
+
+// 运行时启用插件，尚未初始化/启动的插件将被初始化并启动；已终止的插件不能再启用
+func EnablePlugin(name string) error {
+	plugin := GetPlugin(name)
+	if plugin == nil {
+		return ErrPluginNotFound
+	}
+
+	if plugin.GetStatus() == STATUS_TERMINATED {
+		return ErrPluginTerminated
+	}
+
+	plugin.mutex.Lock()
+	plugin.Enabled = true
+	plugin.mutex.Unlock()
+
+	if atomic.LoadInt32(&started) == 0 {
+		return nil
+	}
+
+	switch plugin.GetStatus() {
+	case STATUS_REGISTERED, STATUS_FAILED:
+		if err := engine.InitPlugin(plugin); err != nil {
+			plugin.mutex.Lock()
+			plugin.Enabled = false
+			plugin.mutex.Unlock()
+			return err
+		}
+		fallthrough
+	case STATUS_INITIALIZED:
+		if err := engine.StartPlugin(plugin); err != nil {
+			plugin.mutex.Lock()
+			plugin.Enabled = false
+			plugin.mutex.Unlock()
+			return err
+		}
+	}
+
+	logger.Info("[PLUGIN] Plugin enabled: ", name)
+	return nil
+}
+
+// 运行时禁用插件：路由返回 404，回调与扩展不再生效
+func DisablePlugin(name string) error {
+	plugin := GetPlugin(name)
+	if plugin == nil {
+		return ErrPluginNotFound
+	}
+
+	plugin.mutex.Lock()
+	plugin.Enabled = false
+	plugin.mutex.Unlock()
+
+	logger.Info("[PLUGIN] Plugin disabled: ", name)
+	return nil
+}
+
 var (
-	onceInit, onceStart sync.Once
+	onceInit, onceStart, onceTerminate sync.Once
+	started                            int32
+	mounted                            bool // 已进入路由挂载阶段，受 pluginsMutex 保护
 )
 
 func Init() {
 	onceInit.Do(func() {
-		sort.Slice(plugins, func(i, j int) bool {
+		// 此后注册的插件被忽略；生命周期函数可能调用 GetPlugin，不持锁遍历
+		pluginsMutex.Lock()
+		sort.SliceStable(plugins, func(i, j int) bool {
 			return plugins[i].Priority < plugins[j].Priority
 		})
-		for _, plugin := range plugins {
-			plugin.Init()
-			plugin.Status = STATUS_INITIALIZED
+		mounted = true
+		snapshot := append([]*Plugin(nil), plugins...)
+		pluginsMutex.Unlock()
 
+		for _, plugin := range snapshot {
 			// Plugin Engine
-			if plugin.Enabled {
+			if plugin.IsEnabled() {
 				if err := engine.InitPlugin(plugin); err != nil {
 					// 引擎初始化
 					logger.Error("[PLUGIN] Initializing plugin [", plugin.Name, "] error: ", err.Error())
 				}
 			}
+
+			// 所有插件的路由在服务启动前挂载，禁用插件的路由由守卫拦截
+			engine.MountPlugin(plugin)
 		}
 	})
 }
 
 func Start() {
 	onceStart.Do(func() {
+		pluginsMutex.RLock()
+		defer pluginsMutex.RUnlock()
+
 		for _, plugin := range plugins {
 			// Plugin Engine
-			if plugin.Enabled && plugin.Status == STATUS_INITIALIZED {
-				plugin.Start()
-				plugin.Status = STATUS_STARTED
-
+			if plugin.IsEnabled() && plugin.GetStatus() == STATUS_INITIALIZED {
 				if err := engine.StartPlugin(plugin); err != nil {
-					// 引擎初始化
+					// 引擎启动
 					logger.Error("[PLUGIN] Starting plugin [", plugin.Name, "] error: ", err.Error())
 				}
 			}
 		}
+		atomic.StoreInt32(&started, 1)
 	})
 }
 
 func Terminate() {
-	onceStart.Do(func() {
-		for _, plugin := range plugins {
+	onceTerminate.Do(func() {
+		atomic.StoreInt32(&started, 0)
+
+		pluginsMutex.RLock()
+		defer pluginsMutex.RUnlock()
+
+		for i := len(plugins) - 1; i >= 0; i-- {
+			plugin := plugins[i]
 			// Plugin Engine
-			if plugin.Enabled && plugin.Status == STATUS_STARTED {
-				plugin.Terminate()
-				plugin.Status = STATUS_TERMINATED
-				if err := engine.StartPlugin(plugin); err != nil {
-					// 引擎初始化
-					logger.Error("[PLUGIN] Starting plugin [", plugin.Name, "] error: ", err.Error())
+			if plugin.GetStatus() == STATUS_STARTED {
+				if err := engine.StopPlugin(plugin); err != nil {
+					logger.Error("[PLUGIN] Terminating plugin [", plugin.Name, "] error: ", err.Error())
 				}
 			}
 		}
@@ -0,0 +1,313 @@
+package rabbitmq
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/rabbitmq/config"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var (
+	ErrClientClosed = errors.New("rabbitmq client closed")
+)
+
+// RabbitMQ 客户端：共享连接、发布通道池、断线自动重连并重新声明拓扑
+type Client struct {
+	addr              string
+	reconnectInterval time.Duration
+	conn              *amqp.Connection
+	channels          chan *publishChannel
+	buffer            chan *pendingPublish
+	topologies        []*Topology
+	listeners         []func(conn *amqp.Connection)
+	closed            bool
+	reconnecting      int32
+	mutex             sync.RWMutex
+	flushMutex        sync.Mutex
+}
+
+func NewClient(addr string) *Client {
+	poolSize := config.Setting.ChannelPoolSize
+	if poolSize <= 0 {
+		poolSize = 1
+	}
+
+	reconnectInterval := config.Setting.ReconnectInterval
+	if reconnectInterval <= 0 {
+		reconnectInterval = time.Second * 5
+	}
+
+	bufferSize := config.Setting.PublishBufferSize
+	if bufferSize < 0 {
+		bufferSize = 0
+	}
+
+	result := &Client{
+		addr:              addr,
+		reconnectInterval: reconnectInterval,
+		channels:          make(chan *publishChannel, poolSize),
+		buffer:            make(chan *pendingPublish, bufferSize),
+	}
+	result.OnConnected(func(conn *amqp.Connection) {
+		go result.flush()
+	})
+	return result
+}
+
+var (
+	defaultClient *Client
+	defaultOnce   sync.Once
+)
+
+// 默认客户端，使用 rabbitmq 配置并声明配置中的拓扑
+func Default() *Client {
+	defaultOnce.Do(func() {
+		defaultClient = NewClient(config.Setting.Addr)
+		if err := defaultClient.Declare(ConfigTopology()); err != nil {
+			logger.Error("[RABBITMQ] Declare topology error: ", err.Error())
+		}
+	})
+	return defaultClient
+}
+
+// 获取连接，连接不可用时重新建立
+func (c *Client) Connection() (*amqp.Connection, error) {
+	c.mutex.RLock()
+	conn, closed := c.conn, c.closed
+	c.mutex.RUnlock()
+
+	if closed {
+		return nil, ErrClientClosed
+	}
+	if conn != nil && !conn.IsClosed() {
+		return conn, nil
+	}
+	return c.connect()
+}
+
+func (c *Client) connect() (*amqp.Connection, error) {
+	c.mutex.Lock()
+	if c.closed {
+		c.mutex.Unlock()
+		return nil, ErrClientClosed
+	}
+	if c.conn != nil && !c.conn.IsClosed() {
+		conn := c.conn
+		c.mutex.Unlock()
+		return conn, nil
+	}
+
+	conn, err := amqp.Dial(c.addr)
+	if err != nil {
+		c.mutex.Unlock()
+		logger.Error("[RABBITMQ] Connect error: ", err.Error())
+		return nil, err
+	}
+	c.conn = conn
+	c.drainChannels()
+	notifyClose := conn.NotifyClose(make(chan *amqp.Error, 1))
+
+	topologies := append([]*Topology{}, c.topologies...)
+	listeners := append([]func(conn *amqp.Connection){}, c.listeners...)
+	c.mutex.Unlock()
+
+	logger.Info("[RABBITMQ] Connected: ", c.addr)
+
+	// 重新声明拓扑
+	for _, topology := range topologies {
+		if err := c.declare(conn, topology); err != nil {
+			logger.Error("[RABBITMQ] Declare topology error: ", err.Error())
+		}
+	}
+
+	go c.watch(notifyClose)
+
+	for _, listener := range listeners {
+		listener(conn)
+	}
+
+	return conn, nil
+}
+
+// 监听连接断开，断开后按间隔自动重连
+func (c *Client) watch(notifyClose chan *amqp.Error) {
+	err, ok := <-notifyClose
+	if !ok || err == nil {
+		// 主动关闭
+		return
+	}
+
+	logger.Warn("[RABBITMQ] Connection lost: ", err.Error())
+	c.reconnect()
+}
+
+// 按间隔重连直到成功，同一时刻只有一个重连过程
+func (c *Client) reconnect() {
+	if !atomic.CompareAndSwapInt32(&c.reconnecting, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&c.reconnecting, 0)
+
+	for {
+		time.Sleep(c.reconnectInterval)
+		if _, err := c.connect(); err == ErrClientClosed {
+			return
+		} else if err == nil {
+			// 连接已由其他路径恢复时不会触发 OnConnected，主动发送缓冲
+			c.flush()
+			return
+		}
+	}
+}
+
+// 连接建立（含重连）后回调，用于消费者重新订阅等
+func (c *Client) OnConnected(listener func(conn *amqp.Connection)) {
+	c.mutex.Lock()
+	c.listeners = append(c.listeners, listener)
+	c.mutex.Unlock()
+}
+
+// 创建新通道，由调用方关闭
+func (c *Client) Channel() (*amqp.Channel, error) {
+	conn, err := c.Connection()
+	if err != nil {
+		return nil, err
+	}
+	return conn.Channel()
+}
+
+// 发布通道：确认模式下同一时刻只被一个发布者占用，便于关联确认与退回
+type publishChannel struct {
+	*amqp.Channel
+	closes  chan *amqp.Error
+	returns chan amqp.Return
+	confirm bool
+}
+
+func (c *Client) acquire() (*publishChannel, error) {
+	for {
+		select {
+		case ch := <-c.channels:
+			if !ch.IsClosed() {
+				return ch, nil
+			}
+		default:
+			ch, err := c.Channel()
+			if err != nil {
+				return nil, err
+			}
+
+			result := &publishChannel{Channel: ch, closes: ch.NotifyClose(make(chan *amqp.Error, 1))}
+			if config.Setting.Confirm {
+				if err := ch.Confirm(false); err != nil {
+					_ = ch.Close()
+					return nil, err
+				}
+				// 每次只有一条消息在途，退回在确认之后读取
+				result.returns = ch.NotifyReturn(make(chan amqp.Return, 1))
+				result.confirm = true
+			}
+			return result, nil
+		}
+	}
+}
+
+// 区分连接与通道故障：连接断开时返回 *connectionError 以便缓冲重发；
+// 连接正常而通道被服务端关闭（如交换机不存在）时返回通道关闭原因
+func (c *Client) failure(ch *publishChannel, err error) error {
+	c.mutex.RLock()
+	conn := c.conn
+	c.mutex.RUnlock()
+
+	if conn == nil || conn.IsClosed() {
+		return &connectionError{err}
+	}
+	if ch == nil {
+		return err
+	}
+
+	select {
+	case e, ok := <-ch.closes:
+		if ok && e != nil {
+			return e
+		}
+	default:
+	}
+	return err
+}
+
+func (c *Client) release(ch *publishChannel) {
+	if ch == nil || ch.IsClosed() {
+		return
+	}
+
+	// 丢弃未被读取的退回，避免阻塞连接
+	for ch.returns != nil {
+		select {
+		case <-ch.returns:
+			continue
+		default:
+		}
+		break
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if !c.closed {
+		select {
+		case c.channels <- ch:
+			return
+		default:
+		}
+	}
+	_ = ch.Close()
+}
+
+// 在池化通道上执行操作，出错时丢弃通道
+func (c *Client) WithChannel(f func(ch *amqp.Channel) error) error {
+	ch, err := c.acquire()
+	if err != nil {
+		return err
+	}
+
+	if err = f(ch.Channel); err != nil {
+		_ = ch.Close()
+		return err
+	}
+
+	c.release(ch)
+	return nil
+}
+
+func (c *Client) drainChannels() {
+	for {
+		select {
+		case ch := <-c.channels:
+			_ = ch.Close()
+		default:
+			return
+		}
+	}
+}
+
+func (c *Client) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	c.drainChannels()
+
+	if c.conn != nil && !c.conn.IsClosed() {
+		return c.conn.Close()
+	}
+	return nil
+}
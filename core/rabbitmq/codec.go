@@ -0,0 +1,116 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// 消息编解码器，按 ContentType 注册
+type Codec interface {
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+const (
+	CONTENT_TYPE_JSON     = "application/json"
+	CONTENT_TYPE_PROTOBUF = "application/x-protobuf"
+	CONTENT_TYPE_TEXT     = "text/plain"
+)
+
+type JsonCodec struct{}
+
+func (JsonCodec) ContentType() string {
+	return CONTENT_TYPE_JSON
+}
+
+func (JsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type ProtobufCodec struct{}
+
+func (ProtobufCodec) ContentType() string {
+	return CONTENT_TYPE_PROTOBUF
+}
+
+func (ProtobufCodec) Marshal(v interface{}) ([]byte, error) {
+	if m, ok := v.(proto.Message); ok {
+		return proto.Marshal(m)
+	}
+	return nil, fmt.Errorf("protobuf codec: %T is not a proto.Message", v)
+}
+
+func (ProtobufCodec) Unmarshal(data []byte, v interface{}) error {
+	if m, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, m)
+	}
+	return fmt.Errorf("protobuf codec: %T is not a proto.Message", v)
+}
+
+// 文本/二进制原样传输
+type TextCodec struct{}
+
+func (TextCodec) ContentType() string {
+	return CONTENT_TYPE_TEXT
+}
+
+func (TextCodec) Marshal(v interface{}) ([]byte, error) {
+	switch value := v.(type) {
+	case []byte:
+		return value, nil
+	case string:
+		return []byte(value), nil
+	case fmt.Stringer:
+		return []byte(value.String()), nil
+	}
+	return nil, fmt.Errorf("text codec: unsupported type %T", v)
+}
+
+func (TextCodec) Unmarshal(data []byte, v interface{}) error {
+	switch value := v.(type) {
+	case *[]byte:
+		*value = append((*value)[:0], data...)
+		return nil
+	case *string:
+		*value = string(data)
+		return nil
+	}
+	return fmt.Errorf("text codec: unsupported type %T", v)
+}
+
+var (
+	codecs      = make(map[string]Codec)
+	codecsMutex sync.RWMutex
+
+	ErrCodecNotFound = errors.New("codec not found")
+)
+
+func RegisterCodec(codec Codec) {
+	codecsMutex.Lock()
+	defer codecsMutex.Unlock()
+	codecs[codec.ContentType()] = codec
+}
+
+func GetCodec(contentType string) (Codec, error) {
+	codecsMutex.RLock()
+	defer codecsMutex.RUnlock()
+	if codec, b := codecs[contentType]; b {
+		return codec, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrCodecNotFound, contentType)
+}
+
+func init() {
+	RegisterCodec(JsonCodec{})
+	RegisterCodec(ProtobufCodec{})
+	RegisterCodec(TextCodec{})
+}
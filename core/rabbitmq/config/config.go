@@ -16,20 +16,79 @@ type RabbitQueueSetting struct {
 	ExchangeType             string        `json:"exchangeType" yaml:"exchangeType"`
 	ExchangeName             string        `json:"exchangeName" yaml:"exchangeName"`
 	DelayedExchangeName      string        `json:"delayedExchangeName" yaml:"delayedExchangeName"`
+	Prefetch                 int           `json:"prefetch"` // 每个消费通道未确认消息上限
+	Retry                    RetrySetting  `json:"retry"`
+}
+
+// 消费失败重试，超过最大次数后进入死信队列
+type RetrySetting struct {
+	MaxAttempts     int           `json:"maxAttempts" yaml:"maxAttempts"`         // 最大处理次数（含首次），<=1 不重试
+	InitialDelay    time.Duration `json:"initialDelay" yaml:"initialDelay"`       // 首次重试延迟
+	MaxDelay        time.Duration `json:"maxDelay" yaml:"maxDelay"`               // 重试延迟上限
+	Multiplier      float64       `json:"multiplier"`                             // 延迟增长倍数
+	DeadLetterQueue string        `json:"deadLetterQueue" yaml:"deadLetterQueue"` // 死信队列，默认 <queue>.dlq
+}
+
+// 交换机声明
+type ExchangeSetting struct {
+	Name       string                 `json:"name"`
+	Type       string                 `json:"type"` // direct / fanout / topic / headers / x-delayed-message
+	Durable    bool                   `json:"durable"`
+	AutoDelete bool                   `json:"autoDelete" yaml:"autoDelete"`
+	Internal   bool                   `json:"internal"`
+	Args       map[string]interface{} `json:"args"`
+}
+
+// 队列声明
+type QueueSetting struct {
+	Name       string                 `json:"name"`
+	Durable    bool                   `json:"durable"`
+	AutoDelete bool                   `json:"autoDelete" yaml:"autoDelete"`
+	Exclusive  bool                   `json:"exclusive"`
+	Args       map[string]interface{} `json:"args"`
+}
+
+// 绑定声明
+type BindingSetting struct {
+	Exchange   string                 `json:"exchange"`
+	Queue      string                 `json:"queue"`
+	RoutingKey string                 `json:"routingKey" yaml:"routingKey"`
+	Args       map[string]interface{} `json:"args"`
 }
 
 type RabbitMQSetting struct {
-	Enabled          bool               `json:"enabled"`
-	Addr             string             `json:"addr"`
-	HelloWorld       RabbitQueueSetting `json:"helloWorld" yaml:"helloWorld"`
-	WorkQueue        RabbitQueueSetting `json:"workQueue" yaml:"workQueue"`
-	PublishSubscribe RabbitQueueSetting `json:"publishSubscribe" yaml:"publishSubscribe"`
-	Routing          RabbitQueueSetting `json:"routing" yaml:"routing"`
-	Topic            RabbitQueueSetting `json:"topic" yaml:"topic"`
+	Enabled           bool               `json:"enabled"`
+	Addr              string             `json:"addr"`
+	ChannelPoolSize   int                `json:"channelPoolSize" yaml:"channelPoolSize"`     // 发布通道池大小
+	ReconnectInterval time.Duration      `json:"reconnectInterval" yaml:"reconnectInterval"` // 断线重连间隔
+	ContentType       string             `json:"contentType" yaml:"contentType"`             // 默认编码
+	Confirm           bool               `json:"confirm"`                                    // 发布确认模式
+	ConfirmTimeout    time.Duration      `json:"confirmTimeout" yaml:"confirmTimeout"`       // 等待确认超时
+	PublishBufferSize int                `json:"publishBufferSize" yaml:"publishBufferSize"` // 断线期间待发送消息缓冲上限
+	RpcTimeout        time.Duration      `json:"rpcTimeout" yaml:"rpcTimeout"`               // RPC 调用默认超时
+	MessagingExchange string             `json:"messagingExchange" yaml:"messagingExchange"` // messaging 接口使用的 topic 交换机
+	Exchanges         []ExchangeSetting  `json:"exchanges"`
+	Queues            []QueueSetting     `json:"queues"`
+	Bindings          []BindingSetting   `json:"bindings"`
+	HelloWorld        RabbitQueueSetting `json:"helloWorld" yaml:"helloWorld"`
+	WorkQueue         RabbitQueueSetting `json:"workQueue" yaml:"workQueue"`
+	PublishSubscribe  RabbitQueueSetting `json:"publishSubscribe" yaml:"publishSubscribe"`
+	Routing           RabbitQueueSetting `json:"routing" yaml:"routing"`
+	Topic             RabbitQueueSetting `json:"topic" yaml:"topic"`
 }
 
+const DEFAULT_CONFIRM_TIMEOUT = time.Second * 5
+
 var Setting *RabbitMQSetting = &RabbitMQSetting{
-	Enabled: false,
+	Enabled:           false,
+	ChannelPoolSize:   16,
+	ReconnectInterval: time.Second * 5,
+	ContentType:       "application/json",
+	Confirm:           true,
+	ConfirmTimeout:    DEFAULT_CONFIRM_TIMEOUT,
+	PublishBufferSize: 1000,
+	RpcTimeout:        time.Second * 30,
+	MessagingExchange: "messaging",
 }
 
 func init() {
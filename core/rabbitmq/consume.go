@@ -0,0 +1,457 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/rabbitmq/config"
+	"github.com/gophab/gophrame/core/tracing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// 重试/死信相关消息头
+const (
+	HEADER_RETRY_COUNT          = "x-retry-count"
+	HEADER_EXCEPTION_MESSAGE    = "x-exception-message"
+	HEADER_EXCEPTION_TIME       = "x-exception-time"
+	HEADER_ORIGINAL_EXCHANGE    = "x-original-exchange"
+	HEADER_ORIGINAL_ROUTING_KEY = "x-original-routing-key"
+)
+
+type Delivery struct {
+	amqp.Delivery
+	ctx context.Context
+}
+
+// 处理上下文，携带消息头中传入的链路
+func (d *Delivery) Context() context.Context {
+	if d.ctx == nil {
+		return context.Background()
+	}
+	return d.ctx
+}
+
+// 按消息 ContentType 解码，未标明时使用默认编码
+func (d *Delivery) Decode(v interface{}) error {
+	contentType := d.ContentType
+	if contentType == "" {
+		contentType = config.Setting.ContentType
+	}
+
+	codec, err := GetCodec(contentType)
+	if err != nil {
+		return err
+	}
+	return codec.Unmarshal(d.Body, v)
+}
+
+// 当前为第几次处理（从 1 开始）
+func (d *Delivery) Attempt() int {
+	switch v := d.Headers[HEADER_RETRY_COUNT].(type) {
+	case int:
+		return v + 1
+	case int8:
+		return int(v) + 1
+	case int16:
+		return int(v) + 1
+	case int32:
+		return int(v) + 1
+	case int64:
+		return int(v) + 1
+	case uint8:
+		return int(v) + 1
+	case uint16:
+		return int(v) + 1
+	case uint32:
+		return int(v) + 1
+	}
+	return 1
+}
+
+// 复制消息用于重新投递
+func (d *Delivery) republishing(headers amqp.Table) *PublishOptions {
+	result := amqp.Table{}
+	for k, v := range d.Headers {
+		result[k] = v
+	}
+	for k, v := range headers {
+		result[k] = v
+	}
+	if _, b := result[HEADER_ORIGINAL_EXCHANGE]; !b {
+		result[HEADER_ORIGINAL_EXCHANGE] = d.Exchange
+		result[HEADER_ORIGINAL_ROUTING_KEY] = d.RoutingKey
+	}
+
+	return &PublishOptions{
+		Publishing: amqp.Publishing{
+			Headers:         result,
+			ContentType:     d.ContentType,
+			ContentEncoding: d.ContentEncoding,
+			DeliveryMode:    d.DeliveryMode,
+			Priority:        d.Priority,
+			CorrelationId:   d.CorrelationId,
+			ReplyTo:         d.ReplyTo,
+			MessageId:       d.MessageId,
+			Timestamp:       d.Timestamp,
+			Type:            d.Type,
+			UserId:          d.UserId,
+			AppId:           d.AppId,
+			Body:            d.Body,
+		},
+	}
+}
+
+// 消息处理函数，返回 nil 时确认消息，返回错误时按重试策略处理
+type Handler func(d *Delivery) error
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// 标记为不可重试的错误（如消息格式错误），消息直接进入死信队列
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err}
+}
+
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// 重试策略
+type RetryOptions struct {
+	config.RetrySetting
+	DelayedExchange bool // 使用 x-delayed-message 交换机实现延迟，否则使用 TTL + 死信转发的重试队列
+}
+
+// 第 n 次重试的延迟
+func (r *RetryOptions) Delay(n int) time.Duration {
+	delay := r.InitialDelay
+	if delay <= 0 {
+		delay = time.Second
+	}
+	multiplier := r.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	result := time.Duration(float64(delay) * math.Pow(multiplier, float64(n-1)))
+	if r.MaxDelay > 0 && result > r.MaxDelay {
+		result = r.MaxDelay
+	}
+	return result
+}
+
+func (r *RetryOptions) deadLetterQueue(queue string) string {
+	if r.DeadLetterQueue != "" {
+		return r.DeadLetterQueue
+	}
+	return queue + ".dlq"
+}
+
+func retryExchange(queue string) string {
+	return queue + ".retry"
+}
+
+func retryQueue(queue string, n int) string {
+	return fmt.Sprintf("%s.retry.%d", queue, n)
+}
+
+// 重试及死信队列拓扑
+//
+//	TTL 模式：<queue>.retry.<n> 队列按第 n 次延迟设置 x-message-ttl，过期后经默认交换机转回 <queue>
+//	延迟交换机模式：<queue>.retry 为 x-delayed-message 交换机，以队列名为路由键绑定 <queue>
+func (r *RetryOptions) topology(queue string) *Topology {
+	result := &Topology{
+		Queues: []config.QueueSetting{
+			{Name: r.deadLetterQueue(queue), Durable: true},
+		},
+	}
+
+	if r.DelayedExchange {
+		result.Exchanges = append(result.Exchanges, config.ExchangeSetting{
+			Name:    retryExchange(queue),
+			Type:    "x-delayed-message",
+			Durable: true,
+			Args:    map[string]interface{}{"x-delayed-type": "direct"},
+		})
+		result.Bindings = append(result.Bindings, config.BindingSetting{
+			Exchange:   retryExchange(queue),
+			Queue:      queue,
+			RoutingKey: queue,
+		})
+		return result
+	}
+
+	for n := 1; n < r.MaxAttempts; n++ {
+		result.Queues = append(result.Queues, config.QueueSetting{
+			Name:    retryQueue(queue, n),
+			Durable: true,
+			Args: map[string]interface{}{
+				"x-message-ttl":             r.Delay(n).Milliseconds(),
+				"x-dead-letter-exchange":    "",
+				"x-dead-letter-routing-key": queue,
+			},
+		})
+	}
+	return result
+}
+
+type ConsumeOptions struct {
+	Tag         string
+	Exclusive   bool
+	Concurrency int                   // 并发消费通道数
+	Prefetch    int                   // 每个通道未确认消息上限，0 不限制
+	Retry       *RetryOptions         // 失败重试策略，nil 时失败消息直接拒绝（不重新入队）
+	OnClose     func(err *amqp.Error) // 消费通道异常关闭回调（随后自动重连）
+}
+
+type ConsumeOption func(*ConsumeOptions)
+
+func WithConsumerTag(tag string) ConsumeOption {
+	return func(o *ConsumeOptions) {
+		o.Tag = tag
+	}
+}
+
+func WithExclusive(exclusive bool) ConsumeOption {
+	return func(o *ConsumeOptions) {
+		o.Exclusive = exclusive
+	}
+}
+
+func WithConcurrency(concurrency int) ConsumeOption {
+	return func(o *ConsumeOptions) {
+		o.Concurrency = concurrency
+	}
+}
+
+func WithPrefetch(prefetch int) ConsumeOption {
+	return func(o *ConsumeOptions) {
+		o.Prefetch = prefetch
+	}
+}
+
+// 失败重试，超过最大次数或 Permanent 错误进入死信队列
+func WithRetry(retry config.RetrySetting) ConsumeOption {
+	return func(o *ConsumeOptions) {
+		if o.Retry == nil {
+			o.Retry = &RetryOptions{}
+		}
+		o.Retry.RetrySetting = retry
+	}
+}
+
+// 使用延迟消息交换机实现重试延迟（需 rabbitmq_delayed_message_exchange 插件）
+func WithDelayedRetry(delayed bool) ConsumeOption {
+	return func(o *ConsumeOptions) {
+		if o.Retry == nil {
+			o.Retry = &RetryOptions{}
+		}
+		o.Retry.DelayedExchange = delayed
+	}
+}
+
+func WithOnClose(f func(err *amqp.Error)) ConsumeOption {
+	return func(o *ConsumeOptions) {
+		o.OnClose = f
+	}
+}
+
+// 队列消费者，手动确认，连接断开后自动重新订阅
+type Consumer struct {
+	client  *Client
+	queue   string
+	handler Handler
+	options *ConsumeOptions
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func (c *Client) Consume(queue string, handler Handler, options ...ConsumeOption) (*Consumer, error) {
+	opts := &ConsumeOptions{Concurrency: 1}
+	for _, option := range options {
+		option(opts)
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+
+	if _, err := c.Connection(); err != nil {
+		return nil, err
+	}
+
+	if opts.Retry != nil {
+		if err := c.Declare(opts.Retry.topology(queue)); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := &Consumer{
+		client:  c,
+		queue:   queue,
+		handler: handler,
+		options: opts,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	for i := 0; i < opts.Concurrency; i++ {
+		result.wg.Add(1)
+		go result.run()
+	}
+	return result, nil
+}
+
+func (c *Consumer) run() {
+	defer c.wg.Done()
+
+	for {
+		if err := c.consume(); err != nil {
+			logger.Warn("[RABBITMQ] Consume queue [", c.queue, "] error: ", err.Error())
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(c.client.reconnectInterval):
+		}
+	}
+}
+
+func (c *Consumer) consume() error {
+	conn, err := c.client.Connection()
+	if err != nil {
+		return err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	// 关闭通道时未确认（含预取）的消息由服务端重新入队
+	defer ch.Close()
+
+	if c.options.Prefetch > 0 {
+		if err := ch.Qos(c.options.Prefetch, 0, false); err != nil {
+			return err
+		}
+	}
+
+	notifyClose := ch.NotifyClose(make(chan *amqp.Error, 1))
+	deliveries, err := ch.Consume(c.queue, c.options.Tag, false, c.options.Exclusive, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return nil
+		case err := <-notifyClose:
+			if err != nil && c.options.OnClose != nil {
+				c.options.OnClose(err)
+			}
+			if err != nil {
+				return err
+			}
+			return nil
+		case d, ok := <-deliveries:
+			if !ok {
+				return nil
+			}
+			delivery := &Delivery{Delivery: d}
+			c.settle(delivery, c.handle(delivery))
+		}
+	}
+}
+
+func (c *Consumer) handle(d *Delivery) (err error) {
+	var span *tracing.Span
+	d.ctx, span = startConsumeSpan(c.queue, d)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("[RABBITMQ] Handle message of queue [", c.queue, "] panic: ", r)
+			err = fmt.Errorf("panic: %v", r)
+		}
+		span.RecordError(err)
+		span.End()
+		consumedMessages.WithLabelValues(c.queue, resultLabel(err)).Inc()
+	}()
+	return c.handler(d)
+}
+
+// 按处理结果确认、重试或转入死信队列
+func (c *Consumer) settle(d *Delivery, err error) {
+	if err == nil {
+		_ = d.Ack(false)
+		return
+	}
+
+	attempt := d.Attempt()
+	logger.Warn("[RABBITMQ] Handle message of queue [", c.queue, "] attempt ", attempt, " failed: ", err.Error())
+
+	retry := c.options.Retry
+	if retry == nil {
+		_ = d.Nack(false, false)
+		return
+	}
+
+	var exchange, routingKey string
+	var publishing *PublishOptions
+	if !IsPermanent(err) && attempt < retry.MaxAttempts {
+		publishing = d.republishing(amqp.Table{HEADER_RETRY_COUNT: int32(attempt)})
+		if retry.DelayedExchange {
+			exchange, routingKey = retryExchange(c.queue), c.queue
+			WithDelay(retry.Delay(attempt))(publishing)
+		} else {
+			exchange, routingKey = "", retryQueue(c.queue, attempt)
+		}
+	} else {
+		publishing = d.republishing(amqp.Table{
+			HEADER_RETRY_COUNT:       int32(attempt),
+			HEADER_EXCEPTION_MESSAGE: err.Error(),
+			HEADER_EXCEPTION_TIME:    time.Now().Format(time.RFC3339),
+		})
+		exchange, routingKey = "", retry.deadLetterQueue(c.queue)
+		logger.Error("[RABBITMQ] Message of queue [", c.queue, "] dead-lettered to [", routingKey, "]: ", err.Error())
+	}
+
+	if perr := c.client.publish(context.Background(), &pendingPublish{
+		exchange:   exchange,
+		routingKey: routingKey,
+		options:    publishing,
+	}); perr != nil {
+		// 转投失败，重新入队等待下次处理
+		logger.Error("[RABBITMQ] Republish message of queue [", c.queue, "] error: ", perr.Error())
+		_ = d.Nack(false, true)
+		return
+	}
+	_ = d.Ack(false)
+}
+
+// 停止消费：不再接收新消息，等待处理中的消息完成并确认，预取未处理的消息退回队列
+func (c *Consumer) Stop() {
+	c.cancel()
+	c.wg.Wait()
+}
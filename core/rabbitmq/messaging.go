@@ -0,0 +1,157 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gophab/gophrame/core/messaging"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// 消息键在 RabbitMQ 中以消息头传递
+const HEADER_MESSAGE_KEY = "x-message-key"
+
+// messaging.Broker 的 RabbitMQ 实现：消息发布到 topic 交换机，路由键为 topic；
+// 每个 group 对应一个持久队列 <topic>.<group>，匿名订阅使用自动删除的独占队列
+type Broker struct {
+	client        *Client
+	exchange      string
+	once          sync.Once
+	err           error
+	subscriptions map[*subscription]struct{}
+	closed        bool
+	mutex         sync.Mutex
+}
+
+// client 可为共享客户端，Broker 关闭时不关闭 client
+func NewBroker(client *Client, exchange string) *Broker {
+	return &Broker{client: client, exchange: exchange, subscriptions: make(map[*subscription]struct{})}
+}
+
+func (b *Broker) isClosed() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.closed
+}
+
+func (b *Broker) declareExchange() error {
+	b.once.Do(func() {
+		b.err = b.client.Declare(ExchangeTopology(b.exchange, "topic", true, nil))
+	})
+	return b.err
+}
+
+func (b *Broker) Publish(ctx context.Context, topic string, msg *messaging.Message) error {
+	if b.isClosed() {
+		return messaging.ErrBrokerClosed
+	}
+	if err := b.declareExchange(); err != nil {
+		return err
+	}
+
+	headers := amqp.Table{}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	if msg.Key != "" {
+		headers[HEADER_MESSAGE_KEY] = msg.Key
+	}
+
+	options := []PublishOption{WithHeaders(headers), WithContentType(CONTENT_TYPE_TEXT)}
+	if !msg.Timestamp.IsZero() {
+		options = append(options, func(o *PublishOptions) {
+			o.Publishing.Timestamp = msg.Timestamp
+		})
+	}
+	return b.client.Publish(ctx, b.exchange, topic, msg.Body, options...)
+}
+
+func (b *Broker) Subscribe(topic, group string, handler messaging.Handler) (messaging.Subscription, error) {
+	if b.isClosed() {
+		return nil, messaging.ErrBrokerClosed
+	}
+	if err := b.declareExchange(); err != nil {
+		return nil, err
+	}
+
+	durable := group != ""
+	if !durable {
+		group = uuid.NewString()
+	}
+	queue := topic + "." + group
+
+	topology := BindingTopology(b.exchange, "topic", true, nil, queue, topic)
+	topology.Exchanges = nil
+	topology.Queues[0].Durable = durable
+	topology.Queues[0].AutoDelete = !durable
+	if err := b.client.Declare(topology); err != nil {
+		return nil, err
+	}
+
+	consumer, err := b.client.Consume(queue, func(d *Delivery) error {
+		msg := &messaging.Message{
+			Topic:     d.RoutingKey,
+			Headers:   make(map[string]string, len(d.Headers)),
+			Body:      d.Body,
+			Timestamp: d.Timestamp,
+		}
+		for k, v := range d.Headers {
+			if k == HEADER_MESSAGE_KEY {
+				msg.Key = fmt.Sprint(v)
+				continue
+			}
+			msg.Headers[k] = fmt.Sprint(v)
+		}
+		return handler(d.Context(), msg)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &subscription{broker: b, consumer: consumer}
+	b.mutex.Lock()
+	if b.closed {
+		b.mutex.Unlock()
+		consumer.Stop()
+		return nil, messaging.ErrBrokerClosed
+	}
+	b.subscriptions[result] = struct{}{}
+	b.mutex.Unlock()
+	return result, nil
+}
+
+// 仅停止本 Broker 的订阅，client 由创建方关闭
+func (b *Broker) Close() error {
+	b.mutex.Lock()
+	b.closed = true
+	subscriptions := make([]*subscription, 0, len(b.subscriptions))
+	for s := range b.subscriptions {
+		subscriptions = append(subscriptions, s)
+	}
+	b.mutex.Unlock()
+
+	for _, s := range subscriptions {
+		_ = s.Close()
+	}
+	return nil
+}
+
+type subscription struct {
+	broker    *Broker
+	consumer  *Consumer
+	closeOnce sync.Once
+}
+
+func (s *subscription) Close() error {
+	s.closeOnce.Do(func() {
+		s.consumer.Stop()
+
+		s.broker.mutex.Lock()
+		delete(s.broker.subscriptions, s)
+		s.broker.mutex.Unlock()
+	})
+	return nil
+}
@@ -0,0 +1,23 @@
+package rabbitmq
+
+import (
+	"github.com/gophab/gophrame/core/metrics"
+)
+
+var (
+	publishedMessages = metrics.NewCounterVec("rabbitmq_messages_published_total",
+		"RabbitMQ messages published by exchange, routing key and result", "exchange", "routing_key", "result")
+	consumedMessages = metrics.NewCounterVec("rabbitmq_messages_consumed_total",
+		"RabbitMQ messages handled by queue and result", "queue", "result")
+)
+
+func init() {
+	metrics.MustRegister(publishedMessages, consumedMessages)
+}
+
+func resultLabel(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
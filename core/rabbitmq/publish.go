@@ -0,0 +1,337 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/rabbitmq/config"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type PublishOptions struct {
+	Publishing  amqp.Publishing
+	ContentType string
+	Mandatory   bool
+}
+
+type PublishOption func(*PublishOptions)
+
+// 指定编码，未指定时 []byte/string 按 text/plain 原样发送，其他类型使用配置的默认编码
+func WithContentType(contentType string) PublishOption {
+	return func(o *PublishOptions) {
+		o.ContentType = contentType
+	}
+}
+
+// 无法路由时由服务端退回，Publish 返回 *ReturnError（需开启确认模式）
+func WithMandatory(mandatory bool) PublishOption {
+	return func(o *PublishOptions) {
+		o.Mandatory = mandatory
+	}
+}
+
+func WithHeaders(headers amqp.Table) PublishOption {
+	return func(o *PublishOptions) {
+		for k, v := range headers {
+			WithHeader(k, v)(o)
+		}
+	}
+}
+
+func WithHeader(key string, value interface{}) PublishOption {
+	return func(o *PublishOptions) {
+		if o.Publishing.Headers == nil {
+			o.Publishing.Headers = amqp.Table{}
+		}
+		o.Publishing.Headers[key] = value
+	}
+}
+
+// 延迟投递，需要交换机类型为 x-delayed-message（rabbitmq_delayed_message_exchange 插件）
+func WithDelay(delay time.Duration) PublishOption {
+	return WithHeader("x-delay", delay.Milliseconds())
+}
+
+func WithPersistent(persistent bool) PublishOption {
+	return func(o *PublishOptions) {
+		if persistent {
+			o.Publishing.DeliveryMode = amqp.Persistent
+		} else {
+			o.Publishing.DeliveryMode = amqp.Transient
+		}
+	}
+}
+
+func WithMessageId(messageId string) PublishOption {
+	return func(o *PublishOptions) {
+		o.Publishing.MessageId = messageId
+	}
+}
+
+func WithCorrelationId(correlationId string) PublishOption {
+	return func(o *PublishOptions) {
+		o.Publishing.CorrelationId = correlationId
+	}
+}
+
+func WithReplyTo(replyTo string) PublishOption {
+	return func(o *PublishOptions) {
+		o.Publishing.ReplyTo = replyTo
+	}
+}
+
+// 消息过期时间
+func WithExpiration(expiration time.Duration) PublishOption {
+	return func(o *PublishOptions) {
+		o.Publishing.Expiration = strconv.FormatInt(expiration.Milliseconds(), 10)
+	}
+}
+
+func WithPriority(priority uint8) PublishOption {
+	return func(o *PublishOptions) {
+		o.Publishing.Priority = priority
+	}
+}
+
+func WithType(typ string) PublishOption {
+	return func(o *PublishOptions) {
+		o.Publishing.Type = typ
+	}
+}
+
+// 编码消息
+func Encode(msg interface{}, options ...PublishOption) (*PublishOptions, error) {
+	result := &PublishOptions{
+		Publishing: amqp.Publishing{
+			DeliveryMode: amqp.Persistent,
+			Timestamp:    time.Now(),
+		},
+	}
+	for _, option := range options {
+		option(result)
+	}
+
+	contentType := result.ContentType
+	if contentType == "" {
+		switch msg.(type) {
+		case []byte, string:
+			contentType = CONTENT_TYPE_TEXT
+		default:
+			contentType = config.Setting.ContentType
+		}
+	}
+
+	codec, err := GetCodec(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Publishing.Body, err = codec.Marshal(msg); err != nil {
+		return nil, err
+	}
+	result.Publishing.ContentType = codec.ContentType()
+	return result, nil
+}
+
+var (
+	ErrNacked            = errors.New("rabbitmq publish nacked")
+	ErrConfirmTimeout    = errors.New("rabbitmq publish confirm timeout")
+	ErrPublishBufferFull = errors.New("rabbitmq publish buffer full")
+)
+
+// 连接不可用，消息进入缓冲等待重连后发送
+type connectionError struct {
+	err error
+}
+
+func (e *connectionError) Error() string {
+	return e.err.Error()
+}
+
+func (e *connectionError) Unwrap() error {
+	return e.err
+}
+
+// mandatory 消息无法路由被退回
+type ReturnError struct {
+	Return amqp.Return
+}
+
+func (e *ReturnError) Error() string {
+	return fmt.Sprintf("rabbitmq message returned: %d %s (exchange=%s, routingKey=%s)", e.Return.ReplyCode, e.Return.ReplyText, e.Return.Exchange, e.Return.RoutingKey)
+}
+
+type pendingPublish struct {
+	exchange   string
+	routingKey string
+	options    *PublishOptions
+	result     chan error
+	cancelled  int32
+}
+
+// 发布消息到交换机，exchange 为空时使用默认交换机（routingKey 即队列名）
+//
+// 确认模式下等待服务端确认，被拒绝返回 ErrNacked，超时返回 ErrConfirmTimeout，
+// mandatory 消息无法路由返回 *ReturnError；通道被服务端关闭（如交换机不存在）返回 *amqp.Error；
+// 连接不可用时消息进入有界缓冲，重连后发送，Publish 阻塞直到发送结果返回或 ctx 结束
+func (c *Client) Publish(ctx context.Context, exchange, routingKey string, msg interface{}, options ...PublishOption) error {
+	publishing, err := Encode(msg, options...)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := startPublishSpan(ctx, exchange, routingKey, publishing)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+		publishedMessages.WithLabelValues(exchange, routingKey, resultLabel(err)).Inc()
+	}()
+
+	pending := &pendingPublish{
+		exchange:   exchange,
+		routingKey: routingKey,
+		options:    publishing,
+	}
+
+	err = c.publish(ctx, pending)
+
+	var connErr *connectionError
+	if errors.As(err, &connErr) {
+		logger.Warn("[RABBITMQ] Publish failed, buffering message: ", err.Error())
+		err = c.enqueue(ctx, pending)
+	}
+	return err
+}
+
+func (c *Client) publish(ctx context.Context, pending *pendingPublish) error {
+	ch, err := c.acquire()
+	if err != nil {
+		if err == ErrClientClosed {
+			return err
+		}
+		return c.failure(nil, err)
+	}
+
+	publishing := pending.options
+	if !ch.confirm {
+		if err := ch.PublishWithContext(ctx, pending.exchange, pending.routingKey, publishing.Mandatory, false, publishing.Publishing); err != nil {
+			_ = ch.Close()
+			return c.failure(ch, err)
+		}
+		c.release(ch)
+		return nil
+	}
+
+	// 清理残留的退回消息
+	select {
+	case <-ch.returns:
+	default:
+	}
+
+	confirmation, err := ch.PublishWithDeferredConfirmWithContext(ctx, pending.exchange, pending.routingKey, publishing.Mandatory, false, publishing.Publishing)
+	if err != nil {
+		_ = ch.Close()
+		return c.failure(ch, err)
+	}
+
+	timeout := config.Setting.ConfirmTimeout
+	if timeout <= 0 {
+		timeout = config.DEFAULT_CONFIRM_TIMEOUT
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	acked, err := confirmation.WaitContext(waitCtx)
+	if err != nil {
+		// 确认状态未知，丢弃通道
+		_ = ch.Close()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return ErrConfirmTimeout
+	}
+
+	if !acked {
+		if ch.IsClosed() {
+			// 通道关闭导致的未确认
+			return c.failure(ch, amqp.ErrClosed)
+		}
+		c.release(ch)
+		return ErrNacked
+	}
+
+	// 服务端在确认前发送 basic.return
+	select {
+	case r := <-ch.returns:
+		c.release(ch)
+		return &ReturnError{Return: r}
+	default:
+	}
+
+	c.release(ch)
+	return nil
+}
+
+func (c *Client) enqueue(ctx context.Context, pending *pendingPublish) error {
+	pending.result = make(chan error, 1)
+
+	select {
+	case c.buffer <- pending:
+	default:
+		return ErrPublishBufferFull
+	}
+
+	go c.reconnect()
+
+	select {
+	case err := <-pending.result:
+		return err
+	case <-ctx.Done():
+		atomic.StoreInt32(&pending.cancelled, 1)
+		return ctx.Err()
+	}
+}
+
+// 重连后发送缓冲中的消息
+func (c *Client) flush() {
+	c.flushMutex.Lock()
+	defer c.flushMutex.Unlock()
+
+	for {
+		var pending *pendingPublish
+		select {
+		case pending = <-c.buffer:
+		default:
+			return
+		}
+
+		if atomic.LoadInt32(&pending.cancelled) == 1 {
+			continue
+		}
+
+		err := c.publish(context.Background(), pending)
+
+		var connErr *connectionError
+		if errors.As(err, &connErr) {
+			// 再次断线，放回缓冲等待下次重连
+			select {
+			case c.buffer <- pending:
+			default:
+				pending.result <- ErrPublishBufferFull
+			}
+			return
+		}
+		pending.result <- err
+	}
+}
+
+// 使用默认客户端发布消息
+func Publish(ctx context.Context, exchange, routingKey string, msg interface{}, options ...PublishOption) error {
+	return Default().Publish(ctx, exchange, routingKey, msg, options...)
+}
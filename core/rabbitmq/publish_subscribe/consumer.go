@@ -1,41 +1,27 @@
 package publish_subscribe
 
 import (
+	"sync"
+
+	"github.com/gophab/gophrame/core/rabbitmq"
 	"github.com/gophab/gophrame/core/rabbitmq/config"
 	"github.com/gophab/gophrame/core/rabbitmq/error_record"
 
-	"time"
-
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 func CreateConsumer(options ...OptionsConsumer) (*consumer, error) {
 	// 获取配置信息
-	conn, err := amqp.Dial(config.Setting.Addr)
-	exchangeType := config.Setting.PublishSubscribe.ExchangeType
-	exchangeName := config.Setting.PublishSubscribe.ExchangeName
-	queueName := config.Setting.PublishSubscribe.QueueName
-	durable := config.Setting.PublishSubscribe.Durable
-	chanNumber := config.Setting.PublishSubscribe.ConsumerChannelNumber
-	reconnectInterval := config.Setting.PublishSubscribe.OfflineReconnectInterval
-	retryTimes := config.Setting.PublishSubscribe.RetryCount
-
-	if err != nil {
-		return nil, err
-	}
-
 	cons := &consumer{
-		connect:                     conn,
-		exchangeType:                exchangeType,
-		exchangeName:                exchangeName,
-		queueName:                   queueName,
-		durable:                     durable,
-		chanNumber:                  chanNumber,
-		connErr:                     conn.NotifyClose(make(chan *amqp.Error, 1)),
-		offLineReconnectIntervalSec: reconnectInterval,
-		retryTimes:                  retryTimes,
-		receivedMsgBlocking:         make(chan struct{}),
-		status:                      1,
+		client:       rabbitmq.Default(),
+		exchangeType: config.Setting.PublishSubscribe.ExchangeType,
+		exchangeName: config.Setting.PublishSubscribe.ExchangeName,
+		queueName:    config.Setting.PublishSubscribe.QueueName,
+		durable:      config.Setting.PublishSubscribe.Durable,
+		chanNumber:   config.Setting.PublishSubscribe.ConsumerChannelNumber,
+		prefetch:     config.Setting.PublishSubscribe.Prefetch,
+		retry:        config.Setting.PublishSubscribe.Retry,
+		done:         make(chan struct{}),
 	}
 	// rabbitmq 如果启动了延迟消息队列模式。继续初始化一些参数
 	for _, val := range options {
@@ -46,150 +32,91 @@ func CreateConsumer(options ...OptionsConsumer) (*consumer, error) {
 
 // 定义一个消息队列结构体：PublishSubscribe 模型
 type consumer struct {
-	connect                     *amqp.Connection
-	exchangeType                string
-	exchangeName                string
-	queueName                   string
-	durable                     bool
-	chanNumber                  int
-	occurError                  error
-	connErr                     chan *amqp.Error
-	callbackForReceived         func(receivedData string) //   断线重连，结构体内部使用
-	offLineReconnectIntervalSec time.Duration
-	retryTimes                  int
-	callbackOffLine             func(err *amqp.Error) //   断线重连，结构体内部使用
-	enableDelayMsgPlugin        bool                  // 是否使用延迟队列模式
-	receivedMsgBlocking         chan struct{}         // 接受消息时用于阻塞消息处理函数
-	status                      byte                  // 客户端状态：1=正常；0=异常
+	client               *rabbitmq.Client
+	exchangeType         string
+	exchangeName         string
+	queueName            string
+	durable              bool
+	chanNumber           int
+	prefetch             int
+	retry                config.RetrySetting
+	occurError           error
+	callbackOffLine      func(err *amqp.Error) // 连接异常回调
+	enableDelayMsgPlugin bool                  // 是否使用延迟队列模式
+	args                 map[string]interface{}
+	consumer             *rabbitmq.Consumer
+	done                 chan struct{}
+	closeOnce            sync.Once
+	mutex                sync.Mutex
 }
 
-// Received 接收、处理消息
-func (c *consumer) Received(callbackFunDealMsg func(receivedData string)) {
-	defer func() {
-		c.close()
-	}()
+// Received 接收、处理消息，阻塞直到 Close
+// 回调返回 nil 时确认消息，返回错误时按重试配置延迟重试，超过次数进入死信队列
+func (c *consumer) Received(callbackFunDealMsg func(receivedData string) error) {
+	topology := rabbitmq.BindingTopology(c.exchangeName, c.exchangeType, c.durable, c.args, c.queueName, "")
+	if c.occurError = error_record.ErrorDeal(c.client.Declare(topology)); c.occurError != nil {
+		return
+	}
 
-	// 将回调函数地址赋值给结构体变量，用于掉线重连使用
-	c.callbackForReceived = callbackFunDealMsg
+	cons, err := c.client.Consume(c.queueName, func(d *rabbitmq.Delivery) error {
+		if len(d.Body) == 0 {
+			return nil
+		}
+		return callbackFunDealMsg(string(d.Body))
+	}, c.consumeOptions()...)
 
-	for i := 1; i <= c.chanNumber; i++ {
-		go func(chanNo int) {
+	c.occurError = error_record.ErrorDeal(err)
+	if err != nil {
+		return
+	}
 
-			ch, err := c.connect.Channel()
-			c.occurError = error_record.ErrorDeal(err)
-			defer func() {
-				_ = ch.Close()
-			}()
+	c.mutex.Lock()
+	select {
+	case <-c.done:
+		// Close 先于订阅完成
+		c.mutex.Unlock()
+		cons.Stop()
+		return
+	default:
+	}
+	c.consumer = cons
+	c.mutex.Unlock()
 
-			// 声明exchange交换机
-			err = ch.ExchangeDeclare(
-				c.exchangeName, //exchange name
-				c.exchangeType, //exchange kind
-				c.durable,      //数据是否持久化
-				!c.durable,     //所有连接断开时，交换机是否删除
-				false,
-				false,
-				nil,
-			)
-			// 声明队列
-			queue, err := ch.QueueDeclare(
-				c.queueName,
-				c.durable,
-				true,
-				false,
-				false,
-				nil,
-			)
-			c.occurError = error_record.ErrorDeal(err)
-			if err != nil {
-				return
-			}
-			//队列绑定
-			err = ch.QueueBind(
-				queue.Name,
-				"", //routing key，  fanout 模式设置为 空 即可
-				c.exchangeName,
-				false,
-				nil,
-			)
-			c.occurError = error_record.ErrorDeal(err)
+	<-c.done
+}
 
-			msgs, err := ch.Consume(
-				queue.Name, // 队列名称
-				"",         //  消费者标记，请确保在一个消息频道唯一
-				true,       //是否自动确认，这里设置为 true，自动确认
-				false,      //是否私有队列，false标识允许多个 consumer 向该队列投递消息，true 表示独占
-				false,      //RabbitMQ不支持noLocal标志。
-				false,      // 队列如果已经在服务器声明，设置为 true ，否则设置为 false；
-				nil,
-			)
-			c.occurError = error_record.ErrorDeal(err)
-			if err == nil {
-				for {
-					select {
-					case msg := <-msgs:
-						// 消息处理
-						if c.status == 1 && len(msg.Body) > 0 {
-							callbackFunDealMsg(string(msg.Body))
-						} else if c.status == 0 {
-							return
-						}
-					}
-				}
-			} else {
-				return
-			}
-		}(i)
+func (c *consumer) consumeOptions() []rabbitmq.ConsumeOption {
+	options := []rabbitmq.ConsumeOption{
+		rabbitmq.WithConcurrency(c.chanNumber),
+		rabbitmq.WithPrefetch(c.prefetch),
+		rabbitmq.WithOnClose(c.onClose),
 	}
-	if _, isOk := <-c.receivedMsgBlocking; isOk {
-		c.status = 0
-		close(c.receivedMsgBlocking)
+	if c.retry.MaxAttempts > 0 || c.retry.DeadLetterQueue != "" {
+		options = append(options, rabbitmq.WithRetry(c.retry), rabbitmq.WithDelayedRetry(c.enableDelayMsgPlugin))
 	}
+	return options
+}
 
+func (c *consumer) onClose(err *amqp.Error) {
+	if c.callbackOffLine != nil {
+		c.callbackOffLine(err)
+	}
 }
 
-// OnConnectionError 消费者端，掉线重连失败后的错误回调
+// OnConnectionError 消费者端连接异常回调，客户端会自动重连
 func (c *consumer) OnConnectionError(callbackOfflineErr func(err *amqp.Error)) {
 	c.callbackOffLine = callbackOfflineErr
-	go func() {
-		select {
-		case err := <-c.connErr:
-			var i = 1
-			for i = 1; i <= c.retryTimes; i++ {
-				// 自动重连机制
-				time.Sleep(c.offLineReconnectIntervalSec * time.Second)
-				// 发生连接错误时,中断原来的消息监听（包括关闭连接）
-				if c.status == 1 {
-					c.receivedMsgBlocking <- struct{}{}
-				}
-				conn, err := CreateConsumer()
-				if err != nil {
-					continue
-				} else {
-					go func() {
-						c.connErr = conn.connect.NotifyClose(make(chan *amqp.Error, 1))
-						go conn.OnConnectionError(c.callbackOffLine)
-						conn.Received(c.callbackForReceived)
-					}()
-					// 新的客户端重连成功后，释放旧的回调函数 - OnConnectionError
-					if c.status == 0 {
-						return
-					}
-					break
-				}
-			}
-			if i > c.retryTimes {
-				callbackOfflineErr(err)
-				// 如果超过最大重连次数，同样需要释放回调函数 - OnConnectionError
-				if c.status == 0 {
-					return
-				}
-			}
-		}
-	}()
 }
 
-// close 关闭连接
-func (c *consumer) close() {
-	_ = c.connect.Close()
+// Close 停止消费，等待处理中的消息完成
+func (c *consumer) Close() {
+	c.closeOnce.Do(func() {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		if c.consumer != nil {
+			c.consumer.Stop()
+		}
+		close(c.done)
+	})
 }
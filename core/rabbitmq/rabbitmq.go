@@ -1,5 +1,87 @@
 package rabbitmq
 
+import (
+	"context"
+	"sync"
+
+	"github.com/gophab/gophrame/core/health"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/messaging"
+	MessagingConfig "github.com/gophab/gophrame/core/messaging/config"
+	"github.com/gophab/gophrame/core/rabbitmq/config"
+	"github.com/gophab/gophrame/core/starter"
+)
+
+var (
+	rpcServers   = make([]*RpcServer, 0)
+	started      bool
+	serversMutex sync.Mutex
+)
+
 func init() {
+	starter.RegisterInitializor(Init)
+	starter.RegisterStarter(Start)
+	starter.RegisterTerminater(Terminate)
+
+	health.RegisterChecker(&health.Checker{
+		Name: "rabbitmq",
+		Check: func(ctx context.Context) error {
+			_, err := Default().Connection()
+			return err
+		},
+		Condition: func() bool { return config.Setting.Enabled },
+		Readiness: true,
+	})
+}
+
+func Init() {
+	logger.Debug("Initializing RabbitMQ: ...", config.Setting.Enabled)
+	if config.Setting.Enabled {
+		messaging.RegisterBroker(MessagingConfig.TRANSPORT_RABBITMQ, NewBroker(Default(), config.Setting.MessagingExchange))
+	}
+}
+
+// 注册 RPC 服务，随应用启动，应用退出时先于连接关闭停止
+func RegisterRpcServer(server *RpcServer) {
+	serversMutex.Lock()
+	defer serversMutex.Unlock()
+
+	rpcServers = append(rpcServers, server)
+	if started {
+		startRpcServer(server)
+	}
+}
+
+func startRpcServer(server *RpcServer) {
+	if err := server.Start(); err != nil {
+		logger.Error("[RABBITMQ] Start RPC server [", server.queue, "] error: ", err.Error())
+	}
+}
+
+func Start() {
+	if !config.Setting.Enabled {
+		return
+	}
+
+	serversMutex.Lock()
+	defer serversMutex.Unlock()
+
+	started = true
+	for _, server := range rpcServers {
+		startRpcServer(server)
+	}
+}
+
+func Terminate() {
+	serversMutex.Lock()
+	for _, server := range rpcServers {
+		server.Stop()
+	}
+	started = false
+	serversMutex.Unlock()
 
+	if defaultClient != nil {
+		logger.Info("Closing RabbitMQ client...")
+		_ = defaultClient.Close()
+	}
 }
@@ -0,0 +1,376 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/rabbitmq/config"
+	"github.com/gophab/gophrame/core/tracing"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// 服务端处理失败时在应答消息头中标记错误码
+const HEADER_RPC_ERROR = "x-rpc-error"
+
+var (
+	ErrRpcTimeout = errors.New("rabbitmq rpc timeout")
+	ErrRpcClosed  = errors.New("rabbitmq rpc callback queue closed")
+)
+
+// RPC 结构化错误，服务端处理函数返回 *RpcError 时原样传给调用方
+type RpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RpcError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+func NewRpcError(code int, message string) *RpcError {
+	return &RpcError{Code: code, Message: message}
+}
+
+type rpcCall struct {
+	replyTo string
+	reply   chan *Delivery
+}
+
+// RPC 客户端：请求携带 reply_to/correlation_id，应答经独占回调队列返回
+type RpcClient struct {
+	client   *Client
+	exchange string
+	channel  *amqp.Channel
+	replyTo  string
+	pending  map[string]*rpcCall
+	mutex    sync.Mutex
+}
+
+func (c *Client) NewRpcClient(exchange string) *RpcClient {
+	return &RpcClient{
+		client:   c,
+		exchange: exchange,
+		pending:  make(map[string]*rpcCall),
+	}
+}
+
+// 回调队列，通道失效后重新声明
+func (r *RpcClient) callbackQueue() (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.channel != nil && !r.channel.IsClosed() {
+		return r.replyTo, nil
+	}
+
+	ch, err := r.client.Channel()
+	if err != nil {
+		return "", err
+	}
+
+	queue, err := ch.QueueDeclare("", false, true, true, false, nil)
+	if err != nil {
+		_ = ch.Close()
+		return "", err
+	}
+
+	deliveries, err := ch.Consume(queue.Name, "", true, true, false, false, nil)
+	if err != nil {
+		_ = ch.Close()
+		return "", err
+	}
+
+	r.channel, r.replyTo = ch, queue.Name
+	go r.dispatch(ch, queue.Name, deliveries)
+	return queue.Name, nil
+}
+
+func (r *RpcClient) dispatch(ch *amqp.Channel, replyTo string, deliveries <-chan amqp.Delivery) {
+	for d := range deliveries {
+		r.mutex.Lock()
+		call, b := r.pending[d.CorrelationId]
+		if b {
+			delete(r.pending, d.CorrelationId)
+		}
+		r.mutex.Unlock()
+
+		if b {
+			call.reply <- &Delivery{Delivery: d}
+		}
+	}
+
+	// 回调队列失效，等待该队列应答的调用立即失败
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.channel == ch {
+		r.channel = nil
+	}
+	for id, call := range r.pending {
+		if call.replyTo == replyTo {
+			delete(r.pending, id)
+			close(call.reply)
+		}
+	}
+}
+
+// 调用远程服务并等待应答，ctx 未设置超时时使用配置的 rpcTimeout
+//
+// 服务端返回错误时返回 *RpcError；reply 为 nil 时忽略应答内容
+func (r *RpcClient) Call(ctx context.Context, routingKey string, request interface{}, reply interface{}, options ...PublishOption) error {
+	if _, b := ctx.Deadline(); !b {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Setting.RpcTimeout)
+		defer cancel()
+	}
+
+	replyTo, err := r.callbackQueue()
+	if err != nil {
+		return err
+	}
+
+	correlationId := uuid.NewString()
+	call := &rpcCall{replyTo: replyTo, reply: make(chan *Delivery, 1)}
+
+	r.mutex.Lock()
+	r.pending[correlationId] = call
+	r.mutex.Unlock()
+
+	defer func() {
+		r.mutex.Lock()
+		delete(r.pending, correlationId)
+		r.mutex.Unlock()
+	}()
+
+	deadline, _ := ctx.Deadline()
+	timeout := time.Until(deadline)
+	if timeout <= 0 {
+		return fmt.Errorf("%w: %v", ErrRpcTimeout, context.DeadlineExceeded)
+	}
+
+	options = append(options,
+		WithReplyTo(replyTo),
+		WithCorrelationId(correlationId),
+		WithPersistent(false),
+		WithMandatory(true),
+		// 超时未被处理的请求由服务端丢弃
+		WithExpiration(timeout),
+	)
+	publishing, err := Encode(request, options...)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := startPublishSpan(ctx, r.exchange, routingKey, publishing)
+	defer span.End()
+
+	if err := r.client.publish(ctx, &pendingPublish{
+		exchange:   r.exchange,
+		routingKey: routingKey,
+		options:    publishing,
+	}); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	select {
+	case d, ok := <-call.reply:
+		if !ok {
+			return ErrRpcClosed
+		}
+		return decodeReply(d, reply)
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %v", ErrRpcTimeout, ctx.Err())
+	}
+}
+
+func decodeReply(d *Delivery, reply interface{}) error {
+	if _, b := d.Headers[HEADER_RPC_ERROR]; b {
+		result := &RpcError{}
+		if err := json.Unmarshal(d.Body, result); err != nil {
+			return &RpcError{Code: 500, Message: string(d.Body)}
+		}
+		return result
+	}
+
+	if reply == nil {
+		return nil
+	}
+	return d.Decode(reply)
+}
+
+// 关闭回调队列，等待中的调用返回 ErrRpcClosed
+func (r *RpcClient) Close() error {
+	r.mutex.Lock()
+	ch := r.channel
+	r.channel = nil
+	r.mutex.Unlock()
+
+	if ch != nil {
+		return ch.Close()
+	}
+	return nil
+}
+
+// RPC 处理函数，返回值作为应答消息体
+type RpcHandler func(ctx context.Context, d *Delivery) (interface{}, error)
+
+// RPC 服务端：按路由键分发请求到处理函数
+type RpcServer struct {
+	client   *Client
+	exchange string
+	queue    string
+	options  []ConsumeOption
+	handlers map[string]RpcHandler
+	consumer *Consumer
+	ctx      context.Context
+	cancel   context.CancelFunc
+	mutex    sync.RWMutex
+}
+
+// 创建 RPC 服务，exchange 为空时使用默认交换机（路由键即队列名），
+// 否则 exchange 需已声明，启动时按已注册的路由键绑定 queue
+func (c *Client) NewRpcServer(exchange, queue string, options ...ConsumeOption) *RpcServer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &RpcServer{
+		client:   c,
+		exchange: exchange,
+		queue:    queue,
+		options:  options,
+		handlers: make(map[string]RpcHandler),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+func (s *RpcServer) Handle(routingKey string, handler RpcHandler) *RpcServer {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.handlers[routingKey] = handler
+	return s
+}
+
+// 注册强类型处理函数，请求按消息 ContentType 解码为 Req
+func HandleRpc[Req any, Resp any](s *RpcServer, routingKey string, handler func(ctx context.Context, req Req) (Resp, error)) *RpcServer {
+	return s.Handle(routingKey, func(ctx context.Context, d *Delivery) (interface{}, error) {
+		var req Req
+		if err := d.Decode(&req); err != nil {
+			return nil, NewRpcError(400, err.Error())
+		}
+		return handler(ctx, req)
+	})
+}
+
+func (s *RpcServer) Start() error {
+	topology := &Topology{
+		Queues: []config.QueueSetting{{Name: s.queue}},
+	}
+	if s.exchange != "" {
+		s.mutex.RLock()
+		for routingKey := range s.handlers {
+			topology.Bindings = append(topology.Bindings, config.BindingSetting{
+				Exchange:   s.exchange,
+				Queue:      s.queue,
+				RoutingKey: routingKey,
+			})
+		}
+		s.mutex.RUnlock()
+	}
+
+	if err := s.client.Declare(topology); err != nil {
+		return err
+	}
+
+	consumer, err := s.client.Consume(s.queue, s.serve, s.options...)
+	if err != nil {
+		return err
+	}
+	s.consumer = consumer
+	logger.Info("[RABBITMQ] RPC server started: ", s.queue)
+	return nil
+}
+
+func (s *RpcServer) serve(d *Delivery) error {
+	routingKey := d.RoutingKey
+	if s.exchange == "" {
+		routingKey = s.queue
+	}
+
+	s.mutex.RLock()
+	handler := s.handlers[routingKey]
+	s.mutex.RUnlock()
+
+	var result interface{}
+	var err error
+	if handler == nil {
+		err = NewRpcError(404, "no rpc handler for "+routingKey)
+	} else {
+		result, err = s.call(handler, d)
+	}
+
+	if d.ReplyTo == "" {
+		if err != nil {
+			logger.Warn("[RABBITMQ] RPC [", routingKey, "] error: ", err.Error())
+		}
+		return nil
+	}
+	return s.reply(d, result, err)
+}
+
+func (s *RpcServer) call(handler RpcHandler, d *Delivery) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("[RABBITMQ] RPC handler panic: ", r)
+			err = NewRpcError(500, fmt.Sprintf("panic: %v", r))
+		}
+	}()
+	return handler(tracing.ContextWithSpan(s.ctx, tracing.SpanFromContext(d.Context())), d)
+}
+
+func (s *RpcServer) reply(d *Delivery, result interface{}, err error) error {
+	options := []PublishOption{
+		WithCorrelationId(d.CorrelationId),
+		WithPersistent(false),
+	}
+
+	if err != nil {
+		var rpcErr *RpcError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = NewRpcError(500, err.Error())
+		}
+		result = rpcErr
+		options = append(options, WithHeader(HEADER_RPC_ERROR, int32(rpcErr.Code)), WithContentType(CONTENT_TYPE_JSON))
+	} else if d.ContentType != "" && d.ContentType != CONTENT_TYPE_TEXT {
+		// 应答与请求使用相同编码
+		switch result.(type) {
+		case string, []byte:
+		default:
+			options = append(options, WithContentType(d.ContentType))
+		}
+	}
+
+	publishing, err := Encode(result, options...)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	return s.client.publish(context.Background(), &pendingPublish{
+		exchange:   "",
+		routingKey: d.ReplyTo,
+		options:    publishing,
+	})
+}
+
+// 停止接收请求，等待处理中的请求完成
+func (s *RpcServer) Stop() {
+	if s.consumer != nil {
+		s.consumer.Stop()
+	}
+	s.cancel()
+}
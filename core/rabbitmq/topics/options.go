@@ -2,8 +2,6 @@ package topics
 
 import (
 	"github.com/gophab/gophrame/core/rabbitmq/config"
-
-	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 // 等 go 泛型稳定以后，生产者和消费者初始化参数的设置，本段代码就可以继续精简
@@ -28,7 +26,7 @@ func SetProdMsgDelayParams(enableMsgDelayPlugin bool) OptionsProd {
 	return OptionFunc(func(p *producer) {
 		p.enableDelayMsgPlugin = enableMsgDelayPlugin
 		p.exchangeType = "x-delayed-message"
-		p.args = amqp.Table{
+		p.args = map[string]interface{}{
 			"x-delayed-type": "topic",
 		}
 		p.exchangeName = config.Setting.Topic.DelayedExchangeName
@@ -56,8 +54,25 @@ func SetConsMsgDelayParams(enableDelayMsgPlugin bool) OptionsConsumer {
 	return OptionsConsumerFunc(func(c *consumer) {
 		c.enableDelayMsgPlugin = enableDelayMsgPlugin
 		c.exchangeType = "x-delayed-message"
+		c.args = map[string]interface{}{
+			"x-delayed-type": "topic",
+		}
 		c.exchangeName = config.Setting.Topic.DelayedExchangeName
 		// 延迟消息队列，交换机、消息全部设置为持久
 		c.durable = true
 	})
 }
+
+// SetConsRetryParams 设置消费失败重试及死信队列参数，覆盖配置文件中的设置
+func SetConsRetryParams(retry config.RetrySetting) OptionsConsumer {
+	return OptionsConsumerFunc(func(c *consumer) {
+		c.retry = retry
+	})
+}
+
+// SetConsPrefetchParams 设置每个消费通道未确认消息上限
+func SetConsPrefetchParams(prefetch int) OptionsConsumer {
+	return OptionsConsumerFunc(func(c *consumer) {
+		c.prefetch = prefetch
+	})
+}
@@ -1,51 +1,45 @@
 package topics
 
 import (
-	"github.com/gophab/gophrame/core/logger"
+	"context"
+	"time"
+
+	"github.com/gophab/gophrame/core/rabbitmq"
 	"github.com/gophab/gophrame/core/rabbitmq/config"
 	"github.com/gophab/gophrame/core/rabbitmq/error_record"
-
-	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 // CreateProducer 创建一个生产者
 func CreateProducer(options ...OptionsProd) (*producer, error) {
 	// 获取配置信息
-	conn, err := amqp.Dial(config.Setting.Addr)
-	exchangeType := config.Setting.Topic.ExchangeType
-	exchangeName := config.Setting.Topic.ExchangeName
-	queueName := config.Setting.Topic.QueueName
-	durable := config.Setting.Topic.Durable
-
-	if err != nil {
-		logger.Error(err.Error())
-		return nil, err
-	}
-
 	prod := &producer{
-		connect:      conn,
-		exchangeType: exchangeType,
-		exchangeName: exchangeName,
-		queueName:    queueName,
-		durable:      durable,
+		client:       rabbitmq.Default(),
+		exchangeType: config.Setting.Topic.ExchangeType,
+		exchangeName: config.Setting.Topic.ExchangeName,
+		durable:      config.Setting.Topic.Durable,
 	}
 	// 加载用户设置的参数
 	for _, val := range options {
 		val.apply(prod)
 	}
+
+	// 该模式生产者只负责将消息投递到交换机即可
+	topology := rabbitmq.ExchangeTopology(prod.exchangeName, prod.exchangeType, prod.durable, prod.args)
+	if err := error_record.ErrorDeal(prod.client.Declare(topology)); err != nil {
+		return nil, err
+	}
 	return prod, nil
 }
 
 // 定义一个消息队列结构体：Topics 模型
 type producer struct {
-	connect              *amqp.Connection
+	client               *rabbitmq.Client
 	exchangeType         string
 	exchangeName         string
-	queueName            string
 	durable              bool
 	occurError           error
 	enableDelayMsgPlugin bool // 是否使用延迟队列模式
-	args                 amqp.Table
+	args                 map[string]interface{}
 }
 
 // Send 发送消息
@@ -53,57 +47,21 @@ type producer struct {
 // routeKey 路由键、
 // data 发送的数据、
 // delayMillisecond 延迟时间(毫秒)，只有启用了消息延迟插件才有效果
-func (p *producer) Send(routeKey, data string, delayMillisecond int) bool {
-
-	// 获取一个频道
-	ch, err := p.connect.Channel()
-	p.occurError = error_record.ErrorDeal(err)
-	defer func() {
-		_ = ch.Close()
-	}()
-
-	// 声明交换机，该模式生产者只负责将消息投递到交换机即可
-	err = ch.ExchangeDeclare(
-		p.exchangeName, //交换器名称
-		p.exchangeType, //topic模式
-		p.durable,      //交换机是否持久化
-		!p.durable,     //交换器是否自动删除
-		false,
-		false,
-		p.args,
-	)
-	p.occurError = error_record.ErrorDeal(err)
-
-	// 如果交换机是持久化的，那么消息也设置为持久化
-	msgPersistent := amqp.Transient
-	if p.durable {
-		msgPersistent = amqp.Persistent
-	}
-	// 投递消息
-	if err == nil {
-		err = ch.Publish(
-			p.exchangeName, // 交换机名称
-			routeKey,       // topics 模式默认为空即可
-			false,
-			false,
-			amqp.Publishing{
-				DeliveryMode: msgPersistent, //消息是否持久化，这里与保持保持一致即可
-				ContentType:  "text/plain",
-				Body:         []byte(data),
-				Headers: amqp.Table{
-					"x-delay": delayMillisecond, // 延迟时间: 毫秒
-				},
-			})
-	}
-	p.occurError = error_record.ErrorDeal(err)
-	if p.occurError != nil { //  发生错误，返回 false
-		return false
+// 返回服务端确认结果，发送失败时返回错误
+func (p *producer) Send(routeKey, data string, delayMillisecond int) error {
+	options := []rabbitmq.PublishOption{rabbitmq.WithPersistent(p.durable)}
+	if p.enableDelayMsgPlugin {
+		options = append(options, rabbitmq.WithDelay(time.Duration(delayMillisecond)*time.Millisecond))
 	} else {
-		return true
+		// 延迟交换机投递前不做路由，总会退回 mandatory 消息，仅非延迟模式开启
+		options = append(options, rabbitmq.WithMandatory(true))
 	}
+
+	err := p.client.Publish(context.Background(), p.exchangeName, routeKey, data, options...)
+	p.occurError = error_record.ErrorDeal(err)
+	return p.occurError
 }
 
-// Close 发送完毕手动关闭，这样不影响send多次发送数据
+// Close 连接由客户端共享，生产者无需关闭连接
 func (p *producer) Close() {
-	_ = p.connect.Close()
 }
@@ -0,0 +1,137 @@
+package rabbitmq
+
+import (
+	"reflect"
+
+	"github.com/gophab/gophrame/core/rabbitmq/config"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// 交换机/队列/绑定声明
+type Topology struct {
+	Exchanges []config.ExchangeSetting
+	Queues    []config.QueueSetting
+	Bindings  []config.BindingSetting
+}
+
+// 配置文件中声明的拓扑
+//
+//	rabbitmq:
+//	  exchanges:
+//	    - name: order
+//	      type: topic
+//	      durable: true
+//	  queues:
+//	    - name: order.created
+//	      durable: true
+//	  bindings:
+//	    - exchange: order
+//	      queue: order.created
+//	      routingKey: order.created.#
+func ConfigTopology() *Topology {
+	return &Topology{
+		Exchanges: config.Setting.Exchanges,
+		Queues:    config.Setting.Queues,
+		Bindings:  config.Setting.Bindings,
+	}
+}
+
+// 声明拓扑，并在重连后自动重新声明
+func (c *Client) Declare(topology *Topology) error {
+	c.mutex.Lock()
+	declared := false
+	for _, t := range c.topologies {
+		if reflect.DeepEqual(t, topology) {
+			declared = true
+			break
+		}
+	}
+	if !declared {
+		c.topologies = append(c.topologies, topology)
+	}
+	c.mutex.Unlock()
+
+	conn, err := c.Connection()
+	if err != nil {
+		return err
+	}
+	return c.declare(conn, topology)
+}
+
+func (c *Client) declare(conn *amqp.Connection, topology *Topology) error {
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	for _, exchange := range topology.Exchanges {
+		if err := ch.ExchangeDeclare(
+			exchange.Name,
+			exchange.Type,
+			exchange.Durable,
+			exchange.AutoDelete,
+			exchange.Internal,
+			false,
+			amqp.Table(exchange.Args),
+		); err != nil {
+			return err
+		}
+	}
+
+	for _, queue := range topology.Queues {
+		if _, err := ch.QueueDeclare(
+			queue.Name,
+			queue.Durable,
+			queue.AutoDelete,
+			queue.Exclusive,
+			false,
+			amqp.Table(queue.Args),
+		); err != nil {
+			return err
+		}
+	}
+
+	for _, binding := range topology.Bindings {
+		if err := ch.QueueBind(
+			binding.Queue,
+			binding.RoutingKey,
+			binding.Exchange,
+			false,
+			amqp.Table(binding.Args),
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 预设模式：声明队列
+func QueueTopology(queue string, durable bool) *Topology {
+	return &Topology{
+		Queues: []config.QueueSetting{
+			{Name: queue, Durable: durable, AutoDelete: !durable},
+		},
+	}
+}
+
+// 预设模式：声明交换机
+func ExchangeTopology(exchange, exchangeType string, durable bool, args map[string]interface{}) *Topology {
+	return &Topology{
+		Exchanges: []config.ExchangeSetting{
+			{Name: exchange, Type: exchangeType, Durable: durable, AutoDelete: !durable, Args: args},
+		},
+	}
+}
+
+// 预设模式：声明交换机、队列并绑定
+func BindingTopology(exchange, exchangeType string, durable bool, args map[string]interface{}, queue, routingKey string) *Topology {
+	result := ExchangeTopology(exchange, exchangeType, durable, args)
+	result.Queues = QueueTopology(queue, durable).Queues
+	result.Bindings = []config.BindingSetting{
+		{Exchange: exchange, Queue: queue, RoutingKey: routingKey},
+	}
+	return result
+}
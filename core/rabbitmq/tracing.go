@@ -0,0 +1,61 @@
+package rabbitmq
+
+import (
+	"context"
+
+	"github.com/gophab/gophrame/core/tracing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// 以 AMQP 消息头传递链路标识
+type tableCarrier amqp.Table
+
+func (c tableCarrier) Get(key string) string {
+	if value, ok := c[key].(string); ok {
+		return value
+	}
+	return ""
+}
+
+func (c tableCarrier) Set(key string, value string) {
+	c[key] = value
+}
+
+// 发布消息的 Span，并将链路标识写入消息头
+func startPublishSpan(ctx context.Context, exchange, routingKey string, publishing *PublishOptions) (context.Context, *tracing.Span) {
+	destination := exchange
+	if destination == "" {
+		destination = routingKey
+	}
+
+	ctx, span := tracing.Start(ctx, destination+" publish", tracing.WithKind(tracing.SPAN_KIND_PRODUCER), tracing.WithAttributes(map[string]interface{}{
+		"messaging.system":                     "rabbitmq",
+		"messaging.destination":                exchange,
+		"messaging.rabbitmq.routing_key":       routingKey,
+		"messaging.message_id":                 publishing.Publishing.MessageId,
+		"messaging.conversation_id":            publishing.Publishing.CorrelationId,
+		"messaging.message_payload_size_bytes": len(publishing.Publishing.Body),
+	}))
+
+	if span != nil {
+		if publishing.Publishing.Headers == nil {
+			publishing.Publishing.Headers = amqp.Table{}
+		}
+		tracing.Inject(ctx, tableCarrier(publishing.Publishing.Headers))
+	}
+	return ctx, span
+}
+
+// 消费消息的 Span，延续消息头中的链路
+func startConsumeSpan(queue string, d *Delivery) (context.Context, *tracing.Span) {
+	ctx := tracing.Extract(context.Background(), tableCarrier(d.Headers))
+	return tracing.Start(ctx, queue+" process", tracing.WithKind(tracing.SPAN_KIND_CONSUMER), tracing.WithAttributes(map[string]interface{}{
+		"messaging.system":               "rabbitmq",
+		"messaging.source":               queue,
+		"messaging.destination":          d.Exchange,
+		"messaging.rabbitmq.routing_key": d.RoutingKey,
+		"messaging.message_id":           d.MessageId,
+		"messaging.operation":            "process",
+	}))
+}
@@ -2,8 +2,8 @@ package redis
 
 import (
 	"encoding/json"
-	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gophab/gophrame/core/eventbus"
@@ -21,43 +21,37 @@ const (
 	ErrorsRedisGetConnFail  string = "Redis 从连接池获取一个连接失败，超过最大重试次数"
 )
 
-var redisPools map[string]*redis.Pool = make(map[string]*redis.Pool)
+// 键不存在
+var ErrNil = redis.ErrNil
+
+var (
+	redisPools = make(map[int]Pool)
+	poolsMutex sync.RWMutex
+)
 
 // 处于程序底层的包，init 初始化的代码段的执行会优先于上层代码，因此这里读取配置项不能使用全局配置项变量
-func initRedisClientPool(databaseIndex int) *redis.Pool {
-	if result := redisPools[strconv.Itoa(databaseIndex)]; result != nil {
+func initRedisClientPool(databaseIndex int) Pool {
+	poolsMutex.RLock()
+	result := redisPools[databaseIndex]
+	poolsMutex.RUnlock()
+	if result != nil {
 		return result
 	}
 
-	result := &redis.Pool{
-		MaxIdle:     config.Setting.MaxIdle,    //最大空闲数
-		MaxActive:   config.Setting.MaxActive,  //最大活跃数
-		IdleTimeout: config.Setting.IdleTimout, //最大的空闲连接等待时间，超过此时间后，空闲连接将被关闭
-		Dial: func() (redis.Conn, error) {
-			//此处对应redis ip及端口号
-			conn, err := redis.Dial("tcp", fmt.Sprintf("%s:%d", config.Setting.Host, config.Setting.Port))
-			if err != nil {
-				logger.Error(ErrorsRedisInitConnFail, err.Error())
-				return nil, err
-			}
-			auth := config.Setting.Auth //通过配置项设置redis密码
-			if len(auth) >= 1 {
-				if _, err := conn.Do("AUTH", auth); err != nil {
-					_ = conn.Close()
-					logger.Error(ErrorsRedisAuthFail, err.Error())
-				}
-			}
-			_, _ = conn.Do("select", databaseIndex)
-			return conn, err
-		},
+	poolsMutex.Lock()
+	defer poolsMutex.Unlock()
+	if result := redisPools[databaseIndex]; result != nil {
+		return result
 	}
 
+	result = newPool(databaseIndex)
+
 	// 将redis的关闭事件，注册在全局事件统一管理器，由程序退出时统一销毁
 	eventbus.RegisterEventListener(global.EventDestroyPrefix+"Redis"+strconv.Itoa(databaseIndex), func(args ...interface{}) {
 		_ = result.Close()
 	})
 
-	redisPools[strconv.Itoa(databaseIndex)] = result
+	redisPools[databaseIndex] = result
 
 	return result
 }
@@ -68,30 +62,32 @@ func GetOneRedisClient() *RedisClient {
 }
 
 func GetOneRedisClientIndex(databaseIndex int) *RedisClient {
+	pool := initRedisClientPool(databaseIndex)
+
 	maxRetryTimes := config.Setting.ConnectionFailRetryTimes
-	var oneConn redis.Conn
 	for i := 1; i <= maxRetryTimes; i++ {
-		oneConn = initRedisClientPool(databaseIndex).Get()
-		// 首先通过执行一个获取时间的命令检测连接是否有效，如果已有的连接无法执行命令，则重新尝试连接到redis服务器获取新的连接池地址
-		// 连接不可用可能会发生的场景主要有：服务端redis重启、客户端网络在有线和无线之间切换等
-		if _, replyErr := oneConn.Do("time"); replyErr != nil {
-			//fmt.Printf("连接已经失效(出错)：%+v\n", replyErr.Error())
-			// 如果已有的redis连接池获取连接出错(官方库的说法是连接不可用)，那么继续使用从新初始化连接池
-			redisPools[strconv.Itoa(databaseIndex)] = nil
-			oneConn = initRedisClientPool(databaseIndex).Get()
-		}
-		if oneConn.Err() != nil {
-			if i == maxRetryTimes {
-				logger.Error(ErrorsRedisGetConnFail, oneConn.Err())
-				return nil
+		oneConn := pool.Get()
+		// 首先通过执行一个获取时间的命令检测连接是否有效
+		// 连接不可用可能会发生的场景主要有：服务端redis重启、客户端网络在有线和无线之间切换、哨兵主从切换等
+		_, replyErr := oneConn.Do("time")
+		if replyErr == nil {
+			if config.Setting.Mode == config.MODE_SENTINEL || config.Setting.Mode == config.MODE_CLUSTER {
+				// 哨兵/集群模式下按命令借用连接
+				_ = oneConn.Close()
+				return &RedisClient{&commandConn{pool: pool}}
 			}
-			//如果出现网络短暂的抖动，短暂休眠后，支持自动重连
-			time.Sleep(config.Setting.ReConnectInterval)
-		} else {
-			break
+			return &RedisClient{oneConn}
+		}
+
+		_ = oneConn.Close()
+		if i == maxRetryTimes {
+			logger.Error(ErrorsRedisGetConnFail, replyErr)
+			return nil
 		}
+		//如果出现网络短暂的抖动，短暂休眠后，支持自动重连
+		time.Sleep(config.Setting.ReConnectInterval)
 	}
-	return &RedisClient{oneConn}
+	return nil
 }
 
 // 定义一个redis客户端结构体
@@ -149,6 +145,11 @@ func (r *RedisClient) Bytes(reply interface{}, err error) ([]byte, error) {
 	return redis.Bytes(reply, err)
 }
 
+// Values 类型转换
+func (r *RedisClient) Values(reply interface{}, err error) ([]interface{}, error) {
+	return redis.Values(reply, err)
+}
+
 type RedisResult struct {
 	key   string
 	reply interface{}
@@ -7,26 +7,57 @@ import (
 	"github.com/gophab/gophrame/core/logger"
 )
 
+const (
+	MODE_STANDALONE = "standalone"
+	MODE_SENTINEL   = "sentinel"
+	MODE_CLUSTER    = "cluster"
+)
+
+type RedisTLSSetting struct {
+	Enabled            bool   `json:"enabled"`
+	CAFile             string `json:"caFile" yaml:"caFile"`     // 自定义 CA 证书
+	CertFile           string `json:"certFile" yaml:"certFile"` // 客户端证书（双向认证）
+	KeyFile            string `json:"keyFile" yaml:"keyFile"`
+	ServerName         string `json:"serverName" yaml:"serverName"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify" yaml:"insecureSkipVerify"`
+}
+
 type RedisSetting struct {
-	Enabled                  bool          `json:"enabled"`
-	Host                     string        `json:"host"`
-	Port                     int           `json:"port"`
-	Auth                     string        `json:"auth"`
-	MaxIdle                  int           `json:"maxIdle" yaml:"maxIdle"`
-	MaxActive                int           `json:"maxActive" yaml:"maxActive"`
-	IdleTimout               time.Duration `json:"idleTimeout" yaml:"idleTimeout"`
-	Database                 int           `json:"database"`
-	ConnectionFailRetryTimes int           `json:"connectionFailRetryTimes" yaml:"connectionFailRetryTimes"`
-	ReConnectInterval        time.Duration `json:"reConnectInterval" yaml:"reConnectInterval"`
+	Enabled                  bool            `json:"enabled"`
+	Mode                     string          `json:"mode"` // standalone / sentinel / cluster
+	Host                     string          `json:"host"`
+	Port                     int             `json:"port"`
+	Addrs                    []string        `json:"addrs"`                        // sentinel 地址或 cluster 启动节点
+	MasterName               string          `json:"masterName" yaml:"masterName"` // sentinel 监控的 master 名称
+	Username                 string          `json:"username"`                     // ACL 用户名
+	Auth                     string          `json:"auth"`                         // 密码
+	SentinelUsername         string          `json:"sentinelUsername" yaml:"sentinelUsername"`
+	SentinelAuth             string          `json:"sentinelAuth" yaml:"sentinelAuth"`
+	TLS                      RedisTLSSetting `json:"tls"`
+	MaxIdle                  int             `json:"maxIdle" yaml:"maxIdle"`
+	MaxActive                int             `json:"maxActive" yaml:"maxActive"`
+	Wait                     bool            `json:"wait"` // 连接数达到 MaxActive 时等待
+	IdleTimout               time.Duration   `json:"idleTimeout" yaml:"idleTimeout"`
+	MaxConnLifetime          time.Duration   `json:"maxConnLifetime" yaml:"maxConnLifetime"`
+	DialTimeout              time.Duration   `json:"dialTimeout" yaml:"dialTimeout"`
+	ReadTimeout              time.Duration   `json:"readTimeout" yaml:"readTimeout"`
+	WriteTimeout             time.Duration   `json:"writeTimeout" yaml:"writeTimeout"`
+	HealthCheckInterval      time.Duration   `json:"healthCheckInterval" yaml:"healthCheckInterval"` // 空闲超过该时间的连接借出前检测
+	Database                 int             `json:"database"`
+	ConnectionFailRetryTimes int             `json:"connectionFailRetryTimes" yaml:"connectionFailRetryTimes"`
+	ReConnectInterval        time.Duration   `json:"reConnectInterval" yaml:"reConnectInterval"`
 }
 
 var Setting *RedisSetting = &RedisSetting{
 	Enabled:                  false,
+	Mode:                     MODE_STANDALONE,
 	Port:                     6379,
 	Database:                 1,
 	MaxIdle:                  10,
 	MaxActive:                1000,
 	IdleTimout:               time.Second * 60,
+	DialTimeout:              time.Second * 5,
+	HealthCheckInterval:      time.Second * 30,
 	ConnectionFailRetryTimes: 3,
 	ReConnectInterval:        time.Second * 5,
 }
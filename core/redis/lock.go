@@ -0,0 +1,120 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/gophab/gophrame/core/lock"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+var (
+	// 令牌匹配时才删除
+	unlockScript = redis.NewScript(1, `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+	// 令牌匹配时才续期
+	refreshScript = redis.NewScript(1, `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+)
+
+// 基于 Redis 的分布式锁: SET key token NX PX ttl
+type RedisLocker struct {
+	database int
+}
+
+func NewRedisLocker(database int) *RedisLocker {
+	return &RedisLocker{database: database}
+}
+
+// 每次操作从连接池获取独立连接，避免与看门狗协程共享连接
+func (r *RedisLocker) conn(ctx context.Context) (redis.Conn, error) {
+	return initRedisClientPool(r.database).GetContext(ctx)
+}
+
+func (r *RedisLocker) TryLock(ctx context.Context, key string, options ...lock.Option) (lock.Lock, error) {
+	return r.tryLock(ctx, key, lock.NewOptions(options...))
+}
+
+func (r *RedisLocker) Lock(ctx context.Context, key string, options ...lock.Option) (lock.Lock, error) {
+	opts := lock.NewOptions(options...)
+	return lock.Acquire(ctx, opts, func() (lock.Lock, error) {
+		return r.tryLock(ctx, key, opts)
+	})
+}
+
+func (r *RedisLocker) tryLock(ctx context.Context, key string, options *lock.Options) (lock.Lock, error) {
+	conn, err := r.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	token := lock.NewToken()
+	if _, err := redis.String(conn.Do("SET", key, token, "NX", "PX", options.TTL.Milliseconds())); err != nil {
+		if err == redis.ErrNil {
+			return nil, lock.ErrNotAcquired
+		}
+		return nil, err
+	}
+
+	result := &RedisLock{locker: r, key: key, token: token}
+	if options.Watchdog {
+		result.watchdog = lock.StartWatchdog(result, options.TTL)
+	}
+	return result, nil
+}
+
+type RedisLock struct {
+	locker   *RedisLocker
+	key      string
+	token    string
+	watchdog *lock.Watchdog
+}
+
+func (l *RedisLock) Key() string {
+	return l.key
+}
+
+func (l *RedisLock) Token() string {
+	return l.token
+}
+
+func (l *RedisLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	conn, err := l.locker.conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if res, err := redis.Int(refreshScript.Do(conn, l.key, l.token, ttl.Milliseconds())); err != nil {
+		return err
+	} else if res == 0 {
+		return lock.ErrNotHeld
+	}
+	return nil
+}
+
+func (l *RedisLock) Unlock(ctx context.Context) error {
+	l.watchdog.Stop()
+
+	conn, err := l.locker.conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if res, err := redis.Int(unlockScript.Do(conn, l.key, l.token)); err != nil {
+		return err
+	} else if res == 0 {
+		return lock.ErrNotHeld
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package redis
+
+import (
+	"sort"
+
+	"github.com/gophab/gophrame/core/metrics"
+)
+
+func init() {
+	metrics.MustRegister(metrics.NewCollector(poolMetrics))
+}
+
+// 连接池使用情况，按 地址/数据库 区分
+func poolMetrics() []*metrics.MetricFamily {
+	stats := Stats()
+
+	active := &metrics.MetricFamily{Name: "redis_pool_active_connections", Help: "Redis connections in the pool, idle and in use", Type: metrics.GAUGE}
+	idle := &metrics.MetricFamily{Name: "redis_pool_idle_connections", Help: "Idle Redis connections in the pool", Type: metrics.GAUGE}
+	waits := &metrics.MetricFamily{Name: "redis_pool_wait_total", Help: "Redis connections waited for", Type: metrics.COUNTER}
+	waitDuration := &metrics.MetricFamily{Name: "redis_pool_wait_duration_seconds_total", Help: "Time blocked waiting for a Redis connection", Type: metrics.COUNTER}
+
+	pools := make([]string, 0, len(stats.Pools))
+	for pool := range stats.Pools {
+		pools = append(pools, pool)
+	}
+	sort.Strings(pools)
+
+	for _, pool := range pools {
+		s := stats.Pools[pool]
+		labels := []metrics.LabelPair{{Name: "pool", Value: pool}}
+		active.Samples = append(active.Samples, &metrics.Sample{Labels: labels, Value: float64(s.ActiveCount)})
+		idle.Samples = append(idle.Samples, &metrics.Sample{Labels: labels, Value: float64(s.IdleCount)})
+		waits.Samples = append(waits.Samples, &metrics.Sample{Labels: labels, Value: float64(s.WaitCount)})
+		waitDuration.Samples = append(waitDuration.Samples, &metrics.Sample{Labels: labels, Value: s.WaitDuration.Seconds()})
+	}
+
+	return []*metrics.MetricFamily{
+		active,
+		idle,
+		waits,
+		waitDuration,
+		{Name: "redis_dials_total", Help: "Redis connection attempts", Type: metrics.COUNTER, Samples: []*metrics.Sample{{Value: float64(stats.Dials)}}},
+		{Name: "redis_dial_errors_total", Help: "Failed Redis connection attempts", Type: metrics.COUNTER, Samples: []*metrics.Sample{{Value: float64(stats.DialErrors)}}},
+		{Name: "redis_health_check_failures_total", Help: "Failed Redis connection health checks", Type: metrics.COUNTER, Samples: []*metrics.Sample{{Value: float64(stats.HealthCheckFailures)}}},
+	}
+}
@@ -0,0 +1,396 @@
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/redis/config"
+
+	"github.com/FZambia/sentinel"
+	"github.com/gomodule/redigo/redis"
+	"github.com/mna/redisc"
+)
+
+// 连接池：单机/哨兵模式基于 redis.Pool，集群模式基于 redisc.Cluster
+type Pool interface {
+	Get() redis.Conn
+	GetContext(ctx context.Context) (redis.Conn, error)
+	Close() error
+	Stats() map[string]redis.PoolStats
+}
+
+// 连接统计
+var (
+	dials               int64
+	dialErrors          int64
+	healthCheckFailures int64
+)
+
+type ConnStats struct {
+	Mode                string                     `json:"mode"`
+	Dials               int64                      `json:"dials"`
+	DialErrors          int64                      `json:"dialErrors"`
+	HealthCheckFailures int64                      `json:"healthCheckFailures"`
+	Pools               map[string]redis.PoolStats `json:"pools"`
+}
+
+func Stats() *ConnStats {
+	result := &ConnStats{
+		Mode:                config.Setting.Mode,
+		Dials:               atomic.LoadInt64(&dials),
+		DialErrors:          atomic.LoadInt64(&dialErrors),
+		HealthCheckFailures: atomic.LoadInt64(&healthCheckFailures),
+		Pools:               make(map[string]redis.PoolStats),
+	}
+
+	poolsMutex.RLock()
+	defer poolsMutex.RUnlock()
+	for database, pool := range redisPools {
+		for addr, stats := range pool.Stats() {
+			result.Pools[fmt.Sprintf("%s/%d", addr, database)] = stats
+		}
+	}
+	return result
+}
+
+// 检测默认数据库连接是否可用
+func Ping() error {
+	conn, err := initRedisClientPool(config.Setting.Database).GetContext(context.Background())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Do("PING")
+	return err
+}
+
+func tlsConfig() (*tls.Config, error) {
+	setting := config.Setting.TLS
+
+	result := &tls.Config{
+		ServerName:         setting.ServerName,
+		InsecureSkipVerify: setting.InsecureSkipVerify,
+	}
+
+	if setting.CAFile != "" {
+		data, err := os.ReadFile(setting.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		result.RootCAs = x509.NewCertPool()
+		if !result.RootCAs.AppendCertsFromPEM(data) {
+			return nil, errors.New("invalid redis CA file: " + setting.CAFile)
+		}
+	}
+
+	if setting.CertFile != "" && setting.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(setting.CertFile, setting.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		result.Certificates = []tls.Certificate{cert}
+	}
+
+	return result, nil
+}
+
+// TLS 配置加载失败时返回错误，不降级为明文连接
+func dialOptions(username, password string, databaseIndex int) ([]redis.DialOption, error) {
+	result := []redis.DialOption{
+		redis.DialConnectTimeout(config.Setting.DialTimeout),
+		redis.DialReadTimeout(config.Setting.ReadTimeout),
+		redis.DialWriteTimeout(config.Setting.WriteTimeout),
+	}
+
+	if username != "" {
+		result = append(result, redis.DialUsername(username))
+	}
+	if password != "" {
+		result = append(result, redis.DialPassword(password))
+	}
+	if databaseIndex > 0 {
+		result = append(result, redis.DialDatabase(databaseIndex))
+	}
+
+	if config.Setting.TLS.Enabled {
+		tlsConfig, err := tlsConfig()
+		if err != nil {
+			logger.Error("Load redis TLS config error: ", err.Error())
+			return nil, err
+		}
+		result = append(result, redis.DialUseTLS(true), redis.DialTLSConfig(tlsConfig))
+	}
+
+	return result, nil
+}
+
+func dial(addr string, options ...redis.DialOption) (redis.Conn, error) {
+	atomic.AddInt64(&dials, 1)
+	conn, err := redis.Dial("tcp", addr, options...)
+	if err != nil {
+		atomic.AddInt64(&dialErrors, 1)
+		logger.Error(ErrorsRedisInitConnFail, err.Error())
+	}
+	return conn, err
+}
+
+// 连接选项无效时拒绝建立连接
+func dialError(err error) error {
+	atomic.AddInt64(&dialErrors, 1)
+	logger.Error(ErrorsRedisInitConnFail, err.Error())
+	return err
+}
+
+func newRedisPool(dial func() (redis.Conn, error), testOnBorrow func(c redis.Conn) error) *redis.Pool {
+	return &redis.Pool{
+		MaxIdle:         config.Setting.MaxIdle,    //最大空闲数
+		MaxActive:       config.Setting.MaxActive,  //最大活跃数
+		IdleTimeout:     config.Setting.IdleTimout, //最大的空闲连接等待时间，超过此时间后，空闲连接将被关闭
+		MaxConnLifetime: config.Setting.MaxConnLifetime,
+		Wait:            config.Setting.Wait,
+		Dial:            dial,
+		TestOnBorrow: func(c redis.Conn, t time.Time) error {
+			if time.Since(t) < config.Setting.HealthCheckInterval {
+				return nil
+			}
+			err := testOnBorrow(c)
+			if err != nil {
+				atomic.AddInt64(&healthCheckFailures, 1)
+			}
+			return err
+		},
+	}
+}
+
+func ping(c redis.Conn) error {
+	_, err := c.Do("PING")
+	return err
+}
+
+type standalonePool struct {
+	*redis.Pool
+	addr string
+}
+
+func (p *standalonePool) Stats() map[string]redis.PoolStats {
+	return map[string]redis.PoolStats{p.addr: p.Pool.Stats()}
+}
+
+func newStandalonePool(databaseIndex int) Pool {
+	addr := fmt.Sprintf("%s:%d", config.Setting.Host, config.Setting.Port)
+	options, err := dialOptions(config.Setting.Username, config.Setting.Auth, databaseIndex)
+
+	return &standalonePool{
+		Pool: newRedisPool(func() (redis.Conn, error) {
+			if err != nil {
+				return nil, dialError(err)
+			}
+			return dial(addr, options...)
+		}, ping),
+		addr: addr,
+	}
+}
+
+type sentinelPool struct {
+	*redis.Pool
+	sentinel *sentinel.Sentinel
+}
+
+func (p *sentinelPool) Stats() map[string]redis.PoolStats {
+	addr, _ := p.sentinel.MasterAddr()
+	return map[string]redis.PoolStats{"sentinel:" + p.sentinel.MasterName + "@" + addr: p.Pool.Stats()}
+}
+
+func (p *sentinelPool) Close() error {
+	_ = p.sentinel.Close()
+	return p.Pool.Close()
+}
+
+func newSentinelPool(databaseIndex int) Pool {
+	sentinelOptions, sentinelErr := dialOptions(config.Setting.SentinelUsername, config.Setting.SentinelAuth, 0)
+	options, optionsErr := dialOptions(config.Setting.Username, config.Setting.Auth, databaseIndex)
+
+	s := &sentinel.Sentinel{
+		Addrs:      config.Setting.Addrs,
+		MasterName: config.Setting.MasterName,
+		Dial: func(addr string) (redis.Conn, error) {
+			if sentinelErr != nil {
+				return nil, dialError(sentinelErr)
+			}
+			return dial(addr, sentinelOptions...)
+		},
+	}
+
+	return &sentinelPool{
+		Pool: newRedisPool(func() (redis.Conn, error) {
+			if optionsErr != nil {
+				return nil, dialError(optionsErr)
+			}
+			addr, err := s.MasterAddr()
+			if err != nil {
+				atomic.AddInt64(&dialErrors, 1)
+				logger.Error(ErrorsRedisInitConnFail, err.Error())
+				return nil, err
+			}
+			return dial(addr, options...)
+		}, func(c redis.Conn) error {
+			// 主从切换后旧连接不再是 master
+			if !sentinel.TestRole(c, "master") {
+				return errors.New("redis role check failed")
+			}
+			return nil
+		}),
+		sentinel: s,
+	}
+}
+
+type clusterPool struct {
+	*redisc.Cluster
+}
+
+// 返回的连接根据键所在槽位路由，并自动跟随 MOVED/ASK 重定向
+func (p *clusterPool) Get() redis.Conn {
+	conn := p.Cluster.Get()
+	if result, err := redisc.RetryConn(conn, 3, 100*time.Millisecond); err == nil {
+		return result
+	}
+	return conn
+}
+
+func (p *clusterPool) GetContext(ctx context.Context) (redis.Conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	conn := p.Get()
+	return conn, conn.Err()
+}
+
+func newClusterPool(databaseIndex int) Pool {
+	if databaseIndex != 0 {
+		logger.Warn("Redis cluster only supports database 0, ignore database: ", databaseIndex)
+	}
+
+	options, err := dialOptions(config.Setting.Username, config.Setting.Auth, 0)
+
+	result := &redisc.Cluster{
+		StartupNodes: config.Setting.Addrs,
+		DialOptions:  options,
+		CreatePool: func(address string, options ...redis.DialOption) (*redis.Pool, error) {
+			if err != nil {
+				return nil, dialError(err)
+			}
+			return newRedisPool(func() (redis.Conn, error) {
+				return dial(address, options...)
+			}, ping), nil
+		},
+		BgError: func(src redisc.BgErrorSrc, err error) {
+			logger.Warn("Redis cluster background error: ", err.Error())
+		},
+	}
+
+	if err == nil {
+		if err := result.Refresh(); err != nil {
+			logger.Error(ErrorsRedisInitConnFail, err.Error())
+		}
+	}
+
+	return &clusterPool{Cluster: result}
+}
+
+func newPool(databaseIndex int) Pool {
+	switch config.Setting.Mode {
+	case config.MODE_SENTINEL:
+		return newSentinelPool(databaseIndex)
+	case config.MODE_CLUSTER:
+		return newClusterPool(databaseIndex)
+	default:
+		return newStandalonePool(databaseIndex)
+	}
+}
+
+// 按命令从连接池借用连接，用于哨兵/集群模式下长期持有的 RedisClient，
+// 使主从切换、槽位迁移对调用方透明；不支持 Send/Flush/Receive 管道操作
+type commandConn struct {
+	pool Pool
+}
+
+var errPipelineNotSupported = errors.New("redis pipeline not supported in sentinel/cluster mode")
+
+func (c *commandConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	if cmd == "" {
+		return nil, nil
+	}
+	conn := c.pool.Get()
+	defer conn.Close()
+	return conn.Do(cmd, args...)
+}
+
+func (c *commandConn) Send(cmd string, args ...interface{}) error {
+	return errPipelineNotSupported
+}
+
+func (c *commandConn) Flush() error {
+	return errPipelineNotSupported
+}
+
+func (c *commandConn) Receive() (interface{}, error) {
+	return nil, errPipelineNotSupported
+}
+
+func (c *commandConn) Err() error {
+	return nil
+}
+
+func (c *commandConn) Close() error {
+	return nil
+}
+
+// 遍历匹配的键，集群模式下遍历所有 master 节点
+func ScanKeys(databaseIndex int, match string, fn func(conn redis.Conn, keys []string) error) error {
+	scan := func(conn redis.Conn) error {
+		cursor := "0"
+		for {
+			values, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", match, "COUNT", 100))
+			if err != nil {
+				return err
+			}
+			if cursor, err = redis.String(values[0], nil); err != nil {
+				return err
+			}
+			keys, err := redis.Strings(values[1], nil)
+			if err != nil {
+				return err
+			}
+			if len(keys) > 0 {
+				if err := fn(conn, keys); err != nil {
+					return err
+				}
+			}
+			if cursor == "0" {
+				return nil
+			}
+		}
+	}
+
+	pool := initRedisClientPool(databaseIndex)
+	if cluster, ok := pool.(*clusterPool); ok {
+		return cluster.EachNode(false, func(addr string, conn redis.Conn) error {
+			return scan(conn)
+		})
+	}
+
+	conn, err := pool.GetContext(context.Background())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return scan(conn)
+}
@@ -0,0 +1,80 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/redis/config"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// 发布消息到频道
+func Publish(channel string, message interface{}) error {
+	conn := initRedisClientPool(config.Setting.Database).Get()
+	defer conn.Close()
+
+	_, err := conn.Do("PUBLISH", channel, message)
+	return err
+}
+
+// 订阅频道，阻塞直到 ctx 结束；连接断开时按 ReConnectInterval 自动重连
+func Subscribe(ctx context.Context, handler func(channel string, data []byte), channels ...string) {
+	args := make([]interface{}, len(channels))
+	for i, channel := range channels {
+		args[i] = channel
+	}
+
+	for {
+		if err := subscribe(ctx, handler, args...); err != nil {
+			logger.Warn("[REDIS] Subscribe ", channels, " error: ", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(config.Setting.ReConnectInterval):
+		}
+	}
+}
+
+func subscribe(ctx context.Context, handler func(channel string, data []byte), channels ...interface{}) error {
+	var conn redis.Conn
+	if pool, ok := initRedisClientPool(config.Setting.Database).(*clusterPool); ok {
+		// 集群模式下订阅连接不能使用重定向连接
+		conn = pool.Cluster.Get()
+	} else if c, err := initRedisClientPool(config.Setting.Database).GetContext(ctx); err == nil {
+		conn = c
+	} else {
+		return err
+	}
+
+	psc := redis.PubSubConn{Conn: conn}
+	defer psc.Close()
+
+	if err := psc.Subscribe(channels...); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		for {
+			switch v := psc.Receive().(type) {
+			case redis.Message:
+				handler(v.Channel, v.Data)
+			case error:
+				done <- v
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		_ = psc.Unsubscribe()
+		return nil
+	case err := <-done:
+		return err
+	}
+}
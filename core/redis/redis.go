@@ -1,13 +1,36 @@
 package redis
 
 import (
+	"context"
+	"errors"
+
+	"github.com/gophab/gophrame/core/health"
 	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/module"
 	"github.com/gophab/gophrame/core/redis/config"
 	"github.com/gophab/gophrame/core/starter"
 )
 
 func init() {
 	starter.RegisterInitializor(Init)
+
+	module.RegisterBuiltinModule("redis", "Redis", func() bool {
+		return config.Setting.Enabled
+	}, func() error {
+		client := GetOneRedisClient()
+		if client == nil {
+			return errors.New("redis not available")
+		}
+		client.ReleaseOneRedisClient()
+		return nil
+	})
+
+	health.RegisterChecker(&health.Checker{
+		Name:      "redis",
+		Check:     func(ctx context.Context) error { return Ping() },
+		Condition: func() bool { return config.Setting.Enabled },
+		Readiness: true,
+	})
 }
 
 func Init() {
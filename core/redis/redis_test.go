@@ -0,0 +1,229 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gophab/gophrame/core/lock"
+	"github.com/gophab/gophrame/core/redis/config"
+)
+
+func TestTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	invalidCA := filepath.Join(dir, "invalid-ca.pem")
+	if err := os.WriteFile(invalidCA, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		setting config.RedisTLSSetting
+		wantErr bool
+	}{
+		{name: "server name only", setting: config.RedisTLSSetting{Enabled: true, ServerName: "redis.local"}},
+		{name: "missing ca file", setting: config.RedisTLSSetting{Enabled: true, CAFile: filepath.Join(dir, "missing.pem")}, wantErr: true},
+		{name: "invalid ca file", setting: config.RedisTLSSetting{Enabled: true, CAFile: invalidCA}, wantErr: true},
+		{name: "missing key pair", setting: config.RedisTLSSetting{Enabled: true, CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "key.pem")}, wantErr: true},
+	}
+
+	saved := config.Setting.TLS
+	defer func() { config.Setting.TLS = saved }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Setting.TLS = tt.setting
+			result, err := tlsConfig()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tlsConfig error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && result.ServerName != tt.setting.ServerName {
+				t.Fatalf("ServerName = %q, want %q", result.ServerName, tt.setting.ServerName)
+			}
+		})
+	}
+}
+
+func TestPoolTLSError(t *testing.T) {
+	savedSetting := *config.Setting
+	defer func() { *config.Setting = savedSetting }()
+
+	// TLS 配置错误时连接失败，不降级为明文连接
+	config.Setting.TLS = config.RedisTLSSetting{Enabled: true, CAFile: filepath.Join(t.TempDir(), "missing.pem")}
+	for _, mode := range []string{config.MODE_STANDALONE, config.MODE_SENTINEL} {
+		config.Setting.Mode = mode
+		pool := newPool(testDatabase)
+		if conn, err := pool.GetContext(context.Background()); err == nil {
+			conn.Close()
+			t.Fatalf("%s: GetContext succeeded with invalid TLS config", mode)
+		}
+		_ = pool.Close()
+	}
+}
+
+// 集成测试使用本地启动的 redis-server，未设置 REDIS_ADDR（如 127.0.0.1:6379）时跳过
+const testDatabase = 15
+
+func setupRedis(t *testing.T) {
+	t.Helper()
+
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_ADDR not set, skip redis integration test")
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("invalid REDIS_ADDR %q: %v", addr, err)
+	}
+	portNumber, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("invalid REDIS_ADDR %q: %v", addr, err)
+	}
+
+	config.Setting.Mode = config.MODE_STANDALONE
+	config.Setting.Host = host
+	config.Setting.Port = portNumber
+	config.Setting.Username = os.Getenv("REDIS_USERNAME")
+	config.Setting.Auth = os.Getenv("REDIS_PASSWORD")
+	config.Setting.ConnectionFailRetryTimes = 1
+
+	conn, err := initRedisClientPool(testDatabase).GetContext(context.Background())
+	if err != nil {
+		t.Fatalf("connect redis %s error: %v", addr, err)
+	}
+	defer conn.Close()
+	if _, err := conn.Do("PING"); err != nil {
+		t.Fatalf("ping redis %s error: %v", addr, err)
+	}
+}
+
+func testKey(t *testing.T) string {
+	return "gophrame:test:" + t.Name() + ":" + lock.NewToken()
+}
+
+func TestRedisClient(t *testing.T) {
+	setupRedis(t)
+
+	client := GetOneRedisClientIndex(testDatabase)
+	if client == nil {
+		t.Fatal("GetOneRedisClientIndex returned nil")
+	}
+	defer client.ReleaseOneRedisClient()
+
+	key := testKey(t)
+	defer client.Execute("DEL", key)
+
+	if _, err := client.Execute("SET", key, "value", "PX", 10000); err != nil {
+		t.Fatalf("SET error: %v", err)
+	}
+	if value, err := client.String(client.Execute("GET", key)); err != nil || value != "value" {
+		t.Fatalf("GET = %q, %v; want %q", value, err, "value")
+	}
+
+	stats := Stats()
+	if stats.Dials == 0 || len(stats.Pools) == 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestRedisLocker(t *testing.T) {
+	setupRedis(t)
+
+	locker := NewRedisLocker(testDatabase)
+	ctx := context.Background()
+
+	t.Run("exclusive", func(t *testing.T) {
+		key := testKey(t)
+		l, err := locker.TryLock(ctx, key, lock.WithTTL(time.Second*10), lock.WithWatchdog(false))
+		if err != nil {
+			t.Fatalf("TryLock error: %v", err)
+		}
+		if _, err := locker.TryLock(ctx, key, lock.WithWatchdog(false)); !errors.Is(err, lock.ErrNotAcquired) {
+			t.Fatalf("second TryLock error = %v, want %v", err, lock.ErrNotAcquired)
+		}
+		if err := l.Unlock(ctx); err != nil {
+			t.Fatalf("Unlock error: %v", err)
+		}
+		if err := l.Unlock(ctx); !errors.Is(err, lock.ErrNotHeld) {
+			t.Fatalf("second Unlock error = %v, want %v", err, lock.ErrNotHeld)
+		}
+	})
+
+	t.Run("expired lock not released by old holder", func(t *testing.T) {
+		key := testKey(t)
+		l, err := locker.TryLock(ctx, key, lock.WithTTL(50*time.Millisecond), lock.WithWatchdog(false))
+		if err != nil {
+			t.Fatalf("TryLock error: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+
+		other, err := locker.TryLock(ctx, key, lock.WithTTL(time.Second*10), lock.WithWatchdog(false))
+		if err != nil {
+			t.Fatalf("TryLock after expiry error: %v", err)
+		}
+		defer other.Unlock(ctx)
+
+		if err := l.Unlock(ctx); !errors.Is(err, lock.ErrNotHeld) {
+			t.Fatalf("Unlock of expired lock error = %v, want %v", err, lock.ErrNotHeld)
+		}
+		if err := l.Refresh(ctx, time.Second); !errors.Is(err, lock.ErrNotHeld) {
+			t.Fatalf("Refresh of expired lock error = %v, want %v", err, lock.ErrNotHeld)
+		}
+		if _, err := locker.TryLock(ctx, key, lock.WithWatchdog(false)); !errors.Is(err, lock.ErrNotAcquired) {
+			t.Fatalf("lock of other holder released, TryLock error = %v", err)
+		}
+	})
+
+	t.Run("watchdog", func(t *testing.T) {
+		key := testKey(t)
+		l, err := locker.TryLock(ctx, key, lock.WithTTL(300*time.Millisecond), lock.WithWatchdog(true))
+		if err != nil {
+			t.Fatalf("TryLock error: %v", err)
+		}
+		time.Sleep(time.Second)
+
+		if _, err := locker.TryLock(ctx, key, lock.WithWatchdog(false)); !errors.Is(err, lock.ErrNotAcquired) {
+			t.Fatalf("TryLock while watchdog running error = %v, want %v", err, lock.ErrNotAcquired)
+		}
+		if err := l.Unlock(ctx); err != nil {
+			t.Fatalf("Unlock error: %v", err)
+		}
+	})
+}
+
+func TestRedisStorageUnlock(t *testing.T) {
+	setupRedis(t)
+
+	storage := NewRedisStorage(testDatabase)
+	key := testKey(t)
+
+	// 过期后被他人获取的锁，原持有者释放时不影响新持有者
+	old, ok := storage.TryLock(key, lock.WithTTL(50*time.Millisecond), lock.WithWatchdog(false))
+	if !ok {
+		t.Fatal("TryLock failed")
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	current, ok := storage.TryLock(key, lock.WithTTL(time.Second*10), lock.WithWatchdog(false))
+	if !ok {
+		t.Fatal("TryLock after expiry failed")
+	}
+	if err := storage.Unlock(old); !errors.Is(err, lock.ErrNotHeld) {
+		t.Fatalf("Unlock of expired lock error = %v, want %v", err, lock.ErrNotHeld)
+	}
+	if _, ok := storage.TryLock(key, lock.WithWatchdog(false)); ok {
+		t.Fatal("lock of current holder released")
+	}
+
+	if err := storage.Unlock(current); err != nil {
+		t.Fatalf("Unlock error: %v", err)
+	}
+	if err := storage.Unlock(nil); !errors.Is(err, lock.ErrNotHeld) {
+		t.Fatalf("Unlock nil error = %v, want %v", err, lock.ErrNotHeld)
+	}
+}
@@ -1,152 +1,52 @@
 package redis
 
 import (
+	"context"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/gophab/gophrame/core/json"
+	"github.com/gophab/gophrame/core/lock"
 
 	"github.com/gomodule/redigo/redis"
-	"github.com/timandy/routine"
 )
 
-type RedisLock struct {
-	*RedisClient
-	lockKey   string
-	expireIn  time.Duration
-	timeout   time.Duration
-	locked    bool
-	reentrant bool // 同线程可重入机制
-	counter   routine.ThreadLocal
-}
-
-func NewRedisLock(client *RedisClient, key string) *RedisLock {
-	return &RedisLock{
-		RedisClient: client,
-		lockKey:     key,
-		expireIn:    60 * time.Second,
-		timeout:     10 * time.Second,
-		locked:      false,
-		reentrant:   false,
-	}
-}
-
-func (l *RedisLock) Locked() bool {
-	return l.locked
-}
-
-func (l *RedisLock) Lock(timeout ...int) (bool, error) {
-	if len(timeout) > 0 {
-		l.timeout = time.Duration(timeout[0]) * time.Second
-	}
-
-	for t := l.timeout.Microseconds(); t >= 0; t -= 100 {
-		if l.TryLock() {
-			return true, nil
-		}
-	}
-	return false, nil
-}
-
-func (l *RedisLock) Unlock() {
-	if l.reentrant && l.counter.Get() != nil {
-		c := *l.counter.Get().(*int) - 1
-		l.counter.Set(&c)
-		if c > 0 {
-			return
-		}
-	}
-
-	if l.locked {
-		l.client.Do("DEL", l.lockKey)
-		l.locked = false
-	}
-	l.counter.Remove()
-}
-
-func (l *RedisLock) TryLock() bool {
-	if l.reentrant && l.counter.Get() != nil {
-		c := *l.counter.Get().(*int) + 1
-		l.counter.Set(&c)
-	}
-
-	expires := time.Now().Add(l.expireIn).Add(time.Second).UnixMilli()
-	if b, err := l.client.Do(`SETNX`, l.lockKey, expires); err == nil && b.(bool) {
-		l.locked = true
-		c := 1
-		l.counter.Set(&c)
-		return true
-	}
-
-	if res, err := l.Int64(l.client.Do("GET", l.lockKey)); err == nil && res <= time.Now().UnixMilli() {
-		// 已过期
-		if res, err := l.Int64(l.client.Do("GETSET", l.lockKey, expires)); err == nil && res == expires {
-			l.locked = true
-			c := 1
-			l.counter.Set(&c)
-			return true
-		}
-	}
-
-	return false
-}
-
 type RedisStorage struct {
 	*RedisClient
-	locks sync.Map
-	mutex sync.Mutex
+	locker *RedisLocker
 }
 
 func NewRedisStorage(database int) *RedisStorage {
 	return &RedisStorage{
 		RedisClient: GetOneRedisClientIndex(database),
+		locker:      NewRedisLocker(database),
 	}
 }
 
-func (s *RedisStorage) getLock(key string) *RedisLock {
-	if result, b := s.locks.Load(key); b {
-		return result.(*RedisLock)
-	} else {
-		s.mutex.Lock()
-		defer s.mutex.Unlock()
-		if result, b := s.locks.Load(key); b {
-			return result.(*RedisLock)
-		} else {
-			result := NewRedisLock(s.RedisClient, key)
-			s.locks.Store(key, result)
-			return result
-		}
-	}
-}
-
-func (s *RedisStorage) Lock(key string) {
-	s.getLock(key).Lock()
+func (s *RedisStorage) Locker() lock.Locker {
+	return s.locker
 }
 
-func (s *RedisStorage) TryLock(key string) bool {
-	lock := s.getLock(key)
-	lock.reentrant = false
-	return lock.TryLock()
+// 阻塞获取锁，持有期间由看门狗自动续期，需以返回的锁调用 Unlock 释放
+func (s *RedisStorage) Lock(key string, options ...lock.Option) (lock.Lock, error) {
+	return s.LockContext(context.Background(), key, options...)
 }
 
-func (s *RedisStorage) ReenLock(key string) {
-	lock := s.getLock(key)
-	lock.reentrant = true
-	lock.Lock()
+func (s *RedisStorage) LockContext(ctx context.Context, key string, options ...lock.Option) (lock.Lock, error) {
+	return s.locker.Lock(ctx, key, options...)
 }
 
-func (s *RedisStorage) TryReenLock(key string) bool {
-	lock := s.getLock(key)
-	lock.reentrant = true
-	return lock.TryLock()
+func (s *RedisStorage) TryLock(key string, options ...lock.Option) (lock.Lock, bool) {
+	result, err := s.locker.TryLock(context.Background(), key, options...)
+	return result, err == nil
 }
 
-func (s *RedisStorage) Unlock(key string) {
-	lock := s.getLock(key)
-	if lock != nil && lock.Locked() {
-		lock.Unlock()
+// 按令牌释放锁：锁已过期并被他人获取时返回 lock.ErrNotHeld，不会释放他人的锁
+func (s *RedisStorage) Unlock(l lock.Lock) error {
+	if l == nil {
+		return lock.ErrNotHeld
 	}
+	return l.Unlock(context.Background())
 }
 
 func (s *RedisStorage) Restore(key string) *RedisResult {
@@ -0,0 +1,136 @@
+package resilience
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/eventbus"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/resilience/config"
+)
+
+const (
+	STATE_CLOSED    = "CLOSED"
+	STATE_OPEN      = "OPEN"
+	STATE_HALF_OPEN = "HALF_OPEN"
+)
+
+// 熔断状态变化事件，参数：service, from, to
+const EVENT_CIRCUIT_STATE_CHANGED = "resilience.circuit.state"
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+func init() {
+	eventbus.RegisterEventListener(EVENT_CIRCUIT_STATE_CHANGED, func(args ...interface{}) {
+		logger.Warn("[RESILIENCE] Circuit breaker ", args[0], ": ", args[1], " -> ", args[2])
+	})
+}
+
+// 熔断器：窗口内失败率超过阈值时打开，经过 OpenDuration 后半开放行少量探测请求，
+// 探测全部成功则关闭，任一失败重新打开
+type CircuitBreaker struct {
+	name        string
+	setting     config.CircuitBreakerSetting
+	state       string
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probes      int // 半开时已放行的探测数
+	successes   int // 半开时成功的探测数
+	mutex       sync.Mutex
+}
+
+func NewCircuitBreaker(name string, setting config.CircuitBreakerSetting) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:        name,
+		setting:     setting,
+		state:       STATE_CLOSED,
+		windowStart: time.Now(),
+	}
+}
+
+func (b *CircuitBreaker) State() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.state
+}
+
+func (b *CircuitBreaker) transit(state string) {
+	from := b.state
+	if from == state {
+		return
+	}
+
+	b.state = state
+	b.windowStart = time.Now()
+	b.requests, b.failures = 0, 0
+	b.probes, b.successes = 0, 0
+	if state == STATE_OPEN {
+		b.openedAt = time.Now()
+	}
+
+	eventbus.DispatchEvent(EVENT_CIRCUIT_STATE_CHANGED, b.name, from, state)
+}
+
+// 申请调用，返回的 done 须在调用结束后反馈结果
+func (b *CircuitBreaker) Allow() (done func(success bool), err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case STATE_OPEN:
+		if time.Since(b.openedAt) < b.setting.OpenDuration {
+			return nil, ErrCircuitOpen
+		}
+		b.transit(STATE_HALF_OPEN)
+		fallthrough
+	case STATE_HALF_OPEN:
+		if b.probes >= b.setting.HalfOpenRequests {
+			return nil, ErrCircuitOpen
+		}
+		b.probes++
+	default:
+		if b.setting.Window > 0 && time.Since(b.windowStart) > b.setting.Window {
+			b.windowStart = time.Now()
+			b.requests, b.failures = 0, 0
+		}
+	}
+
+	state := b.state
+	return func(success bool) {
+		b.done(state, success)
+	}, nil
+}
+
+func (b *CircuitBreaker) done(state string, success bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state != state {
+		// 状态已变化，忽略旧状态下发出的请求
+		return
+	}
+
+	switch state {
+	case STATE_HALF_OPEN:
+		if !success {
+			b.transit(STATE_OPEN)
+			return
+		}
+		b.successes++
+		if b.successes >= b.setting.HalfOpenRequests {
+			b.transit(STATE_CLOSED)
+		}
+	case STATE_CLOSED:
+		b.requests++
+		if !success {
+			b.failures++
+		}
+		if b.requests >= b.setting.MinimumRequests &&
+			float64(b.failures)/float64(b.requests) >= b.setting.FailureRateThreshold {
+			b.transit(STATE_OPEN)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package resilience
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gophab/gophrame/core/resilience/config"
+)
+
+var testBreakerSetting = config.CircuitBreakerSetting{
+	Enabled:              true,
+	FailureRateThreshold: 0.5,
+	MinimumRequests:      4,
+	Window:               time.Minute,
+	OpenDuration:         30 * time.Millisecond,
+	HalfOpenRequests:     2,
+}
+
+// 依次执行调用并反馈结果
+func call(t *testing.T, b *CircuitBreaker, results ...bool) {
+	t.Helper()
+	for _, success := range results {
+		done, err := b.Allow()
+		if err != nil {
+			t.Fatalf("Allow error in state %s: %v", b.State(), err)
+		}
+		done(success)
+	}
+}
+
+func expectState(t *testing.T, b *CircuitBreaker, want string) {
+	t.Helper()
+	if got := b.State(); got != want {
+		t.Fatalf("state = %s, want %s", got, want)
+	}
+}
+
+func expectRejected(t *testing.T, b *CircuitBreaker) {
+	t.Helper()
+	if _, err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Allow error = %v, want %v", err, ErrCircuitOpen)
+	}
+}
+
+// 打开熔断器并等待进入半开
+func openBreaker(t *testing.T, b *CircuitBreaker) {
+	t.Helper()
+	call(t, b, false, false, false, false)
+	expectState(t, b, STATE_OPEN)
+	time.Sleep(testBreakerSetting.OpenDuration + 20*time.Millisecond)
+}
+
+func TestCircuitBreakerClosed(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []bool
+		want    string
+	}{
+		{name: "all success", results: []bool{true, true, true, true, true}, want: STATE_CLOSED},
+		{name: "below minimum requests", results: []bool{false, false, false}, want: STATE_CLOSED},
+		{name: "below threshold", results: []bool{true, true, true, false, true}, want: STATE_CLOSED},
+		{name: "reach threshold", results: []bool{true, false, true, false}, want: STATE_OPEN},
+		{name: "all failure", results: []bool{false, false, false, false}, want: STATE_OPEN},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewCircuitBreaker("test", testBreakerSetting)
+			call(t, b, tt.results...)
+			expectState(t, b, tt.want)
+		})
+	}
+}
+
+func TestCircuitBreakerWindow(t *testing.T) {
+	setting := testBreakerSetting
+	setting.Window = 30 * time.Millisecond
+	b := NewCircuitBreaker("test", setting)
+
+	// 窗口结束后重新计数，上一窗口的失败不计入
+	call(t, b, false, false, false)
+	time.Sleep(50 * time.Millisecond)
+	call(t, b, false, true, true)
+	expectState(t, b, STATE_CLOSED)
+
+	call(t, b, false)
+	expectState(t, b, STATE_OPEN)
+}
+
+func TestCircuitBreakerOpen(t *testing.T) {
+	b := NewCircuitBreaker("test", testBreakerSetting)
+	call(t, b, false, false, false, false)
+	expectState(t, b, STATE_OPEN)
+	expectRejected(t, b)
+
+	time.Sleep(testBreakerSetting.OpenDuration + 20*time.Millisecond)
+	call(t, b, true)
+	expectState(t, b, STATE_HALF_OPEN)
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	t.Run("probes succeed", func(t *testing.T) {
+		b := NewCircuitBreaker("test", testBreakerSetting)
+		openBreaker(t, b)
+
+		call(t, b, true)
+		expectState(t, b, STATE_HALF_OPEN)
+		call(t, b, true)
+		expectState(t, b, STATE_CLOSED)
+
+		// 关闭后重新计数
+		call(t, b, false, false, false)
+		expectState(t, b, STATE_CLOSED)
+	})
+
+	t.Run("probe fails", func(t *testing.T) {
+		b := NewCircuitBreaker("test", testBreakerSetting)
+		openBreaker(t, b)
+
+		call(t, b, true, false)
+		expectState(t, b, STATE_OPEN)
+		expectRejected(t, b)
+	})
+
+	t.Run("probes limited", func(t *testing.T) {
+		b := NewCircuitBreaker("test", testBreakerSetting)
+		openBreaker(t, b)
+
+		first, err := b.Allow()
+		if err != nil {
+			t.Fatalf("Allow error: %v", err)
+		}
+		second, err := b.Allow()
+		if err != nil {
+			t.Fatalf("Allow error: %v", err)
+		}
+		// 探测请求未完成前不再放行
+		expectRejected(t, b)
+
+		first(true)
+		second(true)
+		expectState(t, b, STATE_CLOSED)
+	})
+}
+
+func TestCircuitBreakerStaleResult(t *testing.T) {
+	b := NewCircuitBreaker("test", testBreakerSetting)
+
+	stale, err := b.Allow()
+	if err != nil {
+		t.Fatalf("Allow error: %v", err)
+	}
+	openBreaker(t, b)
+
+	// 打开后才反馈的关闭状态下发出的请求结果被忽略，不影响后续探测
+	stale(false)
+	expectState(t, b, STATE_OPEN)
+	call(t, b, true)
+	expectState(t, b, STATE_HALF_OPEN)
+	call(t, b, true)
+	expectState(t, b, STATE_CLOSED)
+}
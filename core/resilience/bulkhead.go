@@ -0,0 +1,56 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrBulkheadFull = errors.New("bulkhead is full")
+
+// 舱壁：限制对同一目标的并发调用数
+type Bulkhead struct {
+	slots   chan struct{}
+	maxWait time.Duration
+}
+
+func NewBulkhead(maxConcurrent int, maxWait time.Duration) *Bulkhead {
+	return &Bulkhead{
+		slots:   make(chan struct{}, maxConcurrent),
+		maxWait: maxWait,
+	}
+}
+
+// 获取空位，等待超过 maxWait 返回 ErrBulkheadFull
+func (b *Bulkhead) Acquire(ctx context.Context) (release func(), err error) {
+	release = func() {
+		<-b.slots
+	}
+
+	select {
+	case b.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	if b.maxWait <= 0 {
+		return nil, ErrBulkheadFull
+	}
+
+	timer := time.NewTimer(b.maxWait)
+	defer timer.Stop()
+
+	select {
+	case b.slots <- struct{}{}:
+		return release, nil
+	case <-timer.C:
+		return nil, ErrBulkheadFull
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// 当前并发数
+func (b *Bulkhead) InUse() int {
+	return len(b.slots)
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"time"
+
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+type RetrySetting struct {
+	MaxAttempts        int           `json:"maxAttempts" yaml:"maxAttempts"` // 总尝试次数，1 表示不重试
+	InitialInterval    time.Duration `json:"initialInterval" yaml:"initialInterval"`
+	MaxInterval        time.Duration `json:"maxInterval" yaml:"maxInterval"`
+	Multiplier         float64       `json:"multiplier"`
+	RetryOn            []int         `json:"retryOn" yaml:"retryOn"`                       // 需要重试的响应状态码
+	RetryNonIdempotent bool          `json:"retryNonIdempotent" yaml:"retryNonIdempotent"` // 非幂等请求（POST/PATCH）也重试
+}
+
+type CircuitBreakerSetting struct {
+	Enabled              bool          `json:"enabled"`
+	FailureRateThreshold float64       `json:"failureRateThreshold" yaml:"failureRateThreshold"` // 失败率达到后打开，0~1
+	MinimumRequests      int           `json:"minimumRequests" yaml:"minimumRequests"`           // 统计窗口内最少请求数
+	Window               time.Duration `json:"window"`                                           // 统计窗口
+	OpenDuration         time.Duration `json:"openDuration" yaml:"openDuration"`                 // 打开后多久进入半开
+	HalfOpenRequests     int           `json:"halfOpenRequests" yaml:"halfOpenRequests"`         // 半开时放行的探测请求数
+}
+
+type BulkheadSetting struct {
+	MaxConcurrent int           `json:"maxConcurrent" yaml:"maxConcurrent"` // 最大并发，0 不限制
+	MaxWait       time.Duration `json:"maxWait" yaml:"maxWait"`             // 等待空位的最长时间
+}
+
+type PolicySetting struct {
+	Timeout        time.Duration         `json:"timeout"`
+	Retry          RetrySetting          `json:"retry"`
+	CircuitBreaker CircuitBreakerSetting `json:"circuitBreaker" yaml:"circuitBreaker"`
+	Bulkhead       BulkheadSetting       `json:"bulkhead"`
+}
+
+type ResilienceSetting struct {
+	Enabled  bool                      `json:"enabled"`
+	Default  PolicySetting             `json:"default"`
+	Services map[string]*PolicySetting `json:"services"` // 按目标服务（服务名或 host）覆盖默认值
+}
+
+var Setting *ResilienceSetting = &ResilienceSetting{
+	Enabled: true,
+	Default: PolicySetting{
+		Timeout: time.Second * 30,
+		Retry: RetrySetting{
+			MaxAttempts:     1,
+			InitialInterval: time.Millisecond * 100,
+			MaxInterval:     time.Second * 2,
+			Multiplier:      2,
+			RetryOn:         []int{502, 503, 504},
+		},
+		CircuitBreaker: CircuitBreakerSetting{
+			Enabled:              false,
+			FailureRateThreshold: 0.5,
+			MinimumRequests:      20,
+			Window:               time.Second * 10,
+			OpenDuration:         time.Second * 30,
+			HalfOpenRequests:     3,
+		},
+	},
+	Services: map[string]*PolicySetting{},
+}
+
+// 目标服务的策略：服务配置中未设置的项使用默认值
+func (s *ResilienceSetting) Policy(service string) PolicySetting {
+	result := s.Default
+	override := s.Services[service]
+	if override == nil {
+		return result
+	}
+
+	if override.Timeout > 0 {
+		result.Timeout = override.Timeout
+	}
+
+	if override.Retry.MaxAttempts > 0 {
+		result.Retry.MaxAttempts = override.Retry.MaxAttempts
+	}
+	if override.Retry.InitialInterval > 0 {
+		result.Retry.InitialInterval = override.Retry.InitialInterval
+	}
+	if override.Retry.MaxInterval > 0 {
+		result.Retry.MaxInterval = override.Retry.MaxInterval
+	}
+	if override.Retry.Multiplier > 0 {
+		result.Retry.Multiplier = override.Retry.Multiplier
+	}
+	if len(override.Retry.RetryOn) > 0 {
+		result.Retry.RetryOn = override.Retry.RetryOn
+	}
+	result.Retry.RetryNonIdempotent = result.Retry.RetryNonIdempotent || override.Retry.RetryNonIdempotent
+
+	result.CircuitBreaker.Enabled = result.CircuitBreaker.Enabled || override.CircuitBreaker.Enabled
+	if override.CircuitBreaker.FailureRateThreshold > 0 {
+		result.CircuitBreaker.FailureRateThreshold = override.CircuitBreaker.FailureRateThreshold
+	}
+	if override.CircuitBreaker.MinimumRequests > 0 {
+		result.CircuitBreaker.MinimumRequests = override.CircuitBreaker.MinimumRequests
+	}
+	if override.CircuitBreaker.Window > 0 {
+		result.CircuitBreaker.Window = override.CircuitBreaker.Window
+	}
+	if override.CircuitBreaker.OpenDuration > 0 {
+		result.CircuitBreaker.OpenDuration = override.CircuitBreaker.OpenDuration
+	}
+	if override.CircuitBreaker.HalfOpenRequests > 0 {
+		result.CircuitBreaker.HalfOpenRequests = override.CircuitBreaker.HalfOpenRequests
+	}
+
+	if override.Bulkhead.MaxConcurrent > 0 {
+		result.Bulkhead.MaxConcurrent = override.Bulkhead.MaxConcurrent
+	}
+	if override.Bulkhead.MaxWait > 0 {
+		result.Bulkhead.MaxWait = override.Bulkhead.MaxWait
+	}
+	return result
+}
+
+func init() {
+	logger.Debug("Register Resilience Config")
+	config.RegisterConfig("resilience", Setting, "Resilience Settings")
+}
@@ -0,0 +1,84 @@
+package resilience
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/gophab/gophrame/core/feign"
+	"github.com/gophab/gophrame/core/resilience/config"
+)
+
+const HEADER_IDEMPOTENCY_KEY = "Idempotency-Key"
+
+// 读取并缓存响应体，避免超时上下文结束后无法读取
+func BufferBody(response *http.Response) error {
+	if response == nil || response.Body == nil {
+		return nil
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(data))
+	return nil
+}
+
+// Feign 容错拦截器：位于注册中心拦截器外层，重试时重新选择实例
+type FeignResilienceInterceptor struct {
+}
+
+func (in *FeignResilienceInterceptor) Do(chain *feign.FeignClientInterceptorChain, method string, urlPath string, urlValues url.Values, bodyValue interface{}, options ...*feign.RequestOptions) *feign.FeignClient {
+	if !config.Setting.Enabled {
+		return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+	}
+
+	target, err := url.Parse(urlPath)
+	if err != nil || target.Host == "" {
+		return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+	}
+	service := target.Hostname()
+
+	idempotent := IsIdempotent(method)
+	if len(options) > 0 && options[0].Headers[HEADER_IDEMPOTENCY_KEY] != "" {
+		idempotent = true
+	}
+
+	var result *feign.FeignClient
+	err = For(service).Execute(chain.Context(), idempotent, func(ctx context.Context) (int, error) {
+		result = chain.Fork(ctx).Next(method, urlPath, urlValues, bodyValue, options...)
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		if result.Response == nil {
+			return 0, nil
+		}
+		if err := BufferBody(result.Response); err != nil {
+			return 0, err
+		}
+		return result.Response.StatusCode, nil
+	})
+
+	client := chain.FeignClient
+	if err != nil {
+		if fallback := GetFallback(service); fallback != nil {
+			request, _ := http.NewRequestWithContext(chain.Context(), method, urlPath, nil)
+			if response, e := fallback(request, err); e == nil && response != nil {
+				client.Request, client.Response, client.Error = request, response, nil
+				return client
+			}
+		}
+		return chain.Exit(err)
+	}
+
+	client.Request, client.Response, client.Error = result.Request, result.Response, nil
+	return client
+}
+
+func init() {
+	feign.RegisterGlobalFeignClientInterceptorEx(&FeignResilienceInterceptor{}, -100)
+}
@@ -0,0 +1,188 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/resilience/config"
+)
+
+// 一次尝试，返回响应状态码（无响应时为 0）
+type Attempt func(ctx context.Context) (statusCode int, err error)
+
+// 目标服务的容错策略：超时、舱壁、熔断、重试
+type Policy struct {
+	Name     string
+	Setting  config.PolicySetting
+	breaker  *CircuitBreaker
+	bulkhead *Bulkhead
+}
+
+func NewPolicy(name string, setting config.PolicySetting) *Policy {
+	result := &Policy{Name: name, Setting: setting}
+	if setting.CircuitBreaker.Enabled {
+		result.breaker = NewCircuitBreaker(name, setting.CircuitBreaker)
+	}
+	if setting.Bulkhead.MaxConcurrent > 0 {
+		result.bulkhead = NewBulkhead(setting.Bulkhead.MaxConcurrent, setting.Bulkhead.MaxWait)
+	}
+	return result
+}
+
+// 熔断状态，未启用熔断时为空
+func (p *Policy) State() string {
+	if p.breaker == nil {
+		return ""
+	}
+	return p.breaker.State()
+}
+
+// 幂等方法可安全重试
+func IsIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func (p *Policy) retryable(statusCode int, err error) bool {
+	if err != nil {
+		// 熔断、舱壁及调用方取消不重试
+		return !errors.Is(err, ErrCircuitOpen) && !errors.Is(err, ErrBulkheadFull) && !errors.Is(err, context.Canceled)
+	}
+	for _, code := range p.Setting.Retry.RetryOn {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// 第 n 次重试前的等待时间
+func (p *Policy) backoff(n int) time.Duration {
+	retry := p.Setting.Retry
+	delay := float64(retry.InitialInterval) * math.Pow(math.Max(retry.Multiplier, 1), float64(n-1))
+	if retry.MaxInterval > 0 && delay > float64(retry.MaxInterval) {
+		return retry.MaxInterval
+	}
+	return time.Duration(delay)
+}
+
+// 执行调用：idempotent 为 false 且未配置 retryNonIdempotent 时不重试
+func (p *Policy) Execute(ctx context.Context, idempotent bool, attempt Attempt) error {
+	if p.bulkhead != nil {
+		release, err := p.bulkhead.Acquire(ctx)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
+	attempts := p.Setting.Retry.MaxAttempts
+	if attempts < 1 || (!idempotent && !p.Setting.Retry.RetryNonIdempotent) {
+		attempts = 1
+	}
+
+	var err error
+	for n := 1; ; n++ {
+		var statusCode int
+		statusCode, err = p.try(ctx, attempt)
+		if n >= attempts || !p.retryable(statusCode, err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				err = ctx.Err()
+			}
+			return err
+		case <-time.After(p.backoff(n)):
+		}
+	}
+}
+
+func (p *Policy) try(ctx context.Context, attempt Attempt) (int, error) {
+	if p.breaker != nil {
+		done, err := p.breaker.Allow()
+		if err != nil {
+			return 0, err
+		}
+		statusCode, err := p.timeout(ctx, attempt)
+		done(err == nil && statusCode < http.StatusInternalServerError)
+		return statusCode, err
+	}
+	return p.timeout(ctx, attempt)
+}
+
+func (p *Policy) timeout(ctx context.Context, attempt Attempt) (int, error) {
+	if p.Setting.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Setting.Timeout)
+		defer cancel()
+	}
+	return attempt(ctx)
+}
+
+// 调用失败（含熔断、舱壁拒绝）时的降级响应
+type Fallback func(request *http.Request, err error) (*http.Response, error)
+
+var (
+	policies    = make(map[string]*Policy)
+	fallbacks   = make(map[string]Fallback)
+	policyMutex sync.RWMutex
+)
+
+// 目标服务的策略，按配置创建并缓存
+func For(service string) *Policy {
+	policyMutex.RLock()
+	policy, ok := policies[service]
+	policyMutex.RUnlock()
+	if ok {
+		return policy
+	}
+
+	policyMutex.Lock()
+	defer policyMutex.Unlock()
+
+	if policy, ok = policies[service]; !ok {
+		policy = NewPolicy(service, config.Setting.Policy(service))
+		policies[service] = policy
+	}
+	return policy
+}
+
+// 全部策略的熔断状态
+func States() map[string]string {
+	policyMutex.RLock()
+	defer policyMutex.RUnlock()
+
+	result := make(map[string]string)
+	for name, policy := range policies {
+		if state := policy.State(); state != "" {
+			result[name] = state
+		}
+	}
+	return result
+}
+
+func RegisterFallback(service string, fallback Fallback) {
+	policyMutex.Lock()
+	defer policyMutex.Unlock()
+	fallbacks[service] = fallback
+}
+
+func GetFallback(service string) Fallback {
+	policyMutex.RLock()
+	defer policyMutex.RUnlock()
+	return fallbacks[service]
+}
+
+func Enabled() bool {
+	return config.Setting.Enabled
+}
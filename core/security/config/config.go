@@ -4,6 +4,7 @@ import (
 	"github.com/gophab/gophrame/core/config"
 	"github.com/gophab/gophrame/core/logger"
 
+	FeignConfig "github.com/gophab/gophrame/core/security/feign/config"
 	ServerConfig "github.com/gophab/gophrame/core/security/server/config"
 	TokenConfig "github.com/gophab/gophrame/core/security/token/config"
 )
@@ -21,6 +22,9 @@ type SecuritySetting struct {
 
 	// Token
 	Token *TokenConfig.TokenSetting `json:"token" yaml:"token"`
+
+	// 服务间调用认证
+	Feign *FeignConfig.FeignAuthSetting `json:"feign" yaml:"feign"`
 }
 
 var Setting *SecuritySetting = &SecuritySetting{
@@ -28,6 +32,7 @@ var Setting *SecuritySetting = &SecuritySetting{
 	AutoRegister: true,
 	Server:       ServerConfig.Setting,
 	Token:        TokenConfig.Setting,
+	Feign:        FeignConfig.Setting,
 }
 
 func init() {
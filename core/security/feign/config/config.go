@@ -0,0 +1,57 @@
+package config
+
+import (
+	"time"
+
+	"github.com/gophab/gophrame/core/config"
+)
+
+const (
+	MODE_NONE               = "none"
+	MODE_RELAY              = "relay"              // 透传入站请求的令牌及应用头
+	MODE_CLIENT_CREDENTIALS = "client_credentials" // 使用本服务的客户端凭证令牌
+	MODE_AUTO               = "auto"               // 有入站令牌时透传，否则使用客户端凭证
+)
+
+type ClientCredentialsSetting struct {
+	TokenUri      string        `json:"tokenUri" yaml:"tokenUri"` // 如 http://auth-service/oauth/token，支持服务名
+	ClientId      string        `json:"clientId" yaml:"clientId"`
+	ClientSecret  string        `json:"clientSecret" yaml:"clientSecret"`
+	Scope         string        `json:"scope"`
+	RefreshBefore time.Duration `json:"refreshBefore" yaml:"refreshBefore"` // 提前刷新时间
+}
+
+// 入站令牌只透传给 services 中显式列出的服务，避免泄露给外部地址；
+// 未列出的目标按 mode 处理，relay 不附加认证，auto 仅使用客户端凭证
+type FeignAuthSetting struct {
+	Mode              string                   `json:"mode"`
+	Services          map[string]string        `json:"services"`                         // 按目标服务指定模式
+	RelayHeaders      []string                 `json:"relayHeaders" yaml:"relayHeaders"` // 透传的请求头
+	ClientCredentials ClientCredentialsSetting `json:"clientCredentials" yaml:"clientCredentials"`
+}
+
+var Setting *FeignAuthSetting = &FeignAuthSetting{
+	Mode:         MODE_NONE,
+	RelayHeaders: []string{"Authorization", "X-App-Id", "X-Tenant-Id"},
+	ClientCredentials: ClientCredentialsSetting{
+		RefreshBefore: time.Minute,
+	},
+}
+
+// 目标服务的认证模式
+func (s *FeignAuthSetting) ModeOf(service string) string {
+	if mode, ok := s.Services[service]; ok && mode != "" {
+		return mode
+	}
+	return s.Mode
+}
+
+// 是否允许向目标服务透传入站请求头：仅限 services 中显式列出的服务
+func (s *FeignAuthSetting) RelayAllowed(service string) bool {
+	_, ok := s.Services[service]
+	return ok
+}
+
+func init() {
+	config.RegisterConfig("security.feign", Setting, "Feign Authentication Settings")
+}
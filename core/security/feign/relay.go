@@ -0,0 +1,106 @@
+package feign
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/gophab/gophrame/core/feign"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/security/feign/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type headersContextKey struct{}
+type withoutAuthContextKey struct{}
+
+// 显式携带需要透传的请求头，用于脱离 gin 请求的异步任务
+func WithHeaders(ctx context.Context, header http.Header) context.Context {
+	return context.WithValue(ctx, headersContextKey{}, header.Clone())
+}
+
+// 令牌请求本身不附加认证
+func withoutAuth(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutAuthContextKey{}, true)
+}
+
+// 入站请求头：优先 WithHeaders，其次 gin 请求
+func inboundHeader(ctx context.Context) http.Header {
+	if header, ok := ctx.Value(headersContextKey{}).(http.Header); ok {
+		return header
+	}
+	if c, ok := ctx.Value(gin.ContextKey).(*gin.Context); ok && c.Request != nil {
+		return c.Request.Header
+	}
+	return nil
+}
+
+// 服务间调用认证拦截器：按目标服务配置透传入站令牌或使用客户端凭证令牌
+type FeignAuthInterceptor struct {
+	TokenSource *TokenSource
+}
+
+func (in *FeignAuthInterceptor) Do(chain *feign.FeignClientInterceptorChain, method string, urlPath string, urlValues url.Values, bodyValue interface{}, options ...*feign.RequestOptions) *feign.FeignClient {
+	ctx := chain.Context()
+	if skip, _ := ctx.Value(withoutAuthContextKey{}).(bool); skip {
+		return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+	}
+
+	target, err := url.Parse(urlPath)
+	if err != nil || target.Host == "" {
+		return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+	}
+
+	mode := config.Setting.ModeOf(target.Hostname())
+	if mode == config.MODE_NONE || mode == "" {
+		return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+	}
+
+	// 复制请求选项，避免修改共享的默认选项
+	option := &feign.RequestOptions{
+		ContentType: feign.DefaultRequestOptions.ContentType,
+		Headers:     make(map[string]string),
+	}
+	if len(options) > 0 && options[0] != nil {
+		option.ContentType = options[0].ContentType
+		for k, v := range options[0].Headers {
+			option.Headers[k] = v
+		}
+	}
+
+	header := inboundHeader(ctx)
+	relay := config.Setting.RelayAllowed(target.Hostname()) &&
+		(mode == config.MODE_RELAY || (mode == config.MODE_AUTO && header.Get("Authorization") != ""))
+	if relay {
+		for _, name := range config.Setting.RelayHeaders {
+			if value := header.Get(name); value != "" {
+				if _, ok := option.Headers[name]; !ok {
+					option.Headers[name] = value
+				}
+			}
+		}
+	}
+
+	useClientToken := mode == config.MODE_CLIENT_CREDENTIALS || (mode == config.MODE_AUTO && !relay && in.TokenSource.Configured())
+	if useClientToken {
+		token, err := in.TokenSource.Token(ctx)
+		if err != nil {
+			logger.Error("[FEIGN] Acquire client credentials token error: ", err.Error())
+			return chain.Exit(err)
+		}
+		option.Headers["Authorization"] = "Bearer " + token
+	}
+
+	result := chain.Next(method, urlPath, urlValues, bodyValue, option)
+	if useClientToken && result.Error == nil && result.Response != nil && result.Response.StatusCode == http.StatusUnauthorized {
+		// 令牌可能已在服务端失效，下次调用重新获取
+		in.TokenSource.Invalidate()
+	}
+	return result
+}
+
+func init() {
+	// 位于注册中心拦截器外层，按服务名匹配配置
+	feign.RegisterGlobalFeignClientInterceptorEx(&FeignAuthInterceptor{TokenSource: defaultTokenSource}, -50)
+}
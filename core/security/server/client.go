@@ -2,6 +2,10 @@ package server
 
 import (
 	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+
+	"gorm.io/gorm"
 )
 
 /**
@@ -23,6 +27,29 @@ func (c *OAuthClient) TableName() string {
 	return "oauth_client_details"
 }
 
+// 客户端更新（含密钥轮换）后清除缓存
+func (c *OAuthClient) AfterSave(tx *gorm.DB) error {
+	return invalidateClient(tx, c.ClientId)
+}
+
+// 客户端删除（含逻辑删除）后清除缓存
+func (c *OAuthClient) AfterDelete(tx *gorm.DB) error {
+	return invalidateClient(tx, c.ClientId)
+}
+
+func invalidateClient(tx *gorm.DB, id string) error {
+	if id == "" {
+		return nil
+	}
+	// 缓存清除失败不回滚数据库变更，仅记录日志，缓存在 TTL 后过期
+	if store, ok := theClientStore.(*DatabaseClientStore); ok {
+		if err := store.Invalidate(tx.Statement.Context, id); err != nil {
+			logger.Warn("[OAUTH2] Invalidate client [", id, "] cache error: ", err.Error())
+		}
+	}
+	return nil
+}
+
 func (c *OAuthClient) GetID() string {
 	return c.ClientId
 }
@@ -2,9 +2,12 @@ package server
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"errors"
-	"sync"
 
+	"github.com/gophab/gophrame/core/cache"
 	"github.com/gophab/gophrame/core/database"
 	"github.com/gophab/gophrame/core/inject"
 
@@ -39,36 +42,68 @@ func InitClientStore() oauth2.ClientStore {
  */
 func NewDatabaseClientStore() (oauth2.ClientStore, error) {
 	return &DatabaseClientStore{
-		data: make(map[string]oauth2.ClientInfo),
+		cache: cache.SharedRegion("oauth2.client"),
 	}, nil
 }
 
 // ClientStore client information store
 type DatabaseClientStore struct {
-	sync.RWMutex
-	data map[string]oauth2.ClientInfo
+	cache cache.Cache // 未启用 Redis 时为 nil，每次读取数据库
+}
+
+// 缓存中的客户端信息：密钥仅保存摘要，缓存（如 Redis）中不出现明文密钥
+type cachedClient struct {
+	OAuthClient
+	SecretHash string `json:"secret_hash"`
+}
+
+func newCachedClient(client *OAuthClient) *cachedClient {
+	result := &cachedClient{OAuthClient: *client, SecretHash: hashSecret(client.ClientSecret)}
+	result.ClientSecret = ""
+	return result
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// 明文密钥不可用，校验统一通过 VerifyPassword
+func (c *cachedClient) GetSecret() string {
+	return ""
+}
+
+func (c *cachedClient) VerifyPassword(password string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashSecret(password)), []byte(c.SecretHash)) == 1
 }
 
 // GetByID according to the ID for the client information
 func (cs *DatabaseClientStore) GetByID(ctx context.Context, id string) (oauth2.ClientInfo, error) {
-	cs.RLock()
-	defer cs.RUnlock()
+	client, err := cache.GetOrLoad(ctx, cs.cache, id, 0, func(ctx context.Context) (*cachedClient, error) {
+		var client OAuthClient
 
-	if c, ok := cs.data[id]; ok {
-		return c, nil
-	}
+		result := database.DB().Where("client_id = ? AND del_flag = false ", id).First(&client)
+		if result.Error != nil {
+			return nil, result.Error
+		}
 
-	var client OAuthClient
+		if result.RowsAffected > 0 {
+			return newCachedClient(&client), nil
+		}
 
-	result := database.DB().Where("client_id = ? AND del_flag = false ", id).First(&client)
-	if result.Error != nil {
-		return nil, result.Error
-	}
+		return nil, errors.New("not found")
+	})
 
-	if result.RowsAffected > 0 {
-		cs.data[id] = &client
-		return &client, nil
+	if err != nil {
+		return nil, err
 	}
+	return client, nil
+}
 
-	return nil, errors.New("not found")
+// 客户端信息变更后清除缓存
+func (cs *DatabaseClientStore) Invalidate(ctx context.Context, ids ...string) error {
+	if cs.cache == nil {
+		return nil
+	}
+	return cs.cache.Delete(ctx, ids...)
 }
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/url"
-	"strings"
 	"time"
 
 	"github.com/gophab/gophrame/errors"
@@ -60,7 +59,7 @@ func (c *OAuth2Controller) InitRouter(g *gin.RouterGroup) *gin.RouterGroup {
 func (o *OAuth2Controller) Login(c *gin.Context) {
 	clientID, clientSecret, err := o.OAuth2Server.ClientInfoHandler(c.Request)
 	if err != nil {
-		response.FailMessage(c, http.StatusInternalServerError, "未知应用")
+		response.AbortWithError(c, errors.NewAppError(errors.ERROR_INVALID_CLIENT, http.StatusUnauthorized).WithCause(err))
 		return
 	}
 
@@ -68,7 +67,7 @@ func (o *OAuth2Controller) Login(c *gin.Context) {
 	if err := c.ShouldBind(&loginForm); err == nil {
 		store, err := session.Start(c.Request.Context(), c.Writer, c.Request)
 		if err != nil {
-			response.FailMessage(c, http.StatusInternalServerError, "会话错误")
+			response.AbortWithError(c, errors.ErrInternal.WithCause(err))
 			return
 		}
 
@@ -97,7 +96,8 @@ func (o *OAuth2Controller) Login(c *gin.Context) {
 		}
 
 		if userDetails == nil || err != nil {
-			response.Unauthorized(c, "账号密码错误")
+			// 不区分用户不存在与密码错误
+			response.AbortWithError(c, errors.NewAppError(errors.ERROR_BAD_CREDENTIALS, http.StatusUnauthorized).WithCause(err))
 			return
 		}
 
@@ -110,7 +110,7 @@ func (o *OAuth2Controller) Login(c *gin.Context) {
 			Scope:        "app",
 		})
 		if info == nil || err != nil {
-			response.FailMessage(c, http.StatusInternalServerError, "应用未授权")
+			response.AbortWithError(c, errors.NewAppError(errors.ERROR_UNAUTHORIZED_CLIENT, http.StatusForbidden).WithCause(err))
 			return
 		}
 
@@ -135,9 +135,9 @@ func (o *OAuth2Controller) Login(c *gin.Context) {
 		json.NewEncoder(c.Writer).Encode(o.OAuth2Server.GetTokenData(info))
 
 		// 发送用户登录事件
-		eventbus.PublishEvent("USER_LOGIN", userDetails.UserId, map[string]string{"IP": strings.Split(c.Request.RemoteAddr, ":")[0]})
+		eventbus.PublishEvent("USER_LOGIN", userDetails.UserId, map[string]string{"IP": c.ClientIP(), "RequestId": request.RequestId(c)})
 	} else {
-		http.Error(c.Writer, err.Error(), http.StatusNonAuthoritativeInfo)
+		response.AbortWithError(c, errors.ErrBadRequest.WithCause(err))
 		return
 	}
 
@@ -151,7 +151,7 @@ func (o *OAuth2Controller) Login(c *gin.Context) {
 func (o *OAuth2Controller) Auth(c *gin.Context) {
 	store, err := session.Start(c.Request.Context(), c.Writer, c.Request)
 	if err != nil {
-		http.Error(c.Writer, err.Error(), http.StatusInternalServerError)
+		response.AbortWithError(c, errors.ErrInternal.WithCause(err))
 		return
 	}
 
@@ -189,7 +189,7 @@ func (o *OAuth2Controller) Authorize(c *gin.Context) {
 
 	err = o.OAuth2Server.HandleAuthorizeRequest(c.Writer, c.Request)
 	if err != nil {
-		c.AbortWithError(500, err)
+		response.AbortWithError(c, errors.ErrInternal.WithCause(err))
 		return
 	}
 
@@ -203,7 +203,7 @@ func (o *OAuth2Controller) Authorize(c *gin.Context) {
 func (o *OAuth2Controller) HandleTokenRequest(c *gin.Context) {
 	err := o.OAuth2Server.HandleTokenRequest(c.Writer, c.Request)
 	if err != nil {
-		c.AbortWithError(500, err)
+		response.AbortWithError(c, errors.ErrInternal.WithCause(err))
 		return
 	}
 	c.Abort()
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gophab/gophrame/core/metrics"
+
+	"github.com/go-oauth2/oauth2/v4"
+)
+
+var (
+	tokenRequests = metrics.NewCounterVec("oauth2_token_requests_total",
+		"OAuth2 token issuance requests by grant type and result", "grant_type", "result")
+	tokenValidations = metrics.NewCounterVec("oauth2_token_validations_total",
+		"OAuth2 bearer token validations by result", "result")
+)
+
+func init() {
+	metrics.MustRegister(tokenRequests, tokenValidations)
+}
+
+// 未知的 grant_type 统一计为 other，避免标签膨胀
+func grantTypeLabel(grantType string) string {
+	switch oauth2.GrantType(grantType) {
+	case oauth2.AuthorizationCode, oauth2.ClientCredentials, oauth2.PasswordCredentials, oauth2.Implicit, oauth2.Refreshing:
+		return grantType
+	}
+	return "other"
+}
+
+// 记录令牌端点的响应状态
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(data)
+}
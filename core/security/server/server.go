@@ -110,7 +110,15 @@ func (s *OAuth2Server) HandleAuthorizeRequest(w http.ResponseWriter, r *http.Req
 }
 
 func (s *OAuth2Server) HandleTokenRequest(w http.ResponseWriter, r *http.Request) error {
-	return s.server.HandleTokenRequest(w, r.WithContext(context.WithValue(r.Context(), AppIdContextKey, r.Header.Get("X-App-Id"))))
+	recorder := &statusRecorder{ResponseWriter: w}
+	err := s.server.HandleTokenRequest(recorder, r.WithContext(context.WithValue(r.Context(), AppIdContextKey, r.Header.Get("X-App-Id"))))
+
+	result := "success"
+	if err != nil || recorder.status >= http.StatusBadRequest {
+		result = "failure"
+	}
+	tokenRequests.WithLabelValues(grantTypeLabel(r.FormValue("grant_type")), result).Inc()
+	return err
 }
 
 func (s *OAuth2Server) ClientInfoHandler(r *http.Request) (string, string, error) {
@@ -122,7 +130,13 @@ func (s *OAuth2Server) GetTokenData(ti oauth2.TokenInfo) map[string]interface{}
 }
 
 func (s *OAuth2Server) ValidationBearerToken(r *http.Request) (oauth2.TokenInfo, error) {
-	return s.server.ValidationBearerToken(r)
+	result, err := s.server.ValidationBearerToken(r)
+	if err != nil {
+		tokenValidations.WithLabelValues("failure").Inc()
+	} else {
+		tokenValidations.WithLabelValues("success").Inc()
+	}
+	return result, err
 }
 
 // oauth框架通过本方法识别用户身份信息,并且可以人为进行登录状态校验
@@ -38,7 +38,7 @@ func GetToken(c *gin.Context) (string, error) {
 }
 
 func GetCurrentUserId(c *gin.Context) string {
-	currentUserId := c.Value("_CURRENT_USER_ID_").(string)
+	currentUserId := c.GetString("_CURRENT_USER_ID_")
 	if currentUserId != "" {
 		return currentUserId
 	}
@@ -46,7 +46,7 @@ func GetCurrentUserId(c *gin.Context) string {
 }
 
 func GetCurrentTenantId(c *gin.Context) string {
-	currentTenantId := c.Value("_CURRENT_TENANT_ID_").(string)
+	currentTenantId := c.GetString("_CURRENT_TENANT_ID_")
 	if currentTenantId != "" {
 		return currentTenantId
 	}
@@ -68,6 +68,9 @@ func GetCurrentUser(c *gin.Context) *SecurityModel.UserDetails {
 	if currentUserId != "" {
 		if strings.HasPrefix(currentUserId, "sns:") {
 			// 社交账户
+			if service.GetSocialUserService() == nil {
+				return nil
+			}
 			if socialUser, _ := service.GetSocialUserService().GetById(strings.SplitN(currentUserId, ":", 2)[1]); socialUser != nil {
 				if socialUser.UserId == nil {
 					// 未绑定系统账号
@@ -89,8 +92,9 @@ func GetCurrentUser(c *gin.Context) *SecurityModel.UserDetails {
 			}
 		}
 
-		if currentUserId != "" {
-			if currentUser, err := service.GetUserService().GetById(currentUserId); err == nil {
+		// 未提供用户服务（如独立部署的微服务）时无法解析用户信息
+		if currentUserId != "" && service.GetUserService() != nil {
+			if currentUser, err := service.GetUserService().GetById(currentUserId); err == nil && currentUser != nil {
 				userDetails := &SecurityModel.UserDetails{
 					UserId:   currentUser.Id,
 					Login:    currentUser.Login,
@@ -0,0 +1,249 @@
+package sse
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/sse/config"
+)
+
+// 推送事件，Id 在 Broker 内全局递增，可直接作为 Last-Event-ID 续传
+type Event struct {
+	Id    int64       `json:"id"`
+	Topic string      `json:"topic"`
+	Event string      `json:"event"`
+	Data  interface{} `json:"data"`
+	Time  time.Time   `json:"time"`
+}
+
+func (e *Event) IdString() string {
+	return strconv.FormatInt(e.Id, 10)
+}
+
+const USER_TOPIC_PREFIX = "user:"
+
+// 用户私有主题名
+func UserTopic(userId string) string {
+	return USER_TOPIC_PREFIX + userId
+}
+
+// 主题：订阅连接及最近事件的环形缓冲
+type topic struct {
+	subscribers map[*subscriber]bool
+	replay      []*Event
+	head        int
+	size        int
+	updated     time.Time // 最近发布或退订时间，用于清理无订阅主题
+}
+
+func (t *topic) append(event *Event, capacity int) {
+	if capacity <= 0 {
+		return
+	}
+	if len(t.replay) != capacity {
+		t.replay = make([]*Event, capacity)
+		t.head, t.size = 0, 0
+	}
+	t.replay[(t.head+t.size)%capacity] = event
+	if t.size < capacity {
+		t.size++
+	} else {
+		t.head = (t.head + 1) % capacity
+	}
+}
+
+// 按顺序返回 Id 大于 after 的缓存事件
+func (t *topic) since(after int64) []*Event {
+	result := make([]*Event, 0)
+	for i := 0; i < t.size; i++ {
+		if event := t.replay[(t.head+i)%len(t.replay)]; event.Id > after {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+// 一个 SSE 连接
+type subscriber struct {
+	topics    []string
+	events    chan *Event
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *subscriber) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+// 进程内 SSE 事件中心：按主题分发，并为每个主题保留有限条事件用于断线续传
+type Broker struct {
+	topics    map[string]*topic
+	sequence  int64
+	lastSweep time.Time
+	mutex     sync.RWMutex
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		topics:    make(map[string]*topic),
+		lastSweep: time.Now(),
+	}
+}
+
+// 每分钟清理一次超过 replayTtl 无订阅的主题，调用方持有写锁
+func (b *Broker) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < time.Minute {
+		return
+	}
+	b.lastSweep = now
+
+	for name, t := range b.topics {
+		if len(t.subscribers) == 0 && now.Sub(t.updated) > config.Setting.ReplayTTL {
+			delete(b.topics, name)
+		}
+	}
+}
+
+// 发布事件到主题，返回事件 Id
+func (b *Broker) Publish(topicName, eventName string, data interface{}) int64 {
+	b.mutex.Lock()
+	b.sweep(time.Now())
+	b.sequence++
+	event := &Event{
+		Id:    b.sequence,
+		Topic: topicName,
+		Event: eventName,
+		Data:  data,
+		Time:  time.Now(),
+	}
+
+	t := b.topics[topicName]
+	if t == nil {
+		t = &topic{subscribers: make(map[*subscriber]bool)}
+		b.topics[topicName] = t
+	}
+	t.append(event, config.Setting.ReplaySize)
+	t.updated = event.Time
+
+	targets := make([]*subscriber, 0, len(t.subscribers))
+	for s := range t.subscribers {
+		targets = append(targets, s)
+	}
+	b.mutex.Unlock()
+
+	for _, s := range targets {
+		select {
+		case s.events <- event:
+		case <-s.done:
+		default:
+			// 发送队列已满：断开连接，由客户端携带 Last-Event-ID 重连续传
+			b.unsubscribe(s)
+		}
+	}
+	return event.Id
+}
+
+// 发送给用户的全部连接
+func (b *Broker) SendToUser(userId, eventName string, data interface{}) int64 {
+	return b.Publish(UserTopic(userId), eventName, data)
+}
+
+// 订阅主题，lastEventId > 0 时一并返回缓存中之后的事件
+func (b *Broker) subscribe(topics []string, lastEventId int64) (*subscriber, []*Event) {
+	s := &subscriber{
+		topics: topics,
+		events: make(chan *Event, config.Setting.BufferSize),
+		done:   make(chan struct{}),
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	replay := make([]*Event, 0)
+	for _, name := range topics {
+		t := b.topics[name]
+		if t == nil {
+			t = &topic{subscribers: make(map[*subscriber]bool)}
+			b.topics[name] = t
+		}
+		t.subscribers[s] = true
+		if lastEventId > 0 {
+			replay = append(replay, t.since(lastEventId)...)
+		}
+	}
+	sortEvents(replay)
+	return s, replay
+}
+
+func (b *Broker) unsubscribe(s *subscriber) {
+	b.mutex.Lock()
+	for _, name := range s.topics {
+		if t := b.topics[name]; t != nil {
+			delete(t.subscribers, s)
+			// 无订阅且无缓存的主题直接清理，有缓存的保留 replayTtl 供续传
+			if len(t.subscribers) == 0 {
+				if t.size == 0 || config.Setting.ReplayTTL <= 0 {
+					delete(b.topics, name)
+				} else {
+					t.updated = time.Now()
+				}
+			}
+		}
+	}
+	b.mutex.Unlock()
+
+	s.close()
+}
+
+// 主题当前连接数
+func (b *Broker) SubscriberCount(topicName string) int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if t := b.topics[topicName]; t != nil {
+		return len(t.subscribers)
+	}
+	return 0
+}
+
+// 断开全部连接
+func (b *Broker) Close() {
+	b.mutex.RLock()
+	subscribers := make(map[*subscriber]bool)
+	for _, t := range b.topics {
+		for s := range t.subscribers {
+			subscribers[s] = true
+		}
+	}
+	b.mutex.RUnlock()
+
+	for s := range subscribers {
+		b.unsubscribe(s)
+	}
+}
+
+func sortEvents(events []*Event) {
+	// 插入排序：各主题内已有序，合并量很小
+	for i := 1; i < len(events); i++ {
+		for j := i; j > 0 && events[j].Id < events[j-1].Id; j-- {
+			events[j], events[j-1] = events[j-1], events[j]
+		}
+	}
+}
+
+var defaultBroker = NewBroker()
+
+func Default() *Broker {
+	return defaultBroker
+}
+
+func Publish(topicName, eventName string, data interface{}) int64 {
+	return defaultBroker.Publish(topicName, eventName, data)
+}
+
+func SendToUser(userId, eventName string, data interface{}) int64 {
+	return defaultBroker.SendToUser(userId, eventName, data)
+}
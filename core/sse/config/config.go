@@ -0,0 +1,35 @@
+package config
+
+import (
+	"time"
+
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+type SSESetting struct {
+	Enabled           bool          `json:"enabled"`
+	Path              string        `json:"path"`                                       // 订阅端点，为空时不注册
+	RequireAuth       bool          `json:"requireAuth" yaml:"requireAuth"`             // 订阅端点需要登录
+	HeartbeatInterval time.Duration `json:"heartbeatInterval" yaml:"heartbeatInterval"` // 心跳间隔
+	Retry             time.Duration `json:"retry"`                                      // 客户端重连间隔建议
+	ReplaySize        int           `json:"replaySize" yaml:"replaySize"`               // 每个主题保留的事件数，用于 Last-Event-ID 续传
+	ReplayTTL         time.Duration `json:"replayTtl" yaml:"replayTtl"`                 // 无订阅主题的续传缓存保留时间
+	BufferSize        int           `json:"bufferSize" yaml:"bufferSize"`               // 每个连接待发送事件上限，超过时断开由客户端续传
+}
+
+var Setting *SSESetting = &SSESetting{
+	Enabled:           false,
+	Path:              "/sse",
+	RequireAuth:       true,
+	HeartbeatInterval: time.Second * 15,
+	Retry:             time.Second * 3,
+	ReplaySize:        100,
+	ReplayTTL:         time.Minute * 5,
+	BufferSize:        64,
+}
+
+func init() {
+	logger.Debug("Register SSE Config")
+	config.RegisterConfig("sse", Setting, "Server-Sent Events Settings")
+}
@@ -0,0 +1,55 @@
+package sse
+
+import (
+	"github.com/gophab/gophrame/core/eventbus"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+// 通过事件总线推送：eventbus.DispatchEvent(EVENT_SSE_PUBLISH, topic, event, data)
+const (
+	EVENT_SSE_PUBLISH = "sse.publish"
+	EVENT_SSE_USER    = "sse.user" // 参数：userId, event, data
+)
+
+func init() {
+	eventbus.RegisterEventListener(EVENT_SSE_PUBLISH, func(args ...interface{}) {
+		if target, eventName, data, ok := eventArgs(args); ok {
+			Publish(target, eventName, data)
+		}
+	})
+	eventbus.RegisterEventListener(EVENT_SSE_USER, func(args ...interface{}) {
+		if target, eventName, data, ok := eventArgs(args); ok {
+			SendToUser(target, eventName, data)
+		}
+	})
+}
+
+func eventArgs(args []interface{}) (target, eventName string, data interface{}, ok bool) {
+	if len(args) < 2 {
+		logger.Warn("[SSE] Invalid event arguments: ", args)
+		return
+	}
+	if target, ok = args[0].(string); !ok {
+		logger.Warn("[SSE] Invalid event target: ", args[0])
+		return
+	}
+	if eventName, ok = args[1].(string); !ok {
+		logger.Warn("[SSE] Invalid event name: ", args[1])
+		return
+	}
+	if len(args) > 2 {
+		data = args[2]
+	}
+	return
+}
+
+// 将事件总线中的事件转发到 SSE 主题：单个参数作为数据，多个参数以数组发送
+func Forward(eventKey, topicName, eventName string) {
+	eventbus.RegisterEventListener(eventKey, func(args ...interface{}) {
+		var data interface{} = args
+		if len(args) == 1 {
+			data = args[0]
+		}
+		Publish(topicName, eventName, data)
+	})
+}
@@ -0,0 +1,129 @@
+package sse
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/sse/config"
+	"github.com/gophab/gophrame/core/webservice/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 从请求中解析订阅主题
+type TopicsFunc func(c *gin.Context) []string
+
+// 默认使用查询参数 topic（可多个），用户主题只能订阅自己的
+func QueryTopics(c *gin.Context) []string {
+	userTopic := ""
+	if userId := c.GetString("_CURRENT_USER_ID_"); userId != "" {
+		userTopic = UserTopic(userId)
+	}
+
+	result := make([]string, 0)
+	for _, name := range c.QueryArray("topic") {
+		if strings.HasPrefix(name, USER_TOPIC_PREFIX) && name != userTopic {
+			logger.Warn("[SSE] Subscribe to topic [", name, "] denied")
+			continue
+		}
+		result = append(result, name)
+	}
+	return result
+}
+
+// SSE 端点：已登录用户自动订阅自己的用户主题，支持 Last-Event-ID 续传
+func Handler(topics ...string) gin.HandlerFunc {
+	return HandlerFunc(func(c *gin.Context) []string {
+		return append(append([]string{}, topics...), QueryTopics(c)...)
+	})
+}
+
+func HandlerFunc(topicsFunc TopicsFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defaultBroker.Serve(c, topicsFunc(c)...)
+	}
+}
+
+func lastEventId(c *gin.Context) int64 {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		// EventSource 无法自定义请求头，首次连接可用查询参数指定
+		value = c.Query("lastEventId")
+	}
+	if value == "" {
+		return 0
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// 保持连接并推送事件，直到客户端断开或连接被服务端关闭
+func (b *Broker) Serve(c *gin.Context, topics ...string) {
+	if userId := c.GetString("_CURRENT_USER_ID_"); userId != "" {
+		topics = append(topics, UserTopic(userId))
+	}
+	if len(topics) == 0 {
+		response.Bad(c, http.StatusBadRequest, "No topic subscribed")
+		return
+	}
+
+	s, replay := b.subscribe(topics, lastEventId(c))
+	defer b.unsubscribe(s)
+
+	response.SSEHeaders(c)
+	c.Status(http.StatusOK)
+
+	if err := response.SSE(c, &response.SSEvent{Retry: config.Setting.Retry}); err != nil {
+		return
+	}
+
+	// 续传快照与订阅在同一把锁内完成，之后发布的事件只进入发送队列，两者不会重复；
+	// 并发发布的事件到达顺序可能与 Id 顺序不同，不能按 Id 去重
+	write := func(event *Event) bool {
+		if err := response.SSE(c, &response.SSEvent{
+			Id:    event.IdString(),
+			Event: event.Event,
+			Data:  event.Data,
+		}); err != nil {
+			logger.Debug("[SSE] Write event error: ", err.Error())
+			return false
+		}
+		return true
+	}
+
+	for _, event := range replay {
+		if !write(event) {
+			return
+		}
+	}
+
+	heartbeat := config.Setting.HeartbeatInterval
+	if heartbeat <= 0 {
+		heartbeat = time.Second * 15
+	}
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-s.done:
+			return
+		case event := <-s.events:
+			if !write(event) {
+				return
+			}
+		case <-ticker.C:
+			if err := response.SSEComment(c, "ping"); err != nil {
+				return
+			}
+		}
+	}
+}
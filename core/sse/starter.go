@@ -0,0 +1,30 @@
+package sse
+
+import (
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/router"
+	"github.com/gophab/gophrame/core/security"
+	"github.com/gophab/gophrame/core/sse/config"
+	"github.com/gophab/gophrame/core/starter"
+)
+
+func init() {
+	starter.RegisterStarter(Start)
+	starter.RegisterTerminater(Terminate)
+}
+
+func Start() {
+	logger.Debug("Enable SSE: ...", config.Setting.Enabled)
+	if config.Setting.Enabled && config.Setting.Path != "" {
+		if config.Setting.RequireAuth {
+			router.Root().GET(config.Setting.Path, security.HandleTokenVerify(), Handler())
+		} else {
+			// 匿名可订阅公共主题，携带 token 时同时订阅用户主题
+			router.Root().GET(config.Setting.Path, security.CheckTokenVerify(), Handler())
+		}
+	}
+}
+
+func Terminate() {
+	defaultBroker.Close()
+}
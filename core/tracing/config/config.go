@@ -0,0 +1,63 @@
+package config
+
+import (
+	"time"
+
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+const (
+	EXPORTER_NONE   = "none"
+	EXPORTER_STDOUT = "stdout"
+	EXPORTER_OTLP   = "otlp"
+	EXPORTER_MEMORY = "memory"
+)
+
+type OtlpSetting struct {
+	Endpoint string            `json:"endpoint"` // OTLP/HTTP 地址，如 http://localhost:4318/v1/traces
+	Headers  map[string]string `json:"headers"`
+	Timeout  time.Duration     `json:"timeout"`
+}
+
+type SamplerSetting struct {
+	Ratio       float64 `json:"ratio"`                          // 采样比例，0~1
+	ParentBased bool    `json:"parentBased" yaml:"parentBased"` // 有上游链路时沿用上游的采样决定
+}
+
+type TracingSetting struct {
+	Enabled        bool           `json:"enabled"`
+	ServiceName    string         `json:"serviceName" yaml:"serviceName"`
+	Exporter       string         `json:"exporter"` // none / stdout / otlp / memory
+	Otlp           OtlpSetting    `json:"otlp"`
+	Sampler        SamplerSetting `json:"sampler"`
+	QueueSize      int            `json:"queueSize" yaml:"queueSize"` // 待导出队列长度，满时丢弃
+	BatchSize      int            `json:"batchSize" yaml:"batchSize"`
+	FlushInterval  time.Duration  `json:"flushInterval" yaml:"flushInterval"`
+	ResponseHeader string         `json:"responseHeader" yaml:"responseHeader"` // 响应中返回链路 ID 的头，为空不返回
+	ExcludePaths   []string       `json:"excludePaths" yaml:"excludePaths"`     // 不记录的请求路径，以 /** 结尾时包含子路径
+}
+
+var Setting *TracingSetting = &TracingSetting{
+	Enabled:     false,
+	ServiceName: "gophrame",
+	Exporter:    EXPORTER_STDOUT,
+	Otlp: OtlpSetting{
+		Endpoint: "http://localhost:4318/v1/traces",
+		Timeout:  time.Second * 10,
+	},
+	Sampler: SamplerSetting{
+		Ratio:       1,
+		ParentBased: true,
+	},
+	QueueSize:      2048,
+	BatchSize:      512,
+	FlushInterval:  time.Second * 5,
+	ResponseHeader: "X-Trace-Id",
+	ExcludePaths:   []string{"/health/**"},
+}
+
+func init() {
+	logger.Debug("Register Tracing Config")
+	config.RegisterConfig("tracing", Setting, "Tracing Settings")
+}
@@ -0,0 +1,213 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/tracing/config"
+)
+
+// 导出已结束的 Span
+type Exporter interface {
+	Export(ctx context.Context, spans []*Span) error
+	Shutdown(ctx context.Context) error
+}
+
+type ExporterFactory func(setting *config.TracingSetting) (Exporter, error)
+
+var (
+	exporterFactories = make(map[string]ExporterFactory)
+	factoriesMutex    sync.RWMutex
+)
+
+// 注册导出器，按 tracing.exporter 配置选择
+func RegisterExporterFactory(name string, factory ExporterFactory) {
+	factoriesMutex.Lock()
+	defer factoriesMutex.Unlock()
+	exporterFactories[name] = factory
+}
+
+func CreateExporter(setting *config.TracingSetting) (Exporter, error) {
+	factoriesMutex.RLock()
+	factory, ok := exporterFactories[setting.Exporter]
+	factoriesMutex.RUnlock()
+
+	if !ok {
+		return nil, errors.New("unknown tracing exporter: " + setting.Exporter)
+	}
+	return factory(setting)
+}
+
+// Span 结束时的处理
+type SpanProcessor interface {
+	OnEnd(span *Span)
+	Shutdown(ctx context.Context) error
+}
+
+// 同步导出，用于测试
+type SimpleProcessor struct {
+	exporter Exporter
+}
+
+func NewSimpleProcessor(exporter Exporter) *SimpleProcessor {
+	return &SimpleProcessor{exporter: exporter}
+}
+
+func (p *SimpleProcessor) OnEnd(span *Span) {
+	if err := p.exporter.Export(context.Background(), []*Span{span}); err != nil {
+		logger.Warn("[TRACING] Export span error: ", err.Error())
+	}
+}
+
+func (p *SimpleProcessor) Shutdown(ctx context.Context) error {
+	return p.exporter.Shutdown(ctx)
+}
+
+// 批量异步导出：攒够 BatchSize 或每隔 FlushInterval 导出一次，队列满时丢弃
+type BatchProcessor struct {
+	exporter      Exporter
+	queue         chan *Span
+	batchSize     int
+	flushInterval time.Duration
+	dropped       int64
+	done          chan struct{}
+	wg            sync.WaitGroup
+	closeOnce     sync.Once
+	mutex         sync.Mutex
+}
+
+func NewBatchProcessor(exporter Exporter, setting *config.TracingSetting) *BatchProcessor {
+	queueSize := setting.QueueSize
+	if queueSize <= 0 {
+		queueSize = 2048
+	}
+	batchSize := setting.BatchSize
+	if batchSize <= 0 || batchSize > queueSize {
+		batchSize = queueSize
+	}
+	flushInterval := setting.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = time.Second * 5
+	}
+
+	result := &BatchProcessor{
+		exporter:      exporter,
+		queue:         make(chan *Span, queueSize),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		done:          make(chan struct{}),
+	}
+	result.wg.Add(1)
+	go result.run()
+	return result
+}
+
+func (p *BatchProcessor) OnEnd(span *Span) {
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+
+	select {
+	case p.queue <- span:
+	default:
+		p.mutex.Lock()
+		p.dropped++
+		p.mutex.Unlock()
+	}
+}
+
+// 因队列已满丢弃的 Span 数
+func (p *BatchProcessor) Dropped() int64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.dropped
+}
+
+func (p *BatchProcessor) run() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, p.batchSize)
+	for {
+		select {
+		case span := <-p.queue:
+			batch = append(batch, span)
+			if len(batch) >= p.batchSize {
+				batch = p.export(batch)
+			}
+		case <-ticker.C:
+			batch = p.export(batch)
+		case <-p.done:
+			// 导出队列中剩余的 Span
+			for {
+				select {
+				case span := <-p.queue:
+					batch = append(batch, span)
+					if len(batch) >= p.batchSize {
+						batch = p.export(batch)
+					}
+				default:
+					p.export(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (p *BatchProcessor) export(batch []*Span) []*Span {
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := p.exporter.Export(context.Background(), batch); err != nil {
+		logger.Warn("[TRACING] Export ", len(batch), " spans error: ", err.Error())
+	}
+	return make([]*Span, 0, p.batchSize)
+}
+
+func (p *BatchProcessor) Shutdown(ctx context.Context) error {
+	p.closeOnce.Do(func() {
+		close(p.done)
+	})
+
+	finished := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return p.exporter.Shutdown(ctx)
+}
+
+var (
+	processor      SpanProcessor
+	processorMutex sync.RWMutex
+)
+
+// 设置 Span 处理器，返回原处理器
+func SetProcessor(p SpanProcessor) SpanProcessor {
+	processorMutex.Lock()
+	defer processorMutex.Unlock()
+
+	result := processor
+	processor = p
+	return result
+}
+
+func getProcessor() SpanProcessor {
+	processorMutex.RLock()
+	defer processorMutex.RUnlock()
+	return processor
+}
@@ -0,0 +1,287 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/tracing/config"
+)
+
+// 丢弃全部 Span，仅传播链路标识
+type noneExporter struct{}
+
+func (noneExporter) Export(context.Context, []*Span) error {
+	return nil
+}
+
+func (noneExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// 每个 Span 输出一行 JSON
+type StdoutExporter struct {
+	writer io.Writer
+	mutex  sync.Mutex
+}
+
+func NewStdoutExporter(writer io.Writer) *StdoutExporter {
+	if writer == nil {
+		writer = os.Stdout
+	}
+	return &StdoutExporter{writer: writer}
+}
+
+type stdoutSpan struct {
+	TraceId    string                 `json:"traceId"`
+	SpanId     string                 `json:"spanId"`
+	ParentId   string                 `json:"parentId,omitempty"`
+	Name       string                 `json:"name"`
+	Kind       SpanKind               `json:"kind"`
+	Start      time.Time              `json:"start"`
+	Duration   string                 `json:"duration"`
+	Status     StatusCode             `json:"status,omitempty"`
+	Message    string                 `json:"message,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+func (e *StdoutExporter) Export(_ context.Context, spans []*Span) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	encoder := json.NewEncoder(e.writer)
+	for _, span := range spans {
+		item := &stdoutSpan{
+			TraceId:    span.Context.TraceID.String(),
+			SpanId:     span.Context.SpanID.String(),
+			Name:       span.Name,
+			Kind:       span.Kind,
+			Start:      span.StartTime,
+			Duration:   span.Duration().String(),
+			Status:     span.Status.Code,
+			Message:    span.Status.Message,
+			Attributes: span.Attributes,
+		}
+		if span.ParentSpanID.IsValid() {
+			item.ParentId = span.ParentSpanID.String()
+		}
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *StdoutExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// 内存导出，用于测试断言
+type MemoryExporter struct {
+	spans []*Span
+	mutex sync.Mutex
+}
+
+func NewMemoryExporter() *MemoryExporter {
+	return &MemoryExporter{spans: make([]*Span, 0)}
+}
+
+func (e *MemoryExporter) Export(_ context.Context, spans []*Span) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *MemoryExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func (e *MemoryExporter) Spans() []*Span {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	result := make([]*Span, len(e.spans))
+	copy(result, e.spans)
+	return result
+}
+
+func (e *MemoryExporter) Reset() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.spans = make([]*Span, 0)
+}
+
+// OTLP/HTTP JSON 导出
+type OtlpExporter struct {
+	serviceName string
+	setting     *config.OtlpSetting
+	client      *http.Client
+}
+
+func NewOtlpExporter(serviceName string, setting *config.OtlpSetting) *OtlpExporter {
+	return &OtlpExporter{
+		serviceName: serviceName,
+		setting:     setting,
+		client:      &http.Client{Timeout: setting.Timeout},
+	}
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    StatusCode `json:"code,omitempty"`
+	Message string     `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceId           string          `json:"traceId"`
+	SpanId            string          `json:"spanId"`
+	TraceState        string          `json:"traceState,omitempty"`
+	ParentSpanId      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              SpanKind        `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+func otlpAttributeValue(v interface{}) otlpValue {
+	switch t := v.(type) {
+	case string:
+		return otlpValue{StringValue: &t}
+	case bool:
+		return otlpValue{BoolValue: &t}
+	case int:
+		s := strconv.FormatInt(int64(t), 10)
+		return otlpValue{IntValue: &s}
+	case int32:
+		s := strconv.FormatInt(int64(t), 10)
+		return otlpValue{IntValue: &s}
+	case int64:
+		s := strconv.FormatInt(t, 10)
+		return otlpValue{IntValue: &s}
+	case float32:
+		f := float64(t)
+		return otlpValue{DoubleValue: &f}
+	case float64:
+		return otlpValue{DoubleValue: &t}
+	default:
+		s := fmt.Sprint(v)
+		return otlpValue{StringValue: &s}
+	}
+}
+
+func otlpAttributes(attributes map[string]interface{}) []otlpAttribute {
+	result := make([]otlpAttribute, 0, len(attributes))
+	for k, v := range attributes {
+		result = append(result, otlpAttribute{Key: k, Value: otlpAttributeValue(v)})
+	}
+	return result
+}
+
+func (e *OtlpExporter) Export(ctx context.Context, spans []*Span) error {
+	items := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		item := otlpSpan{
+			TraceId:           span.Context.TraceID.String(),
+			SpanId:            span.Context.SpanID.String(),
+			TraceState:        span.Context.TraceState,
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+			Status:            otlpStatus{Code: span.Status.Code, Message: span.Status.Message},
+		}
+		if span.ParentSpanID.IsValid() {
+			item.ParentSpanId = span.ParentSpanID.String()
+		}
+		items = append(items, item)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": otlpAttributes(map[string]interface{}{"service.name": e.serviceName}),
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]interface{}{"name": "github.com/gophab/gophrame/core/tracing"},
+						"spans": items,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.setting.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.setting.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("otlp export failed: %s", resp.Status)
+	}
+	return nil
+}
+
+func (e *OtlpExporter) Shutdown(context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+var defaultMemoryExporter = NewMemoryExporter()
+
+// exporter: memory 时使用的导出器
+func DefaultMemoryExporter() *MemoryExporter {
+	return defaultMemoryExporter
+}
+
+func init() {
+	RegisterExporterFactory(config.EXPORTER_NONE, func(*config.TracingSetting) (Exporter, error) {
+		return noneExporter{}, nil
+	})
+	RegisterExporterFactory(config.EXPORTER_STDOUT, func(*config.TracingSetting) (Exporter, error) {
+		return NewStdoutExporter(os.Stdout), nil
+	})
+	RegisterExporterFactory(config.EXPORTER_MEMORY, func(*config.TracingSetting) (Exporter, error) {
+		return defaultMemoryExporter, nil
+	})
+	RegisterExporterFactory(config.EXPORTER_OTLP, func(setting *config.TracingSetting) (Exporter, error) {
+		return NewOtlpExporter(setting.ServiceName, &setting.Otlp), nil
+	})
+}
@@ -0,0 +1,56 @@
+package tracing
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gophab/gophrame/core/feign"
+)
+
+// Feign 客户端 Span，并将 traceparent 写入请求头；位于重试拦截器内层，每次尝试一个 Span
+type FeignTracingInterceptor struct{}
+
+func (in *FeignTracingInterceptor) Do(chain *feign.FeignClientInterceptorChain, method string, urlPath string, urlValues url.Values, bodyValue interface{}, options ...*feign.RequestOptions) *feign.FeignClient {
+	if !Enabled() {
+		return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+	}
+
+	attributes := map[string]interface{}{
+		"http.method": method,
+		"http.url":    urlPath,
+	}
+	if target, err := url.Parse(urlPath); err == nil {
+		attributes["peer.service"] = target.Hostname()
+	}
+
+	ctx, span := Start(chain.Context(), "HTTP "+method, WithKind(SPAN_KIND_CLIENT), WithAttributes(attributes))
+
+	// 复制请求选项，避免修改共享的默认选项
+	option := &feign.RequestOptions{
+		ContentType: feign.DefaultRequestOptions.ContentType,
+		Headers:     make(map[string]string),
+	}
+	if len(options) > 0 && options[0] != nil {
+		option.ContentType = options[0].ContentType
+		for k, v := range options[0].Headers {
+			option.Headers[k] = v
+		}
+	}
+	Inject(ctx, MapCarrier(option.Headers))
+
+	result := chain.Next(method, urlPath, urlValues, bodyValue, option)
+	if result.Error != nil {
+		span.RecordError(result.Error)
+	} else if result.Response != nil {
+		span.SetAttribute("http.status_code", result.Response.StatusCode)
+		if result.Response.StatusCode >= http.StatusInternalServerError {
+			span.SetStatus(STATUS_ERROR, result.Response.Status)
+		}
+	}
+	span.End()
+	return result
+}
+
+func init() {
+	feign.RegisterGlobalFeignClientInterceptorEx(&FeignTracingInterceptor{}, -80)
+}
@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/gophab/gophrame/core/tracing/config"
+	"github.com/gophab/gophrame/core/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+func excluded(path string) bool {
+	return util.MatchAnyPath(config.Setting.ExcludePaths, path)
+}
+
+// 服务端 Span：读取上游 traceparent，后续处理通过 c.Request.Context() 或 c 获取当前链路
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Enabled() || excluded(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		ctx := Extract(c.Request.Context(), HeaderCarrier(c.Request.Header))
+		ctx, span := Start(ctx, c.Request.Method+" "+route, WithKind(SPAN_KIND_SERVER), WithAttributes(map[string]interface{}{
+			"http.method":    c.Request.Method,
+			"http.route":     route,
+			"http.target":    c.Request.URL.RequestURI(),
+			"http.client_ip": c.ClientIP(),
+		}))
+		c.Request = c.Request.WithContext(ctx)
+
+		if config.Setting.ResponseHeader != "" {
+			c.Header(config.Setting.ResponseHeader, span.Context.TraceID.String())
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttribute("http.status_code", status)
+		if len(c.Errors) > 0 {
+			span.SetAttribute("error.message", c.Errors.String())
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(STATUS_ERROR, http.StatusText(status))
+		}
+		span.End()
+	}
+}
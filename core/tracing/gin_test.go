@@ -0,0 +1,32 @@
+package tracing
+
+import (
+	"testing"
+
+	"github.com/gophab/gophrame/core/tracing/config"
+)
+
+func TestExcluded(t *testing.T) {
+	saved := config.Setting.ExcludePaths
+	defer func() { config.Setting.ExcludePaths = saved }()
+	config.Setting.ExcludePaths = []string{"/health/**", "/static/*.js"}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/health", true},
+		{"/health/live", true},
+		{"/health/ready", true},
+		{"/healthz", false},
+		{"/static/app.js", true},
+		{"/static/js/app.js", false},
+		{"/api/users", false},
+	}
+
+	for _, tt := range tests {
+		if got := excluded(tt.path); got != tt.want {
+			t.Errorf("excluded(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
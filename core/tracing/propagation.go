@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+const (
+	HEADER_TRACEPARENT = "traceparent"
+	HEADER_TRACESTATE  = "tracestate"
+)
+
+// 链路标识的载体，如 HTTP 头、消息头
+type Carrier interface {
+	Get(key string) string
+	Set(key string, value string)
+}
+
+type HeaderCarrier http.Header
+
+func (c HeaderCarrier) Get(key string) string {
+	return http.Header(c).Get(key)
+}
+
+func (c HeaderCarrier) Set(key string, value string) {
+	http.Header(c).Set(key, value)
+}
+
+type MapCarrier map[string]string
+
+func (c MapCarrier) Get(key string) string {
+	return c[key]
+}
+
+func (c MapCarrier) Set(key string, value string) {
+	c[key] = value
+}
+
+// 写入当前链路：traceparent: 00-<trace-id>-<span-id>-<flags>
+func Inject(ctx context.Context, carrier Carrier) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	carrier.Set(HEADER_TRACEPARENT, "00-"+sc.TraceID.String()+"-"+sc.SpanID.String()+"-"+flags)
+	if sc.TraceState != "" {
+		carrier.Set(HEADER_TRACESTATE, sc.TraceState)
+	}
+}
+
+// 读取上游链路，无效时原样返回 ctx
+func Extract(ctx context.Context, carrier Carrier) context.Context {
+	sc, ok := ParseTraceparent(carrier.Get(HEADER_TRACEPARENT))
+	if !ok {
+		return ctx
+	}
+	sc.TraceState = carrier.Get(HEADER_TRACESTATE)
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
+
+func ParseTraceparent(value string) (result SpanContext, ok bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return
+	}
+	// 版本 00 恰好 4 段，ff 为非法版本
+	if parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return
+	}
+	if _, err := hex.DecodeString(parts[0]); err != nil {
+		return
+	}
+	if _, err := hex.Decode(result.TraceID[:], []byte(parts[1])); err != nil {
+		return
+	}
+	if _, err := hex.Decode(result.SpanID[:], []byte(parts[2])); err != nil {
+		return
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return
+	}
+	result.Sampled = flags[0]&0x01 == 0x01
+	result.Remote = true
+	return result, result.IsValid()
+}
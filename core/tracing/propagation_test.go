@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+)
+
+const (
+	testTraceId = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanId  = "00f067aa0ba902b7"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		wantOk      bool
+		wantSampled bool
+	}{
+		{name: "sampled", value: "00-" + testTraceId + "-" + testSpanId + "-01", wantOk: true, wantSampled: true},
+		{name: "not sampled", value: "00-" + testTraceId + "-" + testSpanId + "-00", wantOk: true},
+		{name: "other flags", value: "00-" + testTraceId + "-" + testSpanId + "-03", wantOk: true, wantSampled: true},
+		{name: "surrounding spaces", value: "  00-" + testTraceId + "-" + testSpanId + "-01 ", wantOk: true, wantSampled: true},
+		{name: "future version with extra parts", value: "01-" + testTraceId + "-" + testSpanId + "-01-extra", wantOk: true, wantSampled: true},
+		{name: "empty", value: ""},
+		{name: "too few parts", value: "00-" + testTraceId + "-" + testSpanId},
+		{name: "version 00 with extra parts", value: "00-" + testTraceId + "-" + testSpanId + "-01-extra"},
+		{name: "invalid version ff", value: "ff-" + testTraceId + "-" + testSpanId + "-01"},
+		{name: "non hex version", value: "zz-" + testTraceId + "-" + testSpanId + "-01"},
+		{name: "short trace id", value: "00-" + testTraceId[1:] + "-" + testSpanId + "-01"},
+		{name: "short span id", value: "00-" + testTraceId + "-" + testSpanId[1:] + "-01"},
+		{name: "long flags", value: "00-" + testTraceId + "-" + testSpanId + "-001"},
+		{name: "non hex trace id", value: "00-" + "x" + testTraceId[1:] + "-" + testSpanId + "-01"},
+		{name: "non hex span id", value: "00-" + testTraceId + "-" + "x" + testSpanId[1:] + "-01"},
+		{name: "non hex flags", value: "00-" + testTraceId + "-" + testSpanId + "-zz"},
+		{name: "zero trace id", value: "00-00000000000000000000000000000000-" + testSpanId + "-01"},
+		{name: "zero span id", value: "00-" + testTraceId + "-0000000000000000-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, ok := ParseTraceparent(tt.value)
+			if ok != tt.wantOk {
+				t.Fatalf("ParseTraceparent(%q) ok = %v, want %v", tt.value, ok, tt.wantOk)
+			}
+			if !ok {
+				return
+			}
+			if sc.TraceID.String() != testTraceId || sc.SpanID.String() != testSpanId {
+				t.Fatalf("ParseTraceparent(%q) = %s-%s", tt.value, sc.TraceID, sc.SpanID)
+			}
+			if sc.Sampled != tt.wantSampled {
+				t.Fatalf("Sampled = %v, want %v", sc.Sampled, tt.wantSampled)
+			}
+			if !sc.Remote {
+				t.Fatal("Remote = false, want true")
+			}
+		})
+	}
+}
+
+func TestInjectExtract(t *testing.T) {
+	for _, sampled := range []bool{true, false} {
+		sc, _ := ParseTraceparent("00-" + testTraceId + "-" + testSpanId + "-00")
+		sc.Sampled = sampled
+		sc.TraceState = "vendor=value"
+
+		carrier := MapCarrier{}
+		Inject(ContextWithRemoteSpanContext(context.Background(), sc), carrier)
+
+		got := SpanContextFromContext(Extract(context.Background(), carrier))
+		if got != sc {
+			t.Fatalf("round trip = %+v, want %+v", got, sc)
+		}
+	}
+
+	// 无链路时不写入，无效链路不读取
+	carrier := MapCarrier{}
+	Inject(context.Background(), carrier)
+	if len(carrier) != 0 {
+		t.Fatalf("Inject without span wrote %v", carrier)
+	}
+	carrier[HEADER_TRACEPARENT] = "invalid"
+	if sc := SpanContextFromContext(Extract(context.Background(), carrier)); sc.IsValid() {
+		t.Fatalf("Extract invalid traceparent = %+v", sc)
+	}
+}
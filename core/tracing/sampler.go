@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/gophab/gophrame/core/tracing/config"
+)
+
+// 采样策略，决定新 Span 是否记录
+type Sampler interface {
+	ShouldSample(traceId TraceID, parent SpanContext) bool
+}
+
+type alwaysSampler struct{}
+
+func (alwaysSampler) ShouldSample(TraceID, SpanContext) bool {
+	return true
+}
+
+type neverSampler struct{}
+
+func (neverSampler) ShouldSample(TraceID, SpanContext) bool {
+	return false
+}
+
+func AlwaysSample() Sampler {
+	return alwaysSampler{}
+}
+
+func NeverSample() Sampler {
+	return neverSampler{}
+}
+
+// 按链路 ID 比例采样，同一链路在各服务的决定一致
+type ratioSampler struct {
+	threshold uint64
+}
+
+func RatioSampler(ratio float64) Sampler {
+	if ratio >= 1 {
+		return alwaysSampler{}
+	}
+	if ratio <= 0 {
+		return neverSampler{}
+	}
+	return &ratioSampler{threshold: uint64(ratio * (1 << 63))}
+}
+
+func (s *ratioSampler) ShouldSample(traceId TraceID, _ SpanContext) bool {
+	return binary.BigEndian.Uint64(traceId[8:])>>1 < s.threshold
+}
+
+// 有上游链路时沿用上游决定，否则交给 root 采样
+type parentBasedSampler struct {
+	root Sampler
+}
+
+func ParentBasedSampler(root Sampler) Sampler {
+	return &parentBasedSampler{root: root}
+}
+
+func (s *parentBasedSampler) ShouldSample(traceId TraceID, parent SpanContext) bool {
+	if parent.IsValid() {
+		return parent.Sampled
+	}
+	return s.root.ShouldSample(traceId, parent)
+}
+
+func samplerFromSetting(setting *config.SamplerSetting) Sampler {
+	result := RatioSampler(setting.Ratio)
+	if setting.ParentBased {
+		result = ParentBasedSampler(result)
+	}
+	return result
+}
+
+var (
+	sampler      Sampler
+	samplerMutex sync.RWMutex
+)
+
+func SetSampler(s Sampler) {
+	samplerMutex.Lock()
+	defer samplerMutex.Unlock()
+	sampler = s
+}
+
+func getSampler() Sampler {
+	samplerMutex.RLock()
+	result := sampler
+	samplerMutex.RUnlock()
+
+	if result == nil {
+		return samplerFromSetting(&config.Setting.Sampler)
+	}
+	return result
+}
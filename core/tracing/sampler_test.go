@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// 低 8 字节为 low 的链路 ID，比例采样只看低 8 字节
+func traceIdWithLow(low uint64) (result TraceID) {
+	result[0] = 0xff
+	binary.BigEndian.PutUint64(result[8:], low)
+	return
+}
+
+func TestSamplers(t *testing.T) {
+	sampled := SpanContext{TraceID: traceIdWithLow(1), SpanID: SpanID{1}, Sampled: true}
+	notSampled := SpanContext{TraceID: traceIdWithLow(1), SpanID: SpanID{1}}
+
+	tests := []struct {
+		name    string
+		sampler Sampler
+		traceId TraceID
+		parent  SpanContext
+		want    bool
+	}{
+		{name: "always", sampler: AlwaysSample(), traceId: traceIdWithLow(^uint64(0)), want: true},
+		{name: "never", sampler: NeverSample(), traceId: traceIdWithLow(0), want: false},
+		{name: "ratio 1", sampler: RatioSampler(1), traceId: traceIdWithLow(^uint64(0)), want: true},
+		{name: "ratio above 1", sampler: RatioSampler(2), traceId: traceIdWithLow(^uint64(0)), want: true},
+		{name: "ratio 0", sampler: RatioSampler(0), traceId: traceIdWithLow(0), want: false},
+		{name: "ratio below 0", sampler: RatioSampler(-1), traceId: traceIdWithLow(0), want: false},
+		{name: "ratio half lowest", sampler: RatioSampler(0.5), traceId: traceIdWithLow(0), want: true},
+		{name: "ratio half below threshold", sampler: RatioSampler(0.5), traceId: traceIdWithLow(1<<63 - 1), want: true},
+		{name: "ratio half at threshold", sampler: RatioSampler(0.5), traceId: traceIdWithLow(1 << 63), want: false},
+		{name: "ratio half highest", sampler: RatioSampler(0.5), traceId: traceIdWithLow(^uint64(0)), want: false},
+		{name: "ratio ignores parent", sampler: RatioSampler(0.5), traceId: traceIdWithLow(^uint64(0)), parent: sampled, want: false},
+		{name: "parent sampled", sampler: ParentBasedSampler(NeverSample()), traceId: traceIdWithLow(1), parent: sampled, want: true},
+		{name: "parent not sampled", sampler: ParentBasedSampler(AlwaysSample()), traceId: traceIdWithLow(1), parent: notSampled, want: false},
+		{name: "no parent uses root", sampler: ParentBasedSampler(AlwaysSample()), traceId: traceIdWithLow(1), want: true},
+		{name: "invalid parent uses root", sampler: ParentBasedSampler(NeverSample()), traceId: traceIdWithLow(1), parent: SpanContext{Sampled: true}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sampler.ShouldSample(tt.traceId, tt.parent); got != tt.want {
+				t.Fatalf("ShouldSample = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRatioSamplerDistribution(t *testing.T) {
+	sampler := RatioSampler(0.25)
+
+	// 低 8 字节均匀分布时，采样比例等于设置值
+	total, count := 1<<10, 0
+	for i := 0; i < total; i++ {
+		if sampler.ShouldSample(traceIdWithLow(uint64(i)<<54), SpanContext{}) {
+			count++
+		}
+	}
+	if count != total/4 {
+		t.Fatalf("sampled %d of %d, want %d", count, total, total/4)
+	}
+}
@@ -0,0 +1,284 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/tracing/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TraceID [16]byte
+type SpanID [8]byte
+
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+func newTraceID() (result TraceID) {
+	for !result.IsValid() {
+		_, _ = rand.Read(result[:])
+	}
+	return
+}
+
+func newSpanID() (result SpanID) {
+	for !result.IsValid() {
+		_, _ = rand.Read(result[:])
+	}
+	return
+}
+
+// W3C trace context 中的链路标识
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Sampled    bool
+	TraceState string
+	Remote     bool // 由上游传入
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// 与 OTLP 取值一致
+type SpanKind int
+
+const (
+	SPAN_KIND_INTERNAL SpanKind = 1
+	SPAN_KIND_SERVER   SpanKind = 2
+	SPAN_KIND_CLIENT   SpanKind = 3
+	SPAN_KIND_PRODUCER SpanKind = 4
+	SPAN_KIND_CONSUMER SpanKind = 5
+)
+
+type StatusCode int
+
+const (
+	STATUS_UNSET StatusCode = 0
+	STATUS_OK    StatusCode = 1
+	STATUS_ERROR StatusCode = 2
+)
+
+type Status struct {
+	Code    StatusCode
+	Message string
+}
+
+// 一次操作的记录，结束后交给导出器；所有方法允许在 nil 上调用
+type Span struct {
+	Name         string
+	Kind         SpanKind
+	Context      SpanContext
+	ParentSpanID SpanID
+	StartTime    time.Time
+	EndTime      time.Time
+	Attributes   map[string]interface{}
+	Status       Status
+	ended        bool
+	mutex        sync.Mutex
+}
+
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.Context
+}
+
+// 是否记录并导出
+func (s *Span) IsRecording() bool {
+	return s != nil && s.Context.Sampled
+}
+
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if !s.IsRecording() {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.ended {
+		s.Attributes[key] = value
+	}
+}
+
+func (s *Span) SetStatus(code StatusCode, message string) {
+	if !s.IsRecording() {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.ended {
+		s.Status = Status{Code: code, Message: message}
+	}
+}
+
+// 记录错误并标记失败，err 为 nil 时忽略
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.SetAttribute("error.message", err.Error())
+	s.SetStatus(STATUS_ERROR, err.Error())
+}
+
+func (s *Span) End() {
+	if !s.IsRecording() {
+		return
+	}
+
+	s.mutex.Lock()
+	if s.ended {
+		s.mutex.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	s.mutex.Unlock()
+
+	if p := getProcessor(); p != nil {
+		p.OnEnd(s)
+	}
+}
+
+func (s *Span) Duration() time.Duration {
+	return s.EndTime.Sub(s.StartTime)
+}
+
+type SpanOptions struct {
+	Kind       SpanKind
+	Attributes map[string]interface{}
+}
+
+type SpanOption func(*SpanOptions)
+
+func WithKind(kind SpanKind) SpanOption {
+	return func(o *SpanOptions) {
+		o.Kind = kind
+	}
+}
+
+func WithAttributes(attributes map[string]interface{}) SpanOption {
+	return func(o *SpanOptions) {
+		if o.Attributes == nil {
+			o.Attributes = make(map[string]interface{})
+		}
+		for k, v := range attributes {
+			o.Attributes[k] = v
+		}
+	}
+}
+
+type spanContextKey struct{}
+type remoteContextKey struct{}
+
+func Enabled() bool {
+	return config.Setting.Enabled
+}
+
+// 开始一个子 Span，上下文中无链路时开始新链路；未开启链路追踪时返回 nil Span
+func Start(ctx context.Context, name string, options ...SpanOption) (context.Context, *Span) {
+	if !Enabled() {
+		return ctx, nil
+	}
+
+	opts := &SpanOptions{Kind: SPAN_KIND_INTERNAL}
+	for _, option := range options {
+		option(opts)
+	}
+
+	parent := SpanContextFromContext(ctx)
+
+	result := &Span{
+		Name:       name,
+		Kind:       opts.Kind,
+		StartTime:  time.Now(),
+		Attributes: opts.Attributes,
+	}
+	if result.Attributes == nil {
+		result.Attributes = make(map[string]interface{})
+	}
+
+	if parent.IsValid() {
+		result.Context.TraceID = parent.TraceID
+		result.Context.TraceState = parent.TraceState
+		result.ParentSpanID = parent.SpanID
+	} else {
+		result.Context.TraceID = newTraceID()
+	}
+	result.Context.SpanID = newSpanID()
+	result.Context.Sampled = getSampler().ShouldSample(result.Context.TraceID, parent)
+
+	return context.WithValue(ctx, spanContextKey{}, result), result
+}
+
+// 当前 Span，兼容直接传入 *gin.Context 的情况
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	if span, ok := ctx.Value(spanContextKey{}).(*Span); ok {
+		return span
+	}
+	if c, ok := ctx.Value(gin.ContextKey).(*gin.Context); ok && c.Request != nil {
+		if span, ok := c.Request.Context().Value(spanContextKey{}).(*Span); ok {
+			return span
+		}
+	}
+	return nil
+}
+
+// 当前链路标识：当前 Span，否则为上游传入的链路
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.Context
+	}
+	if ctx != nil {
+		if sc, ok := ctx.Value(remoteContextKey{}).(SpanContext); ok {
+			return sc
+		}
+	}
+	return SpanContext{}
+}
+
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	sc.Remote = true
+	return context.WithValue(ctx, remoteContextKey{}, sc)
+}
+
+// 当前链路 ID，无链路时为空
+func TraceIdFromContext(ctx context.Context) string {
+	if sc := SpanContextFromContext(ctx); sc.IsValid() {
+		return sc.TraceID.String()
+	}
+	return ""
+}
+
+// 将 Span 设为 ctx 的当前 Span，用于跨越不同的上下文（如消费者的取消上下文）
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	if span == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanContextKey{}, span)
+}
@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/gophab/gophrame/core/engine"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/starter"
+	"github.com/gophab/gophrame/core/tracing/config"
+)
+
+func init() {
+	// 先于其他模块注册路由
+	starter.RegisterInitializorEx(Init, -100)
+	starter.RegisterTerminater(Terminate)
+
+	logger.RegisterContextFields(func(ctx context.Context) string {
+		if sc := SpanContextFromContext(ctx); sc.IsValid() {
+			return "trace_id=" + sc.TraceID.String() + " span_id=" + sc.SpanID.String()
+		}
+		return ""
+	})
+}
+
+func Init() {
+	logger.Debug("Initializing Tracing: ...", config.Setting.Enabled)
+	if !config.Setting.Enabled {
+		return
+	}
+
+	exporter, err := CreateExporter(config.Setting)
+	if err != nil {
+		logger.Error("[TRACING] Create exporter error: ", err.Error())
+		return
+	}
+	SetProcessor(NewBatchProcessor(exporter, config.Setting))
+
+	engine.Get().Use(Middleware())
+}
+
+func Terminate() {
+	if p := SetProcessor(nil); p != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+
+		if err := p.Shutdown(ctx); err != nil {
+			logger.Warn("[TRACING] Shutdown error: ", err.Error())
+		}
+	}
+}
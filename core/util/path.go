@@ -0,0 +1,25 @@
+package util
+
+import (
+	"path"
+	"strings"
+)
+
+// 请求路径匹配：以 /** 结尾时匹配该路径及其所有子路径，否则按 path.Match 通配
+func MatchPath(pattern, urlPath string) bool {
+	if prefix := strings.TrimSuffix(pattern, "/**"); prefix != pattern {
+		return urlPath == prefix || strings.HasPrefix(urlPath, prefix+"/")
+	}
+	matched, _ := path.Match(pattern, urlPath)
+	return matched
+}
+
+// 路径是否匹配任一模式
+func MatchAnyPath(patterns []string, urlPath string) bool {
+	for _, pattern := range patterns {
+		if MatchPath(pattern, urlPath) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package validator
 
 import (
 	"fmt"
+	"net/http"
 	"reflect"
 	"strings"
 
@@ -13,7 +14,9 @@ import (
 	enTranslations "github.com/go-playground/validator/v10/translations/en"
 	zhTranslations "github.com/go-playground/validator/v10/translations/zh"
 
+	"github.com/gophab/gophrame/core/i18n"
 	"github.com/gophab/gophrame/core/webservice/response"
+	"github.com/gophab/gophrame/errors"
 
 	"github.com/gin-gonic/gin"
 )
@@ -29,27 +32,89 @@ const (
 	ErrorsValidatorBindParamsFail string = "验证器绑定参数失败"
 )
 
+const (
+	MESSAGE_ALL_PARAMS_IS_BLANK = "validator.allParamsIsBlank"
+)
+
+var ErrParamsCheckFail = errors.NewAppError(ValidatorParamsCheckFailCode, http.StatusBadRequest)
+
+func init() {
+	errors.AddErrorMessage(ValidatorParamsCheckFailCode, ValidatorParamsCheckFailMsg)
+	errors.RegisterErrorMessages("en", map[int]string{
+		ValidatorParamsCheckFailCode: "Parameter validation failed",
+	})
+
+	i18n.RegisterMessages("zh", map[string]string{
+		MESSAGE_ALL_PARAMS_IS_BLANK: ErrorNotAllParamsIsBlank,
+	})
+	i18n.RegisterMessages("en", map[string]string{
+		MESSAGE_ALL_PARAMS_IS_BLANK: "All parameters are blank, please submit the required parameters",
+	})
+
+	// 字段名须在首次校验前注册，否则已缓存的结构体仍使用字段原名
+	initTranslators()
+}
+
 // 参数校验错误
 func ErrorParam(c *gin.Context, wrongParam interface{}) {
-	response.FailMessage(c, ValidatorParamsCheckFailCode, ValidatorParamsCheckFailMsg)
-	c.Abort()
+	response.AbortWithError(c, ErrParamsCheckFail.WithDetails(wrongParam))
 }
 
-// ValidatorError 翻译表单参数验证器出现的校验错误
+// ValidatorError 按请求语言翻译表单参数验证器出现的校验错误，字段错误放入 details
 func ValidatorError(c *gin.Context, err error) {
-	if _, ok := err.(validator.ValidationErrors); ok {
-		//wrongParam := RemoveTopStruct(errs.Translate(Trans))
-		response.FailMessage(c, ValidatorParamsCheckFailCode, ValidatorParamsCheckFailMsg)
+	if errs, ok := err.(validator.ValidationErrors); ok {
+		appErr := ErrParamsCheckFail.WithCause(err)
+		if trans := translator(i18n.Language(c)); trans != nil {
+			appErr = appErr.WithDetails(RemoveTopStruct(errs.Translate(trans)))
+		}
+		response.AbortWithError(c, appErr)
 	} else {
 		errStr := err.Error()
 		// multipart:nextpart:eof 错误表示验证器需要一些参数，但是调用者没有提交任何参数
 		if strings.ReplaceAll(strings.ToLower(errStr), " ", "") == "multipart:nextpart:eof" {
-			response.FailMessage(c, ValidatorParamsCheckFailCode, ErrorNotAllParamsIsBlank)
+			response.AbortWithError(c, ErrParamsCheckFail.WithKey(MESSAGE_ALL_PARAMS_IS_BLANK).WithCause(err))
 		} else {
-			response.FailMessage(c, ValidatorParamsCheckFailCode, ValidatorParamsCheckFailMsg)
+			response.AbortWithError(c, ErrParamsCheckFail.WithCause(err))
+		}
+	}
+}
+
+var translators = make(map[string]ut.Translator)
+
+// 注册 json 字段名及中英文翻译
+func initTranslators() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	uni := ut.New(en.New(), en.New(), zh.New())
+	if trans, ok := uni.GetTranslator("en"); ok && enTranslations.RegisterDefaultTranslations(v, trans) == nil {
+		translators["en"] = trans
+	}
+	if trans, ok := uni.GetTranslator("zh"); ok && zhTranslations.RegisterDefaultTranslations(v, trans) == nil {
+		translators["zh"] = trans
+	}
+}
+
+// 按语言返回翻译器，不支持的语言使用英文
+func translator(language string) ut.Translator {
+	if trans, ok := translators[language]; ok {
+		return trans
+	}
+	if i := strings.Index(language, "-"); i > 0 {
+		if trans, ok := translators[language[:i]]; ok {
+			return trans
 		}
 	}
-	c.Abort()
+	return translators["en"]
 }
 
 // Trans 定义一个全局翻译器T
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"time"
+
+	"github.com/gophab/gophrame/core/config"
+	"github.com/gophab/gophrame/core/logger"
+)
+
+const (
+	RATE_LIMIT_TOKEN_BUCKET   = "token-bucket"
+	RATE_LIMIT_SLIDING_WINDOW = "sliding-window"
+
+	RATE_LIMIT_BACKEND_MEMORY = "memory"
+	RATE_LIMIT_BACKEND_REDIS  = "redis"
+
+	RATE_LIMIT_KEY_IP     = "ip"
+	RATE_LIMIT_KEY_USER   = "user"
+	RATE_LIMIT_KEY_CLIENT = "client"
+	RATE_LIMIT_KEY_APIKEY = "apikey"
+	RATE_LIMIT_KEY_GLOBAL = "global"
+)
+
+type RequestIdSetting struct {
+	Enabled bool   `json:"enabled"`
+	Header  string `json:"header"` // 请求 ID 头，上游未携带时生成，并在响应及下游调用中回传
+}
+
+type ClientIpSetting struct {
+	TrustedProxies  []string `json:"trustedProxies" yaml:"trustedProxies"`   // 可信代理的 IP 或 CIDR，仅信任来自这些地址的转发头
+	Headers         []string `json:"headers"`                                // 解析真实 IP 的请求头，按顺序检查
+	TrustedPlatform string   `json:"trustedPlatform" yaml:"trustedPlatform"` // 云平台提供的客户端 IP 头，如 CF-Connecting-IP
+}
+
+type AccessLogSetting struct {
+	Enabled       bool          `json:"enabled"`
+	ExcludePaths  []string      `json:"excludePaths" yaml:"excludePaths"`   // 不记录的请求路径，以 /** 结尾时包含子路径
+	SlowThreshold time.Duration `json:"slowThreshold" yaml:"slowThreshold"` // 超过该耗时以 WARNING 级别记录，为 0 不区分
+	MaskParams    []string      `json:"maskParams" yaml:"maskParams"`       // 记录时隐藏取值的 query 参数（不区分大小写），如 websocket 的 token 参数
+}
+
+type RateLimitRule struct {
+	Name      string        `json:"name"`      // 规则名称，用于区分计数，为空时使用路径
+	Path      string        `json:"path"`      // 路径模式：精确路径、path.Match 通配（/openapi/*/code）或前缀（/openapi/**）
+	Methods   []string      `json:"methods"`   // 为空时匹配全部方法
+	Key       string        `json:"key"`       // 限流维度：ip / user / client / apikey / global 或自定义提取器名称；user 维度仅在挂载 UserRateLimit 的路由组生效
+	Algorithm string        `json:"algorithm"` // token-bucket / sliding-window
+	Limit     int           `json:"limit"`     // 窗口内允许的请求数
+	Window    time.Duration `json:"window"`
+	Burst     int           `json:"burst"` // 令牌桶容量，为 0 时等于 Limit
+}
+
+type RateLimitSetting struct {
+	Enabled      bool            `json:"enabled"`
+	Backend      string          `json:"backend"`                          // memory / redis，redis 为集群共享计数
+	Database     int             `json:"database"`                         // redis 数据库
+	KeyPrefix    string          `json:"keyPrefix" yaml:"keyPrefix"`       // redis 键前缀
+	ApiKeyHeader string          `json:"apiKeyHeader" yaml:"apiKeyHeader"` // apikey 维度读取的请求头
+	Headers      bool            `json:"headers"`                          // 是否返回 RateLimit-* 响应头
+	Rules        []RateLimitRule `json:"rules"`                            // 匹配的规则全部生效，任一超限即拒绝；未配置时使用默认规则
+}
+
+// 默认规则不预置在 Setting 中：配置解码到已有切片元素时，未填写的字段会沿用默认规则的值
+var DefaultRateLimitRules = []RateLimitRule{
+	{
+		Name:      "login",
+		Path:      "/oauth/login",
+		Methods:   []string{"POST"},
+		Key:       RATE_LIMIT_KEY_IP,
+		Algorithm: RATE_LIMIT_SLIDING_WINDOW,
+		Limit:     10,
+		Window:    time.Minute,
+	},
+	{
+		Name:      "code",
+		Path:      "/openapi/*/code",
+		Key:       RATE_LIMIT_KEY_IP,
+		Algorithm: RATE_LIMIT_SLIDING_WINDOW,
+		Limit:     5,
+		Window:    time.Minute,
+	},
+	{
+		Name:      "openapi",
+		Path:      "/openapi/**",
+		Key:       RATE_LIMIT_KEY_USER,
+		Algorithm: RATE_LIMIT_TOKEN_BUCKET,
+		Limit:     600,
+		Window:    time.Minute,
+		Burst:     100,
+	},
+}
+
+// 生效的规则：未配置 rules 时使用默认规则，配置为空列表时不限流
+func (s *RateLimitSetting) EffectiveRules() []RateLimitRule {
+	if s.Rules == nil {
+		return DefaultRateLimitRules
+	}
+	return s.Rules
+}
+
+type CorsSetting struct {
+	Enabled          bool          `json:"enabled"`
+	AllowOrigins     []string      `json:"allowOrigins" yaml:"allowOrigins"`         // 允许的来源，支持 * 及子域名通配，如 https://*.example.com
+	AllowMethods     []string      `json:"allowMethods" yaml:"allowMethods"`         // 预检响应允许的方法
+	AllowHeaders     []string      `json:"allowHeaders" yaml:"allowHeaders"`         // 预检响应允许的请求头，为空时回传请求的头
+	ExposeHeaders    []string      `json:"exposeHeaders" yaml:"exposeHeaders"`       // 允许前端读取的响应头
+	AllowCredentials bool          `json:"allowCredentials" yaml:"allowCredentials"` // 允许携带 Cookie，仅对显式列出的来源生效，* 匹配的来源不允许凭证
+	MaxAge           time.Duration `json:"maxAge" yaml:"maxAge"`                     // 预检结果缓存时间
+	Strict           bool          `json:"strict"`                                   // 来源不在白名单时直接拒绝请求
+}
+
+// 各项为空时不返回对应响应头；按路由覆盖时为空表示沿用默认值，为 "-" 表示不返回
+type SecurityHeadersPolicy struct {
+	StrictTransportSecurity string `json:"strictTransportSecurity" yaml:"strictTransportSecurity"` // HSTS，浏览器仅在 HTTPS 下生效
+	ContentSecurityPolicy   string `json:"contentSecurityPolicy" yaml:"contentSecurityPolicy"`
+	ContentTypeOptions      string `json:"contentTypeOptions" yaml:"contentTypeOptions"`
+	FrameOptions            string `json:"frameOptions" yaml:"frameOptions"`
+	ReferrerPolicy          string `json:"referrerPolicy" yaml:"referrerPolicy"`
+	PermissionsPolicy       string `json:"permissionsPolicy" yaml:"permissionsPolicy"`
+}
+
+type SecurityHeadersRoute struct {
+	Path string `json:"path"` // 路径模式，同限流规则
+	SecurityHeadersPolicy
+}
+
+type SecurityHeadersSetting struct {
+	Enabled bool `json:"enabled"`
+	SecurityHeadersPolicy
+	Routes []SecurityHeadersRoute `json:"routes"` // 按路由覆盖，首个匹配的生效
+}
+
+type ErrorSetting struct {
+	ProblemDetails bool   `json:"problemDetails" yaml:"problemDetails"` // 以 RFC 7807 application/problem+json 返回错误
+	TypeBase       string `json:"typeBase" yaml:"typeBase"`             // problem type 前缀，后接错误码；为空时为 about:blank
+}
+
+type WebServiceSetting struct {
+	RequestId       RequestIdSetting       `json:"requestId" yaml:"requestId"`
+	ClientIp        ClientIpSetting        `json:"clientIp" yaml:"clientIp"`
+	AccessLog       AccessLogSetting       `json:"accessLog" yaml:"accessLog"`
+	RateLimit       RateLimitSetting       `json:"rateLimit" yaml:"rateLimit"`
+	Cors            CorsSetting            `json:"cors"`
+	SecurityHeaders SecurityHeadersSetting `json:"securityHeaders" yaml:"securityHeaders"`
+	Error           ErrorSetting           `json:"error"`
+}
+
+var Setting *WebServiceSetting = &WebServiceSetting{
+	RequestId: RequestIdSetting{
+		Enabled: true,
+		Header:  "X-Request-Id",
+	},
+	ClientIp: ClientIpSetting{
+		TrustedProxies: []string{"127.0.0.1", "::1"},
+		Headers:        []string{"X-Forwarded-For", "X-Real-IP"},
+	},
+	AccessLog: AccessLogSetting{
+		Enabled:       true,
+		ExcludePaths:  []string{"/health/**"},
+		SlowThreshold: time.Second * 3,
+		MaskParams:    []string{"access_token", "refresh_token", "token", "client_secret", "password", "secret", "code"},
+	},
+	Cors: CorsSetting{
+		Enabled:       false,
+		AllowOrigins:  []string{"*"},
+		AllowMethods:  []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
+		ExposeHeaders: []string{"Content-Length", "Content-Disposition", "X-Total-Count", "X-Request-Id", "X-Trace-Id"},
+		MaxAge:        time.Hour * 12,
+	},
+	SecurityHeaders: SecurityHeadersSetting{
+		Enabled: true,
+		SecurityHeadersPolicy: SecurityHeadersPolicy{
+			StrictTransportSecurity: "max-age=31536000; includeSubDomains",
+			ContentTypeOptions:      "nosniff",
+			FrameOptions:            "SAMEORIGIN",
+			ReferrerPolicy:          "strict-origin-when-cross-origin",
+		},
+	},
+	RateLimit: RateLimitSetting{
+		Enabled:      false,
+		Backend:      RATE_LIMIT_BACKEND_MEMORY,
+		KeyPrefix:    "ratelimit:",
+		ApiKeyHeader: "X-Api-Key",
+		Headers:      true,
+	},
+}
+
+func init() {
+	logger.Debug("Register WebService Config")
+	config.RegisterConfig("webservice", Setting, "WebService Settings")
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gophab/gophrame/core/logger"
+	SecurityModel "github.com/gophab/gophrame/core/security/model"
+	"github.com/gophab/gophrame/core/webservice/config"
+)
+
+func accessLogExcluded(path string) bool {
+	for _, exclude := range config.Setting.AccessLog.ExcludePaths {
+		if matchPath(exclude, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// 隐藏敏感 query 参数的取值，保留参数顺序及其他参数原文
+func maskQuery(rawQuery string) string {
+	if rawQuery == "" || len(config.Setting.AccessLog.MaskParams) == 0 {
+		return rawQuery
+	}
+
+	parts := strings.Split(rawQuery, "&")
+	for i, part := range parts {
+		key, _, found := strings.Cut(part, "=")
+		if !found {
+			continue
+		}
+		name := key
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			name = unescaped
+		}
+		for _, param := range config.Setting.AccessLog.MaskParams {
+			if strings.EqualFold(name, param) {
+				parts[i] = key + "=***"
+				break
+			}
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// 当前请求的租户：优先使用已解析的租户，其次取已加载的当前用户，不额外查询
+func currentTenantId(c *gin.Context) string {
+	if tenantId := c.GetString("_CURRENT_TENANT_ID_"); tenantId != "" {
+		return tenantId
+	}
+	if user, ok := c.Value("_CURRENT_USER_").(*SecurityModel.UserDetails); ok && user != nil && user.TenantId != nil {
+		return *user.TenantId
+	}
+	return ""
+}
+
+// 结构化访问日志，请求 ID 及链路 ID 由日志上下文字段输出
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if accessLogExcluded(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		begin := time.Now()
+		path := c.Request.URL.Path
+		if c.Request.URL.RawQuery != "" {
+			path = path + "?" + maskQuery(c.Request.URL.RawQuery)
+		}
+
+		c.Next()
+
+		latency := time.Since(begin)
+		status := c.Writer.Status()
+		message := fmt.Sprintf("method=%s path=%q status=%d latency=%s size=%d ip=%s user_id=%s tenant_id=%s user_agent=%q",
+			c.Request.Method,
+			path,
+			status,
+			latency,
+			c.Writer.Size(),
+			c.ClientIP(),
+			c.GetString("_CURRENT_USER_ID_"),
+			currentTenantId(c),
+			c.Request.UserAgent(),
+		)
+		if len(c.Errors) > 0 {
+			message = message + fmt.Sprintf(" error=%q", c.Errors.String())
+		}
+
+		log := logger.WithContext(c)
+		switch {
+		case status >= http.StatusInternalServerError:
+			log.Error("[ACCESS]", message)
+		case config.Setting.AccessLog.SlowThreshold > 0 && latency > config.Setting.AccessLog.SlowThreshold:
+			log.Warn("[ACCESS]", message)
+		default:
+			log.Info("[ACCESS]", message)
+		}
+	}
+}
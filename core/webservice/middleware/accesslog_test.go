@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/gophab/gophrame/core/webservice/config"
+)
+
+func TestAccessLogExcluded(t *testing.T) {
+	saved := config.Setting.AccessLog.ExcludePaths
+	defer func() { config.Setting.AccessLog.ExcludePaths = saved }()
+	config.Setting.AccessLog.ExcludePaths = []string{"/health/**"}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/health", true},
+		{"/health/live", true},
+		{"/health/ready", true},
+		{"/healthz", false},
+		{"/api/health", false},
+	}
+
+	for _, tt := range tests {
+		if got := accessLogExcluded(tt.path); got != tt.want {
+			t.Errorf("accessLogExcluded(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestMaskQuery(t *testing.T) {
+	saved := config.Setting.AccessLog.MaskParams
+	defer func() { config.Setting.AccessLog.MaskParams = saved }()
+	config.Setting.AccessLog.MaskParams = []string{"access_token", "client_secret"}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", ""},
+		{"page=1&size=10", "page=1&size=10"},
+		{"access_token=abc.def", "access_token=***"},
+		{"page=1&access_token=abc&size=10", "page=1&access_token=***&size=10"},
+		{"ACCESS_TOKEN=abc", "ACCESS_TOKEN=***"},
+		{"client%5Fsecret=abc", "client%5Fsecret=***"},
+		{"access_token=a&access_token=b", "access_token=***&access_token=***"},
+		{"access_token", "access_token"},
+	}
+
+	for _, tt := range tests {
+		if got := maskQuery(tt.query); got != tt.want {
+			t.Errorf("maskQuery(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
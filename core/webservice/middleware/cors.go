@@ -2,99 +2,123 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
-	"github.com/gophab/gophrame/core/engine"
-	"github.com/gophab/gophrame/core/global"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/webservice/config"
 )
 
+// 启用跨域处理，中间件已在初始化时注册
 func UseCors() {
-	engine.Get().Use(Cors())
+	config.Setting.Cors.Enabled = true
 }
 
-// Cors 直接放行所有跨域请求并放行所有 OPTIONS 方法
+// 按配置处理跨域请求，未启用时直接放行
 func Cors() gin.HandlerFunc {
 	return handleCors
 }
 
-// CorsByRules 按照配置处理跨域请求
+// Deprecated: 使用 Cors
 func CorsByRules() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		if global.Cors.Mode == "allow-all" {
-			// 放行全部
-			handleCors(c)
-			return
-		} else {
-			// 按WhiteList设定
-			handleCorsWithRule(c)
-			return
-		}
-	}
+	return handleCors
 }
 
-func handleCors(c *gin.Context) {
-	method := c.Request.Method
+// 来源匹配：* 匹配全部，https://*.example.com 匹配任意子域名（不含 example.com 本身）
+func matchOrigin(pattern, origin string) bool {
+	if pattern == "*" || strings.EqualFold(pattern, origin) {
+		return true
+	}
 
-	// 这是允许访问所有域
-	c.Header("Access-Control-Allow-Origin", "*")
+	if i := strings.Index(pattern, "*."); i >= 0 {
+		prefix, suffix := pattern[:i], pattern[i+1:]
+		return len(origin) > len(prefix)+len(suffix) &&
+			strings.HasPrefix(strings.ToLower(origin), strings.ToLower(prefix)) &&
+			strings.HasSuffix(strings.ToLower(origin), strings.ToLower(suffix))
+	}
+	return false
+}
 
-	//服务器支持的所有跨域请求的方法,为了避免浏览次请求的多次'预检'请求
-	c.Header("Access-Control-Allow-Methods", "POST, GET, PUT, DELETE, PATCH, OPTIONS, UPDATE")
+// 检查配置：* 与 allowCredentials 同时配置时，* 匹配的来源不返回凭证许可
+func checkCors() {
+	setting := &config.Setting.Cors
+	if !setting.AllowCredentials {
+		return
+	}
+	for _, pattern := range setting.AllowOrigins {
+		if pattern == "*" {
+			logger.Warn("[CORS] allowOrigins \"*\" with allowCredentials: credentials are not allowed for wildcard origins, list trusted origins explicitly")
+			return
+		}
+	}
+}
 
-	//  header的类型
-	c.Header("Access-Control-Allow-Headers", "Access-Control-Allow-Headers, Authorization, Content-Length, X-CSRF-Token, Token, session, X_Requested_With,Accept, Origin, Host, Connection, Accept-Encoding, Accept-Language, DNT, X-CustomHeader, Keep-Alive, User-Agent, X-Requested-With, If-Modified-Since, Cache-Control, Content-Type, Pragma, Captcha, X-Verification-Code, X-Authorization-Code, X-App-Id")
+func allowedOrigin(origin string) (allowed bool, wildcard bool) {
+	for _, pattern := range config.Setting.Cors.AllowOrigins {
+		if matchOrigin(pattern, origin) {
+			return true, pattern == "*"
+		}
+	}
+	return false, false
+}
 
-	//  允许跨域设置, 可以返回其他子段
-	c.Header("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers,Cache-Control,Content-Language,Content-Type,Expires,Last-Modified, Pragma, FooBar, X-Total-Count") // 跨域关键设置 让浏览器可以解析
+func handleCors(c *gin.Context) {
+	setting := &config.Setting.Cors
+	origin := c.GetHeader("Origin")
+	if !setting.Enabled || origin == "" {
+		c.Next()
+		return
+	}
 
-	//  跨域请求是否需要带cookie信息 默认设置为true
-	c.Header("Access-Control-Allow-Credentials", "true")
+	preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
 
-	// 放行所有OPTIONS方法
-	if method == "OPTIONS" {
-		c.AbortWithStatus(http.StatusAccepted)
+	// 响应随 Origin 变化，避免缓存将一个来源的响应返回给其他来源
+	c.Writer.Header().Add("Vary", "Origin")
+	if preflight {
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
 	}
-	// 处理请求
-	c.Next()
-}
-
-func handleCorsWithRule(c *gin.Context) {
-	whitelist := checkCors(c.GetHeader("origin"))
 
-	// 通过检查, 添加请求头
-	if whitelist != nil {
-		c.Header("Access-Control-Allow-Origin", whitelist.AllowOrigin)
-		c.Header("Access-Control-Allow-Headers", whitelist.AllowHeaders)
-		c.Header("Access-Control-Allow-Methods", whitelist.AllowMethods)
-		c.Header("Access-Control-Expose-Headers", whitelist.ExposeHeaders)
-		if whitelist.AllowCredentials {
-			c.Header("Access-Control-Allow-Credentials", "true")
+	allowed, wildcard := allowedOrigin(origin)
+	if !allowed {
+		if preflight || setting.Strict {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
 		}
+		// 非严格模式不返回跨域头，由浏览器拦截
+		c.Next()
+		return
 	}
 
-	// 严格白名单模式且未通过检查，直接拒绝处理请求
-	if whitelist == nil && global.Cors.Mode == "strict-whitelist" && !(c.Request.Method == "GET" && c.Request.URL.Path == "/health") {
-		c.AbortWithStatus(http.StatusForbidden)
-		return
+	// * 匹配的来源不允许携带凭证：回传任意来源并允许凭证等同于任何站点都可发起带凭证的请求
+	if wildcard {
+		c.Header("Access-Control-Allow-Origin", "*")
 	} else {
-		// 非严格白名单模式，无论是否通过检查均放行所有 OPTIONS 方法
-		if c.Request.Method == http.MethodOptions {
-			c.AbortWithStatus(http.StatusNoContent)
-			return
+		c.Header("Access-Control-Allow-Origin", origin)
+		if setting.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
 		}
 	}
 
-	// 处理请求
-	c.Next()
-}
-
-func checkCors(currentOrigin string) *global.CORSWhitelist {
-	for _, whitelist := range global.Cors.Whitelist {
-		// 遍历配置中的跨域头，寻找匹配项
-		if currentOrigin == whitelist.AllowOrigin {
-			return &whitelist
+	if !preflight {
+		if len(setting.ExposeHeaders) > 0 {
+			c.Header("Access-Control-Expose-Headers", strings.Join(setting.ExposeHeaders, ", "))
 		}
+		c.Next()
+		return
+	}
+
+	// 预检请求直接返回
+	c.Header("Access-Control-Allow-Methods", strings.Join(setting.AllowMethods, ", "))
+	if len(setting.AllowHeaders) > 0 {
+		c.Header("Access-Control-Allow-Headers", strings.Join(setting.AllowHeaders, ", "))
+	} else if headers := c.GetHeader("Access-Control-Request-Headers"); headers != "" {
+		c.Header("Access-Control-Allow-Headers", headers)
+	}
+	if setting.MaxAge > 0 {
+		c.Header("Access-Control-Max-Age", strconv.FormatInt(int64(setting.MaxAge.Seconds()), 10))
 	}
-	return nil
+	c.AbortWithStatus(http.StatusNoContent)
 }
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gophab/gophrame/core/webservice/config"
+)
+
+func TestMatchOrigin(t *testing.T) {
+	tests := []struct {
+		pattern string
+		origin  string
+		want    bool
+	}{
+		{"*", "https://any.site", true},
+		{"https://example.com", "https://example.com", true},
+		{"https://example.com", "HTTPS://EXAMPLE.COM", true},
+		{"https://example.com", "http://example.com", false},
+		{"https://example.com", "https://example.com.evil.com", false},
+		{"https://*.example.com", "https://app.example.com", true},
+		{"https://*.example.com", "https://a.b.example.com", true},
+		{"https://*.example.com", "https://APP.Example.com", true},
+		{"https://*.example.com", "https://example.com", false},
+		{"https://*.example.com", "https://.example.com", false},
+		{"https://*.example.com", "https://evilexample.com", false},
+		{"https://*.example.com", "http://app.example.com", false},
+		{"https://*.example.com", "https://app.example.com.evil.com", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchOrigin(tt.pattern, tt.origin); got != tt.want {
+			t.Errorf("matchOrigin(%q, %q) = %v, want %v", tt.pattern, tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestCors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	saved := config.Setting.Cors
+	defer func() { config.Setting.Cors = saved }()
+
+	tests := []struct {
+		name            string
+		origins         []string
+		credentials     bool
+		strict          bool
+		method          string
+		origin          string
+		wantStatus      int
+		wantOrigin      string
+		wantCredentials string
+	}{
+		{name: "wildcard", origins: []string{"*"}, method: http.MethodGet, origin: "https://any.site", wantStatus: http.StatusOK, wantOrigin: "*"},
+		{name: "wildcard never allows credentials", origins: []string{"*"}, credentials: true, method: http.MethodGet, origin: "https://any.site", wantStatus: http.StatusOK, wantOrigin: "*"},
+		{name: "explicit origin with credentials", origins: []string{"https://app.example.com", "*"}, credentials: true, method: http.MethodGet, origin: "https://app.example.com", wantStatus: http.StatusOK, wantOrigin: "https://app.example.com", wantCredentials: "true"},
+		{name: "subdomain with credentials", origins: []string{"https://*.example.com"}, credentials: true, method: http.MethodGet, origin: "https://app.example.com", wantStatus: http.StatusOK, wantOrigin: "https://app.example.com", wantCredentials: "true"},
+		{name: "other origin falls back to wildcard", origins: []string{"https://app.example.com", "*"}, credentials: true, method: http.MethodGet, origin: "https://any.site", wantStatus: http.StatusOK, wantOrigin: "*"},
+		{name: "not allowed", origins: []string{"https://app.example.com"}, method: http.MethodGet, origin: "https://any.site", wantStatus: http.StatusOK},
+		{name: "not allowed strict", origins: []string{"https://app.example.com"}, strict: true, method: http.MethodGet, origin: "https://any.site", wantStatus: http.StatusForbidden},
+		{name: "preflight", origins: []string{"https://app.example.com"}, credentials: true, method: http.MethodOptions, origin: "https://app.example.com", wantStatus: http.StatusNoContent, wantOrigin: "https://app.example.com", wantCredentials: "true"},
+		{name: "preflight not allowed", origins: []string{"https://app.example.com"}, method: http.MethodOptions, origin: "https://any.site", wantStatus: http.StatusForbidden},
+		{name: "same origin request", origins: []string{"https://app.example.com"}, strict: true, method: http.MethodGet, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Setting.Cors = config.CorsSetting{
+				Enabled:          true,
+				AllowOrigins:     tt.origins,
+				AllowMethods:     []string{"GET", "POST"},
+				AllowCredentials: tt.credentials,
+				MaxAge:           time.Hour,
+				Strict:           tt.strict,
+			}
+
+			engine := gin.New()
+			engine.Use(Cors())
+			engine.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			request := httptest.NewRequest(tt.method, "/", nil)
+			if tt.origin != "" {
+				request.Header.Set("Origin", tt.origin)
+			}
+			if tt.method == http.MethodOptions {
+				request.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			recorder := httptest.NewRecorder()
+			engine.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if got := recorder.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Fatalf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := recorder.Header().Get("Access-Control-Allow-Credentials"); got != tt.wantCredentials {
+				t.Fatalf("Access-Control-Allow-Credentials = %q, want %q", got, tt.wantCredentials)
+			}
+			if tt.method == http.MethodOptions && tt.wantStatus == http.StatusNoContent {
+				if got := recorder.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST" {
+					t.Fatalf("Access-Control-Allow-Methods = %q", got)
+				}
+				if got := recorder.Header().Get("Access-Control-Max-Age"); got != "3600" {
+					t.Fatalf("Access-Control-Max-Age = %q", got)
+				}
+			}
+		})
+	}
+}
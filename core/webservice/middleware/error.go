@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gophab/gophrame/core/webservice/response"
+	"github.com/gophab/gophrame/errors"
+)
+
+// 统一渲染处理函数通过 c.Error(err) 记录但未写出响应的错误
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			response.AbortWithError(c, c.Errors.Last().Err)
+		}
+	}
+}
+
+// panic 按统一错误格式返回 500，堆栈由 gin 记录
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		response.AbortWithError(c, errors.ErrInternal.WithCause(fmt.Errorf("panic: %v", recovered)))
+	})
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/url"
+
+	"github.com/gophab/gophrame/core/feign"
+	"github.com/gophab/gophrame/core/webservice/config"
+	"github.com/gophab/gophrame/core/webservice/request"
+)
+
+// 将当前请求 ID 传递给下游服务
+type FeignRequestIdInterceptor struct{}
+
+func (in *FeignRequestIdInterceptor) Do(chain *feign.FeignClientInterceptorChain, method string, urlPath string, urlValues url.Values, bodyValue interface{}, options ...*feign.RequestOptions) *feign.FeignClient {
+	requestId := request.RequestId(chain.Context())
+	if !config.Setting.RequestId.Enabled || requestId == "" {
+		return chain.Next(method, urlPath, urlValues, bodyValue, options...)
+	}
+
+	// 复制请求选项，避免修改共享的默认选项
+	option := &feign.RequestOptions{
+		ContentType: feign.DefaultRequestOptions.ContentType,
+		Headers:     make(map[string]string),
+	}
+	if len(options) > 0 && options[0] != nil {
+		option.ContentType = options[0].ContentType
+		for k, v := range options[0].Headers {
+			option.Headers[k] = v
+		}
+	}
+	if _, ok := option.Headers[config.Setting.RequestId.Header]; !ok {
+		option.Headers[config.Setting.RequestId.Header] = requestId
+	}
+
+	return chain.Next(method, urlPath, urlValues, bodyValue, option)
+}
+
+func init() {
+	feign.RegisterGlobalFeignClientInterceptorEx(&FeignRequestIdInterceptor{}, -70)
+}
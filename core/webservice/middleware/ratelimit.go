@@ -0,0 +1,295 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/webservice/config"
+	"github.com/gophab/gophrame/core/webservice/response"
+	"github.com/gophab/gophrame/errors"
+)
+
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // 额度完全恢复（令牌桶）或当前窗口结束（滑动窗口）的时间
+	RetryAfter time.Duration // 被拒绝时建议的重试等待时间
+}
+
+// 限流计数后端
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, rule *config.RateLimitRule) (*RateLimitResult, error)
+}
+
+// 从请求中提取限流维度的值，返回空字符串时该规则不生效
+type KeyExtractor func(c *gin.Context) string
+
+var (
+	keyExtractors      = make(map[string]KeyExtractor)
+	keyExtractorsMutex sync.RWMutex
+)
+
+// 注册自定义限流维度，规则中 key 配置为 name 即可使用
+func RegisterKeyExtractor(name string, extractor KeyExtractor) {
+	keyExtractorsMutex.Lock()
+	defer keyExtractorsMutex.Unlock()
+	keyExtractors[name] = extractor
+}
+
+func GetKeyExtractor(name string) KeyExtractor {
+	keyExtractorsMutex.RLock()
+	defer keyExtractorsMutex.RUnlock()
+	return keyExtractors[name]
+}
+
+func hash(value string) string {
+	sum := sha1.Sum([]byte(value))
+	return hex.EncodeToString(sum[:8])
+}
+
+func ipKey(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// 已认证请求按用户区分，其余按 IP 区分；未校验的令牌不作为维度，否则每次更换伪造令牌即可获得新的额度
+func userKey(c *gin.Context) string {
+	if userId := c.GetString("_CURRENT_USER_ID_"); userId != "" {
+		return "user:" + userId
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// 客户端 ID：Basic 认证用户名或 client_id 查询参数，未提供时按 IP 区分；
+// 不读取请求体，避免在鉴权前解析整个表单/multipart 请求
+func clientKey(c *gin.Context) string {
+	if clientId, _, ok := c.Request.BasicAuth(); ok && clientId != "" {
+		return "client:" + clientId
+	}
+	if clientId := c.Query("client_id"); clientId != "" {
+		return "client:" + clientId
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func apiKey(c *gin.Context) string {
+	if key := c.GetHeader(config.Setting.RateLimit.ApiKeyHeader); key != "" {
+		return hash(key)
+	}
+	return ""
+}
+
+func globalKey(c *gin.Context) string {
+	return "*"
+}
+
+func init() {
+	RegisterKeyExtractor(config.RATE_LIMIT_KEY_IP, ipKey)
+	RegisterKeyExtractor(config.RATE_LIMIT_KEY_USER, userKey)
+	RegisterKeyExtractor(config.RATE_LIMIT_KEY_CLIENT, clientKey)
+	RegisterKeyExtractor(config.RATE_LIMIT_KEY_APIKEY, apiKey)
+	RegisterKeyExtractor(config.RATE_LIMIT_KEY_GLOBAL, globalKey)
+}
+
+// 路径匹配：以 /** 结尾为前缀匹配，其余按 path.Match 匹配
+func matchPath(pattern, urlPath string) bool {
+	if prefix := strings.TrimSuffix(pattern, "/**"); prefix != pattern {
+		return urlPath == prefix || strings.HasPrefix(urlPath, prefix+"/")
+	}
+	matched, _ := path.Match(pattern, urlPath)
+	return matched
+}
+
+func matchRule(rule *config.RateLimitRule, method, urlPath string) bool {
+	if rule.Limit <= 0 || rule.Window <= 0 || !matchPath(rule.Path, urlPath) {
+		return false
+	}
+	if len(rule.Methods) == 0 {
+		return true
+	}
+	for _, m := range rule.Methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func ruleName(rule *config.RateLimitRule) string {
+	if rule.Name != "" {
+		return rule.Name
+	}
+	return rule.Path
+}
+
+func capacity(rule *config.RateLimitRule) int {
+	if rule.Algorithm == config.RATE_LIMIT_TOKEN_BUCKET && rule.Burst > 0 {
+		return rule.Burst
+	}
+	return rule.Limit
+}
+
+// 令牌桶：容量 Burst，每 Window 补充 Limit 个令牌
+func tokenBucketResult(rule *config.RateLimitRule, tokens float64, allowed bool) *RateLimitResult {
+	burst := capacity(rule)
+	interval := float64(rule.Window) / float64(rule.Limit) // 每个令牌的补充间隔
+
+	result := &RateLimitResult{
+		Allowed:   allowed,
+		Limit:     burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(burst) - tokens) * interval),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) * interval)
+	}
+	return result
+}
+
+// 滑动窗口计数：上一窗口计数按剩余比例加权，elapsed 为当前窗口已过时间
+func slidingWindowResult(rule *config.RateLimitRule, elapsed time.Duration, current, previous int, allowed bool) *RateLimitResult {
+	weight := 1 - float64(elapsed)/float64(rule.Window)
+	estimate := int(math.Ceil(float64(previous)*weight)) + current
+
+	result := &RateLimitResult{
+		Allowed:   allowed,
+		Limit:     rule.Limit,
+		Remaining: rule.Limit - estimate,
+		Reset:     rule.Window - elapsed,
+	}
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+	if !allowed {
+		if current+1 > rule.Limit || previous == 0 {
+			result.RetryAfter = rule.Window - elapsed
+		} else {
+			// 上一窗口计数衰减到可再容纳一个请求的时间
+			f := 1 - float64(rule.Limit-current-1)/float64(previous)
+			result.RetryAfter = time.Duration(f*float64(rule.Window)) - elapsed
+		}
+	}
+	return result
+}
+
+var (
+	rateLimiter      RateLimiter
+	rateLimiterMutex sync.Mutex
+)
+
+func SetRateLimiter(limiter RateLimiter) {
+	rateLimiterMutex.Lock()
+	defer rateLimiterMutex.Unlock()
+	rateLimiter = limiter
+}
+
+func GetRateLimiter() RateLimiter {
+	rateLimiterMutex.Lock()
+	defer rateLimiterMutex.Unlock()
+
+	if rateLimiter == nil {
+		switch config.Setting.RateLimit.Backend {
+		case config.RATE_LIMIT_BACKEND_REDIS:
+			rateLimiter = NewRedisRateLimiter(config.Setting.RateLimit.Database, config.Setting.RateLimit.KeyPrefix)
+		default:
+			rateLimiter = NewMemoryRateLimiter()
+		}
+	}
+	return rateLimiter
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+// 本次请求已返回的最严格额度，全局与路由组两级限流共用
+const rateLimitResultKey = "_RATELIMIT_RESULT_"
+
+// 按配置规则限流，超限返回 429；计数后端异常时放行。
+// user 维度需在令牌校验之后才能识别用户，由路由组挂载的 UserRateLimit 处理
+func RateLimit() gin.HandlerFunc {
+	return rateLimit(func(rule *config.RateLimitRule) bool {
+		return rule.Key != config.RATE_LIMIT_KEY_USER
+	})
+}
+
+// 按 user 维度的规则限流，挂载在路由组的令牌校验（security.HandleTokenVerify/CheckTokenVerify）之后：
+// 已认证请求按用户计数，其余按 IP 计数
+func UserRateLimit() gin.HandlerFunc {
+	return rateLimit(func(rule *config.RateLimitRule) bool {
+		return rule.Key == config.RATE_LIMIT_KEY_USER
+	})
+}
+
+func rateLimit(filter func(rule *config.RateLimitRule) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		setting := &config.Setting.RateLimit
+		if !setting.Enabled {
+			c.Next()
+			return
+		}
+
+		limiter := GetRateLimiter()
+
+		var limited *RateLimitResult
+		if value, exists := c.Get(rateLimitResultKey); exists {
+			limited, _ = value.(*RateLimitResult)
+		}
+
+		rules := setting.EffectiveRules()
+		for i := range rules {
+			rule := &rules[i]
+			if !filter(rule) || !matchRule(rule, c.Request.Method, c.Request.URL.Path) {
+				continue
+			}
+
+			extractor := GetKeyExtractor(rule.Key)
+			if extractor == nil {
+				logger.Warn("[RATELIMIT] Unknown key extractor: ", rule.Key)
+				continue
+			}
+			value := extractor(c)
+			if value == "" {
+				continue
+			}
+
+			result, err := limiter.Allow(c.Request.Context(), ruleName(rule)+":"+rule.Key+":"+value, rule)
+			if err != nil {
+				logger.WithContext(c).Warn("[RATELIMIT] Rate limiter error: ", err.Error())
+				continue
+			}
+
+			// 返回最严格规则的额度
+			if limited == nil || !result.Allowed || (limited.Allowed && result.Remaining < limited.Remaining) {
+				limited = result
+				c.Set(rateLimitResultKey, result)
+				if setting.Headers {
+					c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+					c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+					c.Header("RateLimit-Reset", seconds(result.Reset))
+					c.Header("RateLimit-Policy", strconv.Itoa(rule.Limit)+";w="+seconds(rule.Window))
+				}
+			}
+
+			if !result.Allowed {
+				logger.WithContext(c).Warn("[RATELIMIT] Too many requests:", ruleName(rule), rule.Key, value)
+				c.Header("Retry-After", seconds(result.RetryAfter))
+				response.ErrorCode(c, http.StatusTooManyRequests, errors.ERROR_TOO_MANY_REQUESTS)
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
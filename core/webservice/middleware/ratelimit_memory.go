@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gophab/gophrame/core/webservice/config"
+)
+
+type memoryRateLimitEntry struct {
+	// 令牌桶
+	tokens float64
+	last   time.Time
+
+	// 滑动窗口
+	window   int64
+	current  int
+	previous int
+
+	expire time.Time
+}
+
+// 进程内计数，仅限单实例
+type MemoryRateLimiter struct {
+	entries   map[string]*memoryRateLimitEntry
+	lastSweep time.Time
+	mutex     sync.Mutex
+}
+
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{
+		entries:   make(map[string]*memoryRateLimitEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+func (m *MemoryRateLimiter) Allow(ctx context.Context, key string, rule *config.RateLimitRule) (*RateLimitResult, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	m.sweep(now)
+
+	entry := m.entries[key]
+	if entry == nil {
+		entry = &memoryRateLimitEntry{tokens: float64(capacity(rule)), last: now}
+		m.entries[key] = entry
+	}
+	entry.expire = now.Add(rule.Window * 2)
+
+	if rule.Algorithm == config.RATE_LIMIT_TOKEN_BUCKET {
+		return m.tokenBucket(entry, rule, now), nil
+	}
+	return m.slidingWindow(entry, rule, now), nil
+}
+
+func (m *MemoryRateLimiter) tokenBucket(entry *memoryRateLimitEntry, rule *config.RateLimitRule, now time.Time) *RateLimitResult {
+	if elapsed := now.Sub(entry.last); elapsed > 0 {
+		entry.tokens = math.Min(float64(capacity(rule)), entry.tokens+float64(elapsed)*float64(rule.Limit)/float64(rule.Window))
+		entry.last = now
+	}
+
+	allowed := entry.tokens >= 1
+	if allowed {
+		entry.tokens--
+	}
+	return tokenBucketResult(rule, entry.tokens, allowed)
+}
+
+func (m *MemoryRateLimiter) slidingWindow(entry *memoryRateLimitEntry, rule *config.RateLimitRule, now time.Time) *RateLimitResult {
+	window := now.UnixNano() / int64(rule.Window)
+	if window != entry.window {
+		if window == entry.window+1 {
+			entry.previous = entry.current
+		} else {
+			entry.previous = 0
+		}
+		entry.current = 0
+		entry.window = window
+	}
+
+	elapsed := time.Duration(now.UnixNano() - window*int64(rule.Window))
+	weight := 1 - float64(elapsed)/float64(rule.Window)
+
+	allowed := float64(entry.previous)*weight+float64(entry.current)+1 <= float64(rule.Limit)
+	if allowed {
+		entry.current++
+	}
+	return slidingWindowResult(rule, elapsed, entry.current, entry.previous, allowed)
+}
+
+// 每分钟清理一次过期计数
+func (m *MemoryRateLimiter) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < time.Minute {
+		return
+	}
+	m.lastSweep = now
+
+	for key, entry := range m.entries {
+		if now.After(entry.expire) {
+			delete(m.entries, key)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gophab/gophrame/core/redis"
+	"github.com/gophab/gophrame/core/webservice/config"
+)
+
+const (
+	// KEYS[1] 令牌桶；ARGV: 每毫秒补充令牌数、容量、当前毫秒时间、过期毫秒
+	tokenBucketScript = `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+if now > ts then
+	tokens = math.min(burst, tokens + (now - ts) * rate)
+	ts = now
+end
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(ts))
+redis.call("PEXPIRE", KEYS[1], ARGV[4])
+return {allowed, tostring(tokens)}`
+
+	// KEYS[1] 当前窗口计数、KEYS[2] 上一窗口计数；ARGV: 限额、上一窗口权重、过期毫秒
+	slidingWindowScript = `
+local limit = tonumber(ARGV[1])
+local weight = tonumber(ARGV[2])
+local current = tonumber(redis.call("GET", KEYS[1]) or "0")
+local previous = tonumber(redis.call("GET", KEYS[2]) or "0")
+if previous * weight + current + 1 > limit then
+	return {0, current, previous}
+end
+current = redis.call("INCR", KEYS[1])
+redis.call("PEXPIRE", KEYS[1], ARGV[3])
+return {1, current, previous}`
+)
+
+// 基于 Redis 的集群共享计数，同一限流键使用 hash tag 保证集群模式下位于同一槽位
+type RedisRateLimiter struct {
+	database  int
+	keyPrefix string
+}
+
+func NewRedisRateLimiter(database int, keyPrefix string) *RedisRateLimiter {
+	return &RedisRateLimiter{database: database, keyPrefix: keyPrefix}
+}
+
+func (r *RedisRateLimiter) Allow(ctx context.Context, key string, rule *config.RateLimitRule) (*RateLimitResult, error) {
+	client := redis.GetOneRedisClientIndex(r.database)
+	if client == nil {
+		return nil, errors.New("redis not available")
+	}
+	defer client.ReleaseOneRedisClient()
+
+	key = r.keyPrefix + "{" + key + "}"
+	if rule.Algorithm == config.RATE_LIMIT_TOKEN_BUCKET {
+		return r.tokenBucket(client, key, rule)
+	}
+	return r.slidingWindow(client, key, rule)
+}
+
+func (r *RedisRateLimiter) tokenBucket(client *redis.RedisClient, key string, rule *config.RateLimitRule) (*RateLimitResult, error) {
+	rate := float64(rule.Limit) / float64(rule.Window.Milliseconds())
+	values, err := client.Values(client.Execute("EVAL", tokenBucketScript, 1, key,
+		strconv.FormatFloat(rate, 'f', -1, 64),
+		capacity(rule),
+		time.Now().UnixMilli(),
+		(rule.Window * 2).Milliseconds(),
+	))
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 2 {
+		return nil, errors.New("unexpected rate limit script result")
+	}
+
+	allowed, _ := values[0].(int64)
+	remaining, _ := values[1].([]byte)
+	tokens, err := strconv.ParseFloat(string(remaining), 64)
+	if err != nil {
+		return nil, err
+	}
+	return tokenBucketResult(rule, tokens, allowed == 1), nil
+}
+
+func (r *RedisRateLimiter) slidingWindow(client *redis.RedisClient, key string, rule *config.RateLimitRule) (*RateLimitResult, error) {
+	now := time.Now().UnixNano()
+	window := now / int64(rule.Window)
+	elapsed := time.Duration(now - window*int64(rule.Window))
+	weight := 1 - float64(elapsed)/float64(rule.Window)
+
+	values, err := client.Values(client.Execute("EVAL", slidingWindowScript, 2,
+		key+":"+strconv.FormatInt(window, 10),
+		key+":"+strconv.FormatInt(window-1, 10),
+		rule.Limit,
+		strconv.FormatFloat(weight, 'f', -1, 64),
+		(rule.Window * 2).Milliseconds(),
+	))
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 3 {
+		return nil, errors.New("unexpected rate limit script result")
+	}
+
+	allowed, _ := values[0].(int64)
+	current, _ := values[1].(int64)
+	previous, _ := values[2].(int64)
+	return slidingWindowResult(rule, elapsed, int(current), int(previous), allowed == 1), nil
+}
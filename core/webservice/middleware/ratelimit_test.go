@@ -0,0 +1,281 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gophab/gophrame/core/security"
+	SecurityConfig "github.com/gophab/gophrame/core/security/config"
+	"github.com/gophab/gophrame/core/security/token"
+	TokenConfig "github.com/gophab/gophrame/core/security/token/config"
+	"github.com/gophab/gophrame/core/security/token/jwt"
+	"github.com/gophab/gophrame/core/webservice/config"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/oauth/login", "/oauth/login", true},
+		{"/oauth/login", "/oauth/login/", false},
+		{"/oauth/login", "/oauth/logout", false},
+		{"/openapi/*/code", "/openapi/sms/code", true},
+		{"/openapi/*/code", "/openapi/sms/email/code", false},
+		{"/openapi/**", "/openapi", true},
+		{"/openapi/**", "/openapi/user/info", true},
+		{"/openapi/**", "/openapis", false},
+		{"/openapi/**", "/api/openapi/user", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchPath(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchPath(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestMatchRule(t *testing.T) {
+	rule := config.RateLimitRule{Path: "/oauth/login", Methods: []string{"POST"}, Limit: 10, Window: time.Minute}
+
+	tests := []struct {
+		name   string
+		modify func(*config.RateLimitRule)
+		method string
+		path   string
+		want   bool
+	}{
+		{name: "match", method: "POST", path: "/oauth/login", want: true},
+		{name: "method case insensitive", method: "post", path: "/oauth/login", want: true},
+		{name: "other method", method: "GET", path: "/oauth/login", want: false},
+		{name: "other path", method: "POST", path: "/oauth/token", want: false},
+		{name: "all methods", modify: func(r *config.RateLimitRule) { r.Methods = nil }, method: "GET", path: "/oauth/login", want: true},
+		{name: "zero limit disabled", modify: func(r *config.RateLimitRule) { r.Limit = 0 }, method: "POST", path: "/oauth/login", want: false},
+		{name: "zero window disabled", modify: func(r *config.RateLimitRule) { r.Window = 0 }, method: "POST", path: "/oauth/login", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := rule
+			if tt.modify != nil {
+				tt.modify(&r)
+			}
+			if got := matchRule(&r, tt.method, tt.path); got != tt.want {
+				t.Fatalf("matchRule = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEffectiveRules(t *testing.T) {
+	if rules := (&config.RateLimitSetting{}).EffectiveRules(); len(rules) != len(config.DefaultRateLimitRules) {
+		t.Fatalf("rules not configured: got %d rules, want defaults", len(rules))
+	}
+	if rules := (&config.RateLimitSetting{Rules: []config.RateLimitRule{}}).EffectiveRules(); len(rules) != 0 {
+		t.Fatalf("empty rules configured: got %d rules, want none", len(rules))
+	}
+	custom := []config.RateLimitRule{{Path: "/api/**", Limit: 1, Window: time.Second}}
+	if rules := (&config.RateLimitSetting{Rules: custom}).EffectiveRules(); len(rules) != 1 || rules[0].Path != "/api/**" {
+		t.Fatalf("custom rules configured: got %+v", rules)
+	}
+}
+
+type rateLimitStep struct {
+	at         time.Duration // 相对起始时间
+	allowed    bool
+	remaining  int
+	retryAfter time.Duration
+}
+
+func checkRateLimitStep(t *testing.T, i int, step rateLimitStep, result *RateLimitResult) {
+	t.Helper()
+	if result.Allowed != step.allowed || result.Remaining != step.remaining || result.RetryAfter != step.retryAfter {
+		t.Fatalf("step %d at %v: got allowed=%v remaining=%d retryAfter=%v, want allowed=%v remaining=%d retryAfter=%v",
+			i, step.at, result.Allowed, result.Remaining, result.RetryAfter, step.allowed, step.remaining, step.retryAfter)
+	}
+}
+
+func TestMemoryRateLimiterTokenBucket(t *testing.T) {
+	// 容量 3，每秒补充 1 个令牌
+	rule := &config.RateLimitRule{Algorithm: config.RATE_LIMIT_TOKEN_BUCKET, Limit: 60, Window: time.Minute, Burst: 3}
+	steps := []rateLimitStep{
+		{at: 0, allowed: true, remaining: 2},
+		{at: 0, allowed: true, remaining: 1},
+		{at: 0, allowed: true, remaining: 0},
+		{at: 0, allowed: false, remaining: 0, retryAfter: time.Second},
+		{at: 500 * time.Millisecond, allowed: false, remaining: 0, retryAfter: 500 * time.Millisecond},
+		{at: time.Second, allowed: true, remaining: 0},
+		{at: time.Hour, allowed: true, remaining: 2}, // 补充不超过容量
+	}
+
+	limiter := NewMemoryRateLimiter()
+	start := time.Now()
+	entry := &memoryRateLimitEntry{tokens: float64(capacity(rule)), last: start}
+	for i, step := range steps {
+		checkRateLimitStep(t, i, step, limiter.tokenBucket(entry, rule, start.Add(step.at)))
+	}
+}
+
+func TestMemoryRateLimiterSlidingWindow(t *testing.T) {
+	// 每秒 4 次；上一窗口计数按剩余比例计入
+	rule := &config.RateLimitRule{Algorithm: config.RATE_LIMIT_SLIDING_WINDOW, Limit: 4, Window: time.Second}
+	steps := []rateLimitStep{
+		{at: 0, allowed: true, remaining: 3},
+		{at: 0, allowed: true, remaining: 2},
+		{at: 0, allowed: true, remaining: 1},
+		{at: 0, allowed: true, remaining: 0},
+		{at: 0, allowed: false, remaining: 0, retryAfter: time.Second},
+		// 下一窗口过半，上一窗口 4 次按 0.5 计为 2 次
+		{at: 1500 * time.Millisecond, allowed: true, remaining: 1},
+		{at: 1500 * time.Millisecond, allowed: true, remaining: 0},
+		// 上一窗口计数衰减到 1 次（窗口过去 3/4）后可再容纳一次
+		{at: 1500 * time.Millisecond, allowed: false, remaining: 0, retryAfter: 250 * time.Millisecond},
+		{at: 1750 * time.Millisecond, allowed: true, remaining: 0},
+		// 跳过一个以上窗口后不计上一窗口
+		{at: 3500 * time.Millisecond, allowed: true, remaining: 3},
+	}
+
+	limiter := NewMemoryRateLimiter()
+	start := time.Unix(1000, 0) // 对齐窗口起点
+	entry := &memoryRateLimitEntry{}
+	for i, step := range steps {
+		checkRateLimitStep(t, i, step, limiter.slidingWindow(entry, rule, start.Add(step.at)))
+	}
+}
+
+func TestMemoryRateLimiterAllow(t *testing.T) {
+	limiter := NewMemoryRateLimiter()
+	rule := &config.RateLimitRule{Algorithm: config.RATE_LIMIT_TOKEN_BUCKET, Limit: 1, Window: time.Hour, Burst: 2}
+
+	for i, want := range []bool{true, true, false} {
+		result, err := limiter.Allow(context.Background(), "a", rule)
+		if err != nil {
+			t.Fatalf("Allow error: %v", err)
+		}
+		if result.Allowed != want {
+			t.Fatalf("request %d allowed = %v, want %v", i, result.Allowed, want)
+		}
+	}
+
+	// 不同键独立计数
+	if result, _ := limiter.Allow(context.Background(), "b", rule); !result.Allowed || result.Limit != 2 {
+		t.Fatalf("other key: %+v", result)
+	}
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name      string
+		extractor KeyExtractor
+		setup     func(c *gin.Context)
+		want      string
+	}{
+		{name: "user by ip", extractor: userKey, setup: func(c *gin.Context) { c.Request.Header.Set("Authorization", "Bearer forged") }, want: "ip:192.0.2.1"},
+		{name: "client basic auth", extractor: clientKey, setup: func(c *gin.Context) { c.Request.SetBasicAuth("app", "secret") }, want: "client:app"},
+		{name: "client query", extractor: clientKey, setup: func(c *gin.Context) { c.Request.URL.RawQuery = "client_id=app" }, want: "client:app"},
+		{name: "client by ip", extractor: clientKey, want: "ip:192.0.2.1"},
+		{name: "apikey missing", extractor: apiKey, want: ""},
+		{name: "apikey hashed", extractor: apiKey, setup: func(c *gin.Context) { c.Request.Header.Set("X-Api-Key", "key") }, want: hash("key")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/oauth/token", nil)
+			c.Request.RemoteAddr = "192.0.2.1:1234"
+			if tt.setup != nil {
+				tt.setup(c)
+			}
+			if got := tt.extractor(c); got != tt.want {
+				t.Fatalf("key = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// 签发测试用 JWT 令牌
+func testToken(t *testing.T, userId string) string {
+	t.Helper()
+	claims := &jwt.Claims{}
+	claims.Subject = userId
+	claims.IssuedAt = time.Now().Unix()
+	claims.ExpiresAt = time.Now().Add(time.Hour).Unix()
+	result, err := jwt.GenerateToken(claims)
+	if err != nil {
+		t.Fatalf("GenerateToken error: %v", err)
+	}
+	return result
+}
+
+func TestUserRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	savedRateLimit, savedAuthMode, savedJwt, savedSecret := config.Setting.RateLimit, SecurityConfig.Setting.AuthMode, TokenConfig.Setting.UseJwtToken, jwt.Setting.Secret
+	defer func() {
+		config.Setting.RateLimit = savedRateLimit
+		SecurityConfig.Setting.AuthMode = savedAuthMode
+		TokenConfig.Setting.UseJwtToken = savedJwt
+		jwt.Setting.Secret = savedSecret
+	}()
+	defer SetRateLimiter(nil)
+
+	// 本地校验 JWT 令牌
+	SecurityConfig.Setting.AuthMode = "local"
+	TokenConfig.Setting.UseJwtToken = true
+	jwt.Setting.Secret = "ratelimit-test-secret"
+	token.InitTokenResolver()
+
+	config.Setting.RateLimit.Enabled = true
+	config.Setting.RateLimit.Rules = []config.RateLimitRule{
+		{Name: "user", Path: "/openapi/**", Key: config.RATE_LIMIT_KEY_USER, Algorithm: config.RATE_LIMIT_SLIDING_WINDOW, Limit: 2, Window: time.Hour},
+	}
+	SetRateLimiter(NewMemoryRateLimiter())
+
+	engine := gin.New()
+	engine.Use(RateLimit())
+	group := engine.Group("/openapi", security.HandleTokenVerify(), UserRateLimit())
+	group.GET("/user/info", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(remoteAddr, tokenValue string) int {
+		r := httptest.NewRequest(http.MethodGet, "/openapi/user/info", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("Authorization", "Bearer "+tokenValue)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	alice, bob := testToken(t, "alice"), testToken(t, "bob")
+	steps := []struct {
+		remoteAddr string
+		token      string
+		want       int
+	}{
+		// 同一用户从不同 IP 访问共享额度
+		{"192.0.2.1:1000", alice, http.StatusOK},
+		{"192.0.2.2:1000", alice, http.StatusOK},
+		{"192.0.2.3:1000", alice, http.StatusTooManyRequests},
+		// 同一 IP 上的其他用户单独计数
+		{"192.0.2.1:1000", bob, http.StatusOK},
+		{"192.0.2.1:1000", bob, http.StatusOK},
+		{"192.0.2.1:1000", bob, http.StatusTooManyRequests},
+	}
+	for i, step := range steps {
+		if got := request(step.remoteAddr, step.token); got != step.want {
+			t.Fatalf("step %d: status = %d, want %d", i, got, step.want)
+		}
+	}
+
+	// 未通过令牌校验的请求不计入用户额度
+	if got := request("192.0.2.9:1000", "invalid"); got == http.StatusOK || got == http.StatusTooManyRequests {
+		t.Fatalf("invalid token: status = %d", got)
+	}
+}
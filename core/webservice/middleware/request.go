@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/gophab/gophrame/core/util"
+	"github.com/gophab/gophrame/core/webservice/config"
+	"github.com/gophab/gophrame/core/webservice/request"
+)
+
+// 上游传入的请求 ID 仅接受可见 ASCII 字符，避免日志注入
+func validRequestId(requestId string) bool {
+	if requestId == "" || len(requestId) > 128 {
+		return false
+	}
+	for i := 0; i < len(requestId); i++ {
+		if requestId[i] <= ' ' || requestId[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// 沿用上游请求 ID 或生成新的请求 ID，写入 gin.Context、请求 context 及响应头
+func RequestId() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !config.Setting.RequestId.Enabled {
+			c.Next()
+			return
+		}
+
+		header := config.Setting.RequestId.Header
+		requestId := c.GetHeader(header)
+		if !validRequestId(requestId) {
+			requestId = util.UUID()
+		}
+
+		c.Set(request.REQUEST_ID_KEY, requestId)
+		c.Request = c.Request.WithContext(request.ContextWithRequestId(c.Request.Context(), requestId))
+		c.Header(header, requestId)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/gophab/gophrame/core/webservice/config"
+)
+
+// 路由策略为空时沿用默认值，为 "-" 时不返回
+func mergePolicy(base, override config.SecurityHeadersPolicy) config.SecurityHeadersPolicy {
+	merge := func(b, o string) string {
+		switch o {
+		case "":
+			return b
+		case "-":
+			return ""
+		}
+		return o
+	}
+
+	return config.SecurityHeadersPolicy{
+		StrictTransportSecurity: merge(base.StrictTransportSecurity, override.StrictTransportSecurity),
+		ContentSecurityPolicy:   merge(base.ContentSecurityPolicy, override.ContentSecurityPolicy),
+		ContentTypeOptions:      merge(base.ContentTypeOptions, override.ContentTypeOptions),
+		FrameOptions:            merge(base.FrameOptions, override.FrameOptions),
+		ReferrerPolicy:          merge(base.ReferrerPolicy, override.ReferrerPolicy),
+		PermissionsPolicy:       merge(base.PermissionsPolicy, override.PermissionsPolicy),
+	}
+}
+
+func writeSecurityHeaders(c *gin.Context, policy config.SecurityHeadersPolicy) {
+	headers := map[string]string{
+		"Strict-Transport-Security": policy.StrictTransportSecurity,
+		"Content-Security-Policy":   policy.ContentSecurityPolicy,
+		"X-Content-Type-Options":    policy.ContentTypeOptions,
+		"X-Frame-Options":           policy.FrameOptions,
+		"Referrer-Policy":           policy.ReferrerPolicy,
+		"Permissions-Policy":        policy.PermissionsPolicy,
+	}
+	for name, value := range headers {
+		// c.Header 传入空值时删除已设置的头
+		c.Header(name, value)
+	}
+}
+
+// 按配置返回安全响应头，路由按 Routes 中首个匹配的策略覆盖
+func SecurityHeaders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		setting := &config.Setting.SecurityHeaders
+		if !setting.Enabled {
+			c.Next()
+			return
+		}
+
+		policy := setting.SecurityHeadersPolicy
+		for _, route := range setting.Routes {
+			if matchPath(route.Path, c.Request.URL.Path) {
+				policy = mergePolicy(policy, route.SecurityHeadersPolicy)
+				break
+			}
+		}
+		writeSecurityHeaders(c, policy)
+
+		c.Next()
+	}
+}
+
+// 路由组使用的安全响应头，在全局策略基础上覆盖，如：
+//
+//	Handlers: []gin.HandlerFunc{middleware.SecurityHeadersWith(config.SecurityHeadersPolicy{FrameOptions: "-"})}
+func SecurityHeadersWith(override config.SecurityHeadersPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writeSecurityHeaders(c, mergePolicy(config.Setting.SecurityHeaders.SecurityHeadersPolicy, override))
+		c.Next()
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gophab/gophrame/core/engine"
+	"github.com/gophab/gophrame/core/i18n"
+	I18nConfig "github.com/gophab/gophrame/core/i18n/config"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/starter"
+	"github.com/gophab/gophrame/core/webservice/config"
+	"github.com/gophab/gophrame/core/webservice/request"
+)
+
+func init() {
+	// 先于其他中间件，后续中间件均可获取请求 ID 及真实客户端 IP
+	starter.RegisterInitializorEx(Init, -200)
+	// 位于链路追踪、监控指标之后，被限流的请求同样可观测
+	starter.RegisterInitializorEx(InitRateLimit, -50)
+
+	logger.RegisterContextFields(func(ctx context.Context) string {
+		if requestId := request.RequestId(ctx); requestId != "" {
+			return "request_id=" + requestId
+		}
+		return ""
+	})
+}
+
+func Init() {
+	logger.Debug("Initializing WebService Middleware: ...")
+
+	e := engine.Get()
+
+	// 仅信任来自可信代理的转发头，c.ClientIP() 返回真实客户端 IP
+	if err := e.SetTrustedProxies(config.Setting.ClientIp.TrustedProxies); err != nil {
+		logger.Error("Invalid trusted proxies: ", err.Error())
+	}
+	if len(config.Setting.ClientIp.Headers) > 0 {
+		e.RemoteIPHeaders = config.Setting.ClientIp.Headers
+	}
+	e.TrustedPlatform = config.Setting.ClientIp.TrustedPlatform
+
+	e.Use(RequestId())
+	e.Use(ErrorHandler())
+	checkCors()
+	e.Use(Cors())
+	e.Use(SecurityHeaders())
+
+	if config.Setting.AccessLog.Enabled {
+		engine.SetLogger(AccessLog())
+	}
+	engine.SetRecovery(Recovery())
+
+	i18n.SetDefaultLanguage(I18nConfig.Setting.DefaultLanguage)
+}
+
+func InitRateLimit() {
+	logger.Debug("Initializing Rate Limit: ...", config.Setting.RateLimit.Enabled)
+	if config.Setting.RateLimit.Enabled {
+		engine.Get().Use(RateLimit())
+	}
+}
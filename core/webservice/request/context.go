@@ -0,0 +1,36 @@
+package request
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// gin.Context 中保存请求 ID 的键
+const REQUEST_ID_KEY = "_REQUEST_ID_"
+
+type requestIdKey struct{}
+
+func ContextWithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, requestId)
+}
+
+// 当前请求 ID，支持 *gin.Context 及由其派生的 context
+func RequestId(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	if c, ok := ctx.(*gin.Context); ok {
+		return c.GetString(REQUEST_ID_KEY)
+	}
+
+	if requestId, ok := ctx.Value(requestIdKey{}).(string); ok {
+		return requestId
+	}
+
+	if c, ok := ctx.Value(gin.ContextKey).(*gin.Context); ok {
+		return c.GetString(REQUEST_ID_KEY)
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+package response
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gophab/gophrame/core/i18n"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/webservice/config"
+	"github.com/gophab/gophrame/core/webservice/request"
+	"github.com/gophab/gophrame/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const CONTENT_TYPE_PROBLEM = "application/problem+json; charset=utf-8"
+
+type ErrorBody struct {
+	Code      int         `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestId string      `json:"requestId,omitempty"`
+}
+
+// RFC 7807
+type ProblemDetails struct {
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail,omitempty"`
+	Instance  string      `json:"instance,omitempty"`
+	Code      int         `json:"code"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestId string      `json:"requestId,omitempty"`
+}
+
+// 任意错误转换为应用错误，非应用错误视为内部错误
+func toAppError(err error) *errors.AppError {
+	if err == nil {
+		return errors.ErrInternal
+	}
+	if result, ok := errors.AsAppError(err); ok {
+		return result
+	}
+	return errors.ErrInternal.WithCause(err)
+}
+
+// 按请求语言渲染错误，响应已写出时仅记录日志
+func WriteError(c *gin.Context, err error) {
+	appErr := toAppError(err)
+
+	status := appErr.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+
+	// 原始错误不返回给客户端，服务端错误记录日志
+	if status >= http.StatusInternalServerError && appErr.Cause != nil {
+		logger.WithContext(c).Error("Request error: ", appErr.Error())
+	}
+
+	if c.Writer.Written() {
+		return
+	}
+
+	message := appErr.Localize(i18n.Language(c))
+	requestId := request.RequestId(c)
+
+	if config.Setting.Error.ProblemDetails {
+		problemType := "about:blank"
+		if config.Setting.Error.TypeBase != "" {
+			problemType = config.Setting.Error.TypeBase + strconv.Itoa(appErr.Code)
+		}
+
+		// 已设置的 Content-Type 不会被 c.JSON 覆盖
+		c.Header("Content-Type", CONTENT_TYPE_PROBLEM)
+		c.JSON(status, &ProblemDetails{
+			Type:      problemType,
+			Title:     http.StatusText(status),
+			Status:    status,
+			Detail:    message,
+			Instance:  c.Request.URL.Path,
+			Code:      appErr.Code,
+			Details:   appErr.Details,
+			RequestId: requestId,
+		})
+		return
+	}
+
+	c.JSON(status, &ErrorBody{
+		Code:      appErr.Code,
+		Message:   message,
+		Details:   appErr.Details,
+		RequestId: requestId,
+	})
+}
+
+// 渲染错误并终止后续处理
+func AbortWithError(c *gin.Context, err error) {
+	WriteError(c, err)
+	c.Abort()
+}
@@ -20,6 +20,17 @@ const (
 	ServerOccurredErrorMsg  string = "服务器内部发生代码执行错误,请联系开发者排查错误日志"
 )
 
+func init() {
+	errors.AddErrorMessages(map[int]string{
+		BusinessOccurredErrorCode: BusinessOccurredErrorMsg,
+		ServerOccurredErrorCode:   ServerOccurredErrorMsg,
+	})
+	errors.RegisterErrorMessages("en", map[int]string{
+		BusinessOccurredErrorCode: "Business processing failed, please contact the administrator",
+		ServerOccurredErrorCode:   "Internal server error, please contact the developer",
+	})
+}
+
 type Gin struct {
 	C *gin.Context
 }
@@ -30,10 +41,7 @@ func (g *Gin) Response(httpCode, errCode int, data interface{}) {
 
 func Response(c *gin.Context, httpCode, errCode int, data interface{}) {
 	if errCode != 0 {
-		c.JSON(httpCode, gin.H{
-			"code":    errCode,
-			"message": errors.GetErrorMessage(errCode),
-		})
+		WriteError(c, errors.NewAppError(errCode, httpCode))
 	} else {
 		c.Header("Content-Type", "application/json; charset=utf-8")
 		if data != nil {
@@ -55,19 +63,11 @@ func Error(c *gin.Context, httpCode int) {
 }
 
 func ErrorCode(c *gin.Context, httpCode int, errCode int) {
-	//Context.Header("key2020","value2020")  	//可以根据实际情况在头部添加额外的其他信息
-	c.AbortWithStatusJSON(httpCode, gin.H{
-		"code":    errCode,
-		"message": errors.GetErrorMessage(errCode),
-	})
+	AbortWithError(c, errors.NewAppError(errCode, httpCode))
 }
 
 func ErrorMessage(c *gin.Context, httpCode int, dataCode int, msg string) {
-	//Context.Header("key2020","value2020")  	//可以根据实际情况在头部添加额外的其他信息
-	c.AbortWithStatusJSON(httpCode, gin.H{
-		"code":    dataCode,
-		"message": msg,
-	})
+	AbortWithError(c, errors.NewAppError(dataCode, httpCode).WithMessage(msg))
 }
 
 // 语法糖函数封装
@@ -103,11 +103,11 @@ func SuccessWithHeader(c *gin.Context, data interface{}, headers map[string]stri
 
 // 失败的业务逻辑
 func Fail(c *gin.Context) {
-	Bad(c, BusinessOccurredErrorCode, BusinessOccurredErrorMsg)
+	FailCode(c, BusinessOccurredErrorCode)
 }
 
 func FailCode(c *gin.Context, errCode int) {
-	Bad(c, errCode, errors.GetErrorMessage(errCode))
+	ErrorCode(c, http.StatusBadRequest, errCode)
 }
 
 func FailMessage(c *gin.Context, errCode int, errMsg string) {
@@ -138,19 +138,26 @@ func Exception(c *gin.Context, errCode int, errMessage string, msg string) {
 
 // 系统执行代码错误
 func SystemError(c *gin.Context) {
-	Exception(c, ServerOccurredErrorCode, ServerOccurredErrorMsg, "")
+	SystemErrorCode(c, ServerOccurredErrorCode)
 }
 
 func SystemErrorCode(c *gin.Context, errCode int) {
-	Exception(c, errCode, errors.GetErrorMessage(errCode), "")
+	ErrorCode(c, http.StatusInternalServerError, errCode)
+
+	// internal log
+	logger.Error("Code: ", errCode, " \nStack: ", stack())
 }
 
+// 错误码未注册消息时使用 msg 作为消息
 func SystemErrorMessage(c *gin.Context, errCode int, msg string) {
-	errMessage := errors.GetErrorMessage(errCode)
-	if errMessage == "" {
-		errMessage = msg
+	appErr := errors.NewAppError(errCode, http.StatusInternalServerError)
+	if !errors.HasErrorMessage(errCode) {
+		appErr = appErr.WithMessage(msg)
 	}
-	Exception(c, errCode, errMessage, msg)
+	AbortWithError(c, appErr)
+
+	// internal log
+	logger.Error("Code: ", errCode, "\nError: ", msg, " \nStack: ", stack())
 }
 
 func Page(context *gin.Context, total int64, list interface{}) {
@@ -0,0 +1,77 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Server-Sent Events 事件
+type SSEvent struct {
+	Id    string
+	Event string
+	Data  interface{} // string/[]byte 原样发送，其他类型编码为 JSON
+	Retry time.Duration
+}
+
+// 设置 SSE 响应头
+func SSEHeaders(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// 关闭 nginx 缓冲
+	c.Header("X-Accel-Buffering", "no")
+}
+
+// 写入一个事件并立即刷新
+func SSE(c *gin.Context, event *SSEvent) error {
+	var builder strings.Builder
+
+	if event.Id != "" {
+		fmt.Fprintf(&builder, "id: %s\n", event.Id)
+	}
+	if event.Event != "" {
+		fmt.Fprintf(&builder, "event: %s\n", event.Event)
+	}
+	if event.Retry > 0 {
+		fmt.Fprintf(&builder, "retry: %d\n", event.Retry.Milliseconds())
+	}
+
+	if event.Data != nil {
+		var data string
+		switch value := event.Data.(type) {
+		case string:
+			data = value
+		case []byte:
+			data = string(value)
+		default:
+			bytes, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			data = string(bytes)
+		}
+		for _, line := range strings.Split(data, "\n") {
+			fmt.Fprintf(&builder, "data: %s\n", line)
+		}
+	}
+	builder.WriteString("\n")
+
+	if _, err := c.Writer.WriteString(builder.String()); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
+
+// 写入注释行（心跳）
+func SSEComment(c *gin.Context, comment string) error {
+	if _, err := c.Writer.WriteString(": " + comment + "\n\n"); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
@@ -0,0 +1,101 @@
+package websocket
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gophab/gophrame/core/security/token"
+	SecurityUtil "github.com/gophab/gophrame/core/security/util"
+	"github.com/gophab/gophrame/core/websocket/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+var ErrUnauthorized = errors.New("websocket unauthorized")
+
+// 握手携带的 token：Authorization 头、query 参数、子协议（Sec-WebSocket-Protocol: access_token, <token>）
+// 通过子协议传递时返回需回应的子协议名
+func extractToken(context *gin.Context) (tokenValue string, subprotocol string) {
+	if authorization := context.GetHeader("Authorization"); authorization != "" {
+		if parts := strings.SplitN(authorization, " ", 2); len(parts) == 2 && parts[1] != "" {
+			return parts[1], ""
+		}
+	}
+
+	if config.Setting.TokenParam != "" {
+		if value := context.Query(config.Setting.TokenParam); value != "" {
+			return value, ""
+		}
+	}
+
+	if config.Setting.TokenSubprotocol != "" {
+		protocols := websocket.Subprotocols(context.Request)
+		for i, protocol := range protocols {
+			if protocol == config.Setting.TokenSubprotocol && i+1 < len(protocols) {
+				return protocols[i+1], protocol
+			}
+		}
+	}
+
+	return "", ""
+}
+
+// 使用配置的 token 解析器认证握手请求，返回用户ID、租户ID及需回应的子协议
+func authenticate(context *gin.Context) (userId string, tenantId string, subprotocol string, err error) {
+	tokenValue, subprotocol := extractToken(context)
+	if tokenValue == "" {
+		// 已由 token 中间件认证
+		if userId := context.GetString("_CURRENT_USER_ID_"); userId != "" {
+			return userId, SecurityUtil.GetCurrentTenantId(context), "", nil
+		}
+		if config.Setting.RequireAuth {
+			return "", "", "", ErrUnauthorized
+		}
+		return "", "", "", nil
+	}
+
+	tokenInfo, err := token.TokenResolver().Resolve(context, tokenValue)
+	if err != nil || tokenInfo == nil || tokenInfo.GetUserID() == "" {
+		return "", "", "", ErrUnauthorized
+	}
+
+	// 与 token 中间件一致记录当前用户，租户由用户信息解析
+	context.Set("_CURRENT_USER_ID_", tokenInfo.GetUserID())
+	return tokenInfo.GetUserID(), SecurityUtil.GetCurrentTenantId(context), subprotocol, nil
+}
+
+// 校验 Origin：未配置 allowedOrigins 时仅允许同源，"*" 允许全部，"*.example.com" 匹配子域名
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		// 非浏览器客户端
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	allowedOrigins := config.Setting.AllowedOrigins
+	if len(allowedOrigins) == 0 {
+		return strings.EqualFold(u.Host, r.Host)
+	}
+
+	for _, allowed := range allowedOrigins {
+		switch {
+		case allowed == "*":
+			return true
+		case strings.EqualFold(allowed, origin), strings.EqualFold(allowed, u.Host):
+			return true
+		case strings.HasPrefix(allowed, "*."):
+			if strings.HasSuffix(strings.ToLower(u.Hostname()), strings.ToLower(allowed[1:])) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package websocket
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"sync"
@@ -8,9 +9,11 @@ import (
 
 	"github.com/gophab/gophrame/core/global"
 	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/webservice/response"
 	"github.com/gophab/gophrame/core/websocket/config"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
@@ -23,6 +26,9 @@ type ClientMoreParams struct {
 }
 
 type Client struct {
+	Id                 string          // 连接标识
+	UserId             string          // 登录用户，未登录为空
+	TenantId           string          // 用户所属租户
 	Hub                *Hub            // 负责处理客户端注册、注销、在线管理
 	Conn               *websocket.Conn // 一个ws连接
 	Send               chan []byte     // 一个ws连接存储自己的消息管道
@@ -31,6 +37,9 @@ type Client struct {
 	WriteDeadline      time.Duration
 	HeartbeatFailTimes int
 	State              uint8 // ws状态，1=ok；0=出错、掉线等
+	rooms              map[string]bool
+	done               chan struct{}
+	closeOnce          sync.Once
 	sync.RWMutex
 	ClientMoreParams // 这里追加一个结构体，方便开发者在成功上线后，可以自定义追加更多字段信息
 }
@@ -51,22 +60,39 @@ func (c *Client) OnOpen(context *gin.Context) (*Client, bool) {
 			}
 		}
 	}()
+	// 认证握手请求
+	userId, tenantId, subprotocol, err := authenticate(context)
+	if err != nil {
+		logger.Warn(ErrorsWebsocketUnauthorized, context.ClientIP())
+		response.Unauthorized(context, err.Error())
+		context.Abort()
+		return nil, false
+	}
+
 	var upGrader = websocket.Upgrader{
 		ReadBufferSize:  config.Setting.BufferSize,
 		WriteBufferSize: config.Setting.BufferSize,
-		CheckOrigin: func(r *http.Request) bool {
-			return true
-		},
+		CheckOrigin:     checkOrigin,
+	}
+
+	var responseHeader http.Header
+	if subprotocol != "" {
+		responseHeader = http.Header{"Sec-Websocket-Protocol": []string{subprotocol}}
 	}
 
 	// 2.将http协议升级到websocket协议.初始化一个有效的websocket长连接客户端
-	if wsConn, err := upGrader.Upgrade(context.Writer, context.Request, nil); err != nil {
+	if wsConn, err := upGrader.Upgrade(context.Writer, context.Request, responseHeader); err != nil {
 		logger.Error(ErrorsWebsocketUpgradeFail, err.Error())
 		return nil, false
 	} else {
 		if wsHub, ok := global.WebsocketHub.(*Hub); ok {
 			c.Hub = wsHub
 		}
+		c.Id = uuid.NewString()
+		c.UserId = userId
+		c.TenantId = tenantId
+		c.rooms = make(map[string]bool)
+		c.done = make(chan struct{})
 		c.Conn = wsConn
 		c.Send = make(chan []byte, config.Setting.BufferSize)
 		c.PingPeriod = time.Second * time.Duration(config.Setting.PingPeriod)
@@ -77,8 +103,9 @@ func (c *Client) OnOpen(context *gin.Context) (*Client, bool) {
 			logger.Error(ErrorsWebsocketWriteMgsFail, err.Error())
 		}
 		c.Conn.SetReadLimit(config.Setting.MaxMessageSize) // 设置最大读取长度
-		c.Hub.Register <- c
 		c.State = 1
+		c.Hub.Register(c)
+		go c.WritePump()
 		return c, true
 	}
 
@@ -137,6 +164,78 @@ func (c *Client) SendMessage(messageType int, message string) error {
 	}
 }
 
+// 消息放入发送队列，由 WritePump 发送；队列已满或连接已关闭时返回 false
+func (c *Client) Push(message []byte) bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+
+	select {
+	case c.Send <- message:
+		return true
+	case <-c.done:
+		return false
+	default:
+		logger.Warn("[WEBSOCKET] Send queue full: ", c.Id)
+		return false
+	}
+}
+
+// 推送 JSON 消息
+func (c *Client) SendJSON(msgType string, data interface{}) error {
+	message, err := NewMessage(msgType, data)
+	if err != nil {
+		return err
+	}
+	if !c.Push(message) {
+		return errors.New(ErrorsWebsocketStateInvalid)
+	}
+	return nil
+}
+
+// 应答或错误帧
+func (c *Client) reply(id, msgType string, data interface{}, messageError *MessageError) {
+	message := &Message{Id: id, Type: msgType, Error: messageError}
+	if data != nil {
+		if raw, err := json.Marshal(data); err != nil {
+			message.Type = MESSAGE_TYPE_ERROR
+			message.Error = NewMessageError(http.StatusInternalServerError, err.Error())
+		} else {
+			message.Data = raw
+		}
+	}
+
+	result, _ := json.Marshal(message)
+	c.Push(result)
+}
+
+// 依次发送队列中的消息，连接关闭后退出
+func (c *Client) WritePump() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case message := <-c.Send:
+			if err := c.SendMessage(websocket.TextMessage, string(message)); err != nil {
+				logger.Error(ErrorsWebsocketWriteMgsFail, err.Error())
+				c.State = 0
+				return
+			}
+		}
+	}
+}
+
+// 关闭连接，由 Hub 注销时调用
+func (c *Client) close() {
+	c.closeOnce.Do(func() {
+		c.State = 0
+		close(c.done)
+		_ = c.Conn.Close()
+	})
+}
+
 // 按照websocket标准协议实现隐式心跳,Server端向Client远端发送ping格式数据包,浏览器收到ping标准格式，自动将消息原路返回给服务器
 func (c *Client) Heartbeat() {
 	//  1. 设置一个时钟，周期性的向client远端发送心跳数据包
@@ -185,7 +284,8 @@ func (c *Client) Heartbeat() {
 			} else {
 				return
 			}
-
+		case <-c.done:
+			return
 		}
 	}
 }
@@ -6,13 +6,20 @@ import (
 )
 
 type WebsocketSetting struct {
-	Enabled               bool  `json:"enabled"`
-	BufferSize            int   `json:"bufferSize" yaml:"bufferSize"`
-	MaxMessageSize        int64 `json:"maxMessageSize" yaml:"maxMessageSize"`
-	PingPeriod            int   `json:"pingPeriod" yaml:"pingPeriod"`
-	HeartbeatFailMaxTimes int   `json:"heartbeatFailMaxTimes" yaml:"heartbeatFialMaxTimes"`
-	ReadDeadline          int   `json:"readDeadline" yaml:"readDeadline"`
-	WriteDeadline         int   `json:"writeDeadline" yaml:"writeDeadline"`
+	Enabled               bool     `json:"enabled"`
+	BufferSize            int      `json:"bufferSize" yaml:"bufferSize"`
+	MaxMessageSize        int64    `json:"maxMessageSize" yaml:"maxMessageSize"`
+	PingPeriod            int      `json:"pingPeriod" yaml:"pingPeriod"`
+	HeartbeatFailMaxTimes int      `json:"heartbeatFailMaxTimes" yaml:"heartbeatFialMaxTimes"`
+	ReadDeadline          int      `json:"readDeadline" yaml:"readDeadline"`
+	WriteDeadline         int      `json:"writeDeadline" yaml:"writeDeadline"`
+	FanOut                bool     `json:"fanOut" yaml:"fanOut"`                     // 通过 redis pub/sub 跨实例转发消息
+	FanOutChannel         string   `json:"fanOutChannel" yaml:"fanOutChannel"`       // 转发频道
+	Path                  string   `json:"path"`                                     // 握手路径，为空时不注册
+	AllowedOrigins        []string `json:"allowedOrigins" yaml:"allowedOrigins"`     // 允许的 Origin，为空时仅允许同源
+	RequireAuth           bool     `json:"requireAuth" yaml:"requireAuth"`           // 握手必须携带有效 token
+	TokenParam            string   `json:"tokenParam" yaml:"tokenParam"`             // 传递 token 的 query 参数
+	TokenSubprotocol      string   `json:"tokenSubprotocol" yaml:"tokenSubprotocol"` // 传递 token 的子协议名
 }
 
 var Setting *WebsocketSetting = &WebsocketSetting{
@@ -23,6 +30,11 @@ var Setting *WebsocketSetting = &WebsocketSetting{
 	HeartbeatFailMaxTimes: 4,
 	ReadDeadline:          100,
 	WriteDeadline:         35,
+	FanOutChannel:         "websocket:fanout",
+	Path:                  "/ws",
+	RequireAuth:           true,
+	TokenParam:            "access_token",
+	TokenSubprotocol:      "access_token",
 }
 
 func init() {
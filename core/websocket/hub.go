@@ -1,32 +1,281 @@
 package websocket
 
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/gophab/gophrame/core/global"
+	"github.com/gophab/gophrame/core/logger"
+	"github.com/gophab/gophrame/core/redis"
+	"github.com/gophab/gophrame/core/websocket/config"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TARGET_ALL  = "all"
+	TARGET_USER = "user"
+	TARGET_ROOM = "room"
+)
+
+// 跨实例转发的消息
+type envelope struct {
+	Instance string `json:"instance"`
+	Target   string `json:"target"` // all / user / room
+	Key      string `json:"key"`
+	Message  []byte `json:"message"`
+}
+
+// 在线连接管理：按用户、房间索引，所有方法并发安全
 type Hub struct {
-	//上线注册
-	Register chan *Client
-	//下线注销
-	UnRegister chan *Client
-	//所有在线客户端的内存地址
-	Clients map[*Client]bool
+	instanceId string
+	clients    map[*Client]bool
+	users      map[string]map[*Client]bool
+	rooms      map[string]map[*Client]bool
+	mutex      sync.RWMutex
+	ctx        context.Context
+	cancel     context.CancelFunc
 }
 
 func CreateHubFactory() *Hub {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Hub{
-		Register:   make(chan *Client),
-		UnRegister: make(chan *Client),
-		Clients:    make(map[*Client]bool),
+		instanceId: uuid.NewString(),
+		clients:    make(map[*Client]bool),
+		users:      make(map[string]map[*Client]bool),
+		rooms:      make(map[string]map[*Client]bool),
+		ctx:        ctx,
+		cancel:     cancel,
 	}
 }
 
+// 开启跨实例转发时订阅 redis 频道，阻塞直到 Stop
 func (h *Hub) Run() {
-	for {
-		select {
-		case client := <-h.Register:
-			h.Clients[client] = true
-		case client := <-h.UnRegister:
-			if _, ok := h.Clients[client]; ok {
-				_ = client.Conn.Close()
-				delete(h.Clients, client)
-			}
+	if !config.Setting.FanOut {
+		return
+	}
+
+	redis.Subscribe(h.ctx, func(channel string, data []byte) {
+		var e envelope
+		if err := json.Unmarshal(data, &e); err != nil {
+			logger.Warn("[WEBSOCKET] Invalid fan-out message: ", err.Error())
+			return
+		}
+		if e.Instance == h.instanceId {
+			return
+		}
+		h.deliver(e.Target, e.Key, e.Message)
+	}, config.Setting.FanOutChannel)
+}
+
+func (h *Hub) Stop() {
+	h.cancel()
+
+	h.mutex.RLock()
+	clients := make([]*Client, 0, len(h.clients))
+	for client := range h.clients {
+		clients = append(clients, client)
+	}
+	h.mutex.RUnlock()
+
+	for _, client := range clients {
+		h.UnRegister(client)
+	}
+}
+
+func addIndex(index map[string]map[*Client]bool, key string, client *Client) {
+	if index[key] == nil {
+		index[key] = make(map[*Client]bool)
+	}
+	index[key][client] = true
+}
+
+func removeIndex(index map[string]map[*Client]bool, key string, client *Client) {
+	if clients := index[key]; clients != nil {
+		delete(clients, client)
+		if len(clients) == 0 {
+			delete(index, key)
+		}
+	}
+}
+
+// 上线注册，已登录用户按用户索引，租户用户自动加入租户房间
+func (h *Hub) Register(client *Client) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.clients[client] = true
+	if client.UserId != "" {
+		addIndex(h.users, client.UserId, client)
+	}
+	if client.TenantId != "" {
+		client.rooms[TenantRoom(client.TenantId)] = true
+	}
+	for room := range client.rooms {
+		addIndex(h.rooms, room, client)
+	}
+}
+
+// 下线注销，关闭连接
+func (h *Hub) UnRegister(client *Client) {
+	h.mutex.Lock()
+	if _, ok := h.clients[client]; !ok {
+		h.mutex.Unlock()
+		return
+	}
+
+	delete(h.clients, client)
+	if client.UserId != "" {
+		removeIndex(h.users, client.UserId, client)
+	}
+	for room := range client.rooms {
+		removeIndex(h.rooms, room, client)
+	}
+	h.mutex.Unlock()
+
+	client.close()
+}
+
+// 租户房间名
+func TenantRoom(tenantId string) string {
+	return "tenant:" + tenantId
+}
+
+func (h *Hub) Join(client *Client, room string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	client.rooms[room] = true
+	if _, ok := h.clients[client]; ok {
+		addIndex(h.rooms, room, client)
+	}
+}
+
+func (h *Hub) Leave(client *Client, room string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	delete(client.rooms, room)
+	removeIndex(h.rooms, room, client)
+}
+
+func (h *Hub) OnlineCount() int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return len(h.clients)
+}
+
+// 用户是否在本实例在线
+func (h *Hub) IsOnline(userId string) bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return len(h.users[userId]) > 0
+}
+
+func (h *Hub) Rooms(client *Client) []string {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	result := make([]string, 0, len(client.rooms))
+	for room := range client.rooms {
+		result = append(result, room)
+	}
+	return result
+}
+
+func (h *Hub) targets(target, key string) []*Client {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	var clients map[*Client]bool
+	switch target {
+	case TARGET_USER:
+		clients = h.users[key]
+	case TARGET_ROOM:
+		clients = h.rooms[key]
+	default:
+		clients = h.clients
+	}
+
+	result := make([]*Client, 0, len(clients))
+	for client := range clients {
+		result = append(result, client)
+	}
+	return result
+}
+
+// 投递到本实例的连接
+func (h *Hub) deliver(target, key string, message []byte) {
+	for _, client := range h.targets(target, key) {
+		if !client.Push(message) {
+			// 发送队列已满或连接已关闭
+			h.UnRegister(client)
+		}
+	}
+}
+
+func (h *Hub) send(target, key string, message []byte) {
+	h.deliver(target, key, message)
+
+	if config.Setting.FanOut {
+		data, _ := json.Marshal(&envelope{
+			Instance: h.instanceId,
+			Target:   target,
+			Key:      key,
+			Message:  message,
+		})
+		if err := redis.Publish(config.Setting.FanOutChannel, data); err != nil {
+			logger.Error("[WEBSOCKET] Fan-out message error: ", err.Error())
 		}
 	}
 }
+
+// 发送给用户的全部连接（含其他实例）
+func (h *Hub) SendToUser(userId string, message []byte) {
+	h.send(TARGET_USER, userId, message)
+}
+
+// 发送给房间内全部连接（含其他实例）
+func (h *Hub) SendToRoom(room string, message []byte) {
+	h.send(TARGET_ROOM, room, message)
+}
+
+func (h *Hub) SendToTenant(tenantId string, message []byte) {
+	h.send(TARGET_ROOM, TenantRoom(tenantId), message)
+}
+
+// 发送给全部在线连接（含其他实例）
+func (h *Hub) Broadcast(message []byte) {
+	h.send(TARGET_ALL, "", message)
+}
+
+// 全局 Hub，websocket 未启用时为 nil
+func GetHub() *Hub {
+	hub, _ := global.WebsocketHub.(*Hub)
+	return hub
+}
+
+func SendToUser(userId string, message []byte) {
+	if hub := GetHub(); hub != nil {
+		hub.SendToUser(userId, message)
+	}
+}
+
+func SendToRoom(room string, message []byte) {
+	if hub := GetHub(); hub != nil {
+		hub.SendToRoom(room, message)
+	}
+}
+
+func SendToTenant(tenantId string, message []byte) {
+	if hub := GetHub(); hub != nil {
+		hub.SendToTenant(tenantId, message)
+	}
+}
+
+func Broadcast(message []byte) {
+	if hub := GetHub(); hub != nil {
+		hub.Broadcast(message)
+	}
+}
@@ -0,0 +1,15 @@
+package websocket
+
+import (
+	"github.com/gophab/gophrame/core/metrics"
+)
+
+func init() {
+	metrics.MustRegister(metrics.NewGaugeFunc("websocket_connected_clients",
+		"WebSocket connections on this instance", func() float64 {
+			if hub := GetHub(); hub != nil {
+				return float64(hub.OnlineCount())
+			}
+			return 0
+		}))
+}
@@ -0,0 +1,176 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gophab/gophrame/core/logger"
+)
+
+// 错误帧类型
+const MESSAGE_TYPE_ERROR = "error"
+
+// 客户端与服务端交互的 JSON 消息，Id 用于请求/应答关联
+type Message struct {
+	Id    string          `json:"id,omitempty"`
+	Type  string          `json:"type"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error *MessageError   `json:"error,omitempty"`
+}
+
+type MessageError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *MessageError) Error() string {
+	return fmt.Sprintf("%d %s", e.Code, e.Message)
+}
+
+func NewMessageError(code int, message string) *MessageError {
+	return &MessageError{Code: code, Message: message}
+}
+
+// 编码服务端推送消息
+func NewMessage(msgType string, data interface{}) ([]byte, error) {
+	result := &Message{Type: msgType}
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		result.Data = raw
+	}
+	return json.Marshal(result)
+}
+
+// 消息处理上下文
+type Context struct {
+	context.Context
+	Client  *Client
+	Message *Message
+}
+
+// 解析消息数据
+func (c *Context) Bind(v interface{}) error {
+	if len(c.Message.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(c.Message.Data, v)
+}
+
+func (c *Context) UserId() string {
+	return c.Client.UserId
+}
+
+// 消息处理函数，返回值作为应答数据（消息带 Id 时应答）
+type HandlerFunc func(ctx *Context) (interface{}, error)
+
+type MessageHandler struct {
+	Type    string
+	Handler HandlerFunc
+}
+
+// 消息处理器注册，类似 Controller 注册路由
+type MessageController interface {
+	InitMessageRouter(*MessageRouter)
+}
+
+// 按消息 type 分发
+type MessageRouter struct {
+	handlers map[string]HandlerFunc
+	mutex    sync.RWMutex
+}
+
+func NewMessageRouter() *MessageRouter {
+	return &MessageRouter{handlers: make(map[string]HandlerFunc)}
+}
+
+func (r *MessageRouter) Handle(msgType string, handler HandlerFunc) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.handlers[msgType] = handler
+}
+
+func (r *MessageRouter) RegisterMessageHandler(h ...MessageHandler) {
+	for _, handler := range h {
+		r.Handle(handler.Type, handler.Handler)
+	}
+}
+
+func (r *MessageRouter) RegisterMessageController(c ...MessageController) {
+	for _, controller := range c {
+		controller.InitMessageRouter(r)
+	}
+}
+
+// 注册强类型处理函数，消息 data 解析为 Req
+func HandleMessage[Req any](r *MessageRouter, msgType string, handler func(ctx *Context, req *Req) (interface{}, error)) {
+	r.Handle(msgType, func(ctx *Context) (interface{}, error) {
+		req := new(Req)
+		if err := ctx.Bind(req); err != nil {
+			return nil, NewMessageError(http.StatusBadRequest, err.Error())
+		}
+		return handler(ctx, req)
+	})
+}
+
+// 分发客户端消息并回写应答或错误帧
+func (r *MessageRouter) Dispatch(client *Client, data []byte) {
+	message := &Message{}
+	if err := json.Unmarshal(data, message); err != nil || message.Type == "" {
+		client.reply(message.Id, MESSAGE_TYPE_ERROR, nil, NewMessageError(http.StatusBadRequest, "invalid message"))
+		return
+	}
+
+	r.mutex.RLock()
+	handler := r.handlers[message.Type]
+	r.mutex.RUnlock()
+
+	if handler == nil {
+		client.reply(message.Id, MESSAGE_TYPE_ERROR, nil, NewMessageError(http.StatusNotFound, "unknown message type: "+message.Type))
+		return
+	}
+
+	result, err := r.call(handler, &Context{Context: context.Background(), Client: client, Message: message})
+	if err != nil {
+		messageError, ok := err.(*MessageError)
+		if !ok {
+			messageError = NewMessageError(http.StatusInternalServerError, err.Error())
+		}
+		client.reply(message.Id, MESSAGE_TYPE_ERROR, nil, messageError)
+		return
+	}
+
+	if message.Id != "" {
+		client.reply(message.Id, message.Type, result, nil)
+	}
+}
+
+func (r *MessageRouter) call(handler HandlerFunc, ctx *Context) (result interface{}, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			logger.Error("[WEBSOCKET] Handle message [", ctx.Message.Type, "] panic: ", e)
+			err = NewMessageError(http.StatusInternalServerError, "internal error")
+		}
+	}()
+	return handler(ctx)
+}
+
+var defaultRouter = NewMessageRouter()
+
+// 全局消息路由
+func Router() *MessageRouter {
+	return defaultRouter
+}
+
+func Handle(msgType string, handler HandlerFunc) {
+	defaultRouter.Handle(msgType, handler)
+}
+
+func RegisterMessageController(c ...MessageController) {
+	defaultRouter.RegisterMessageController(c...)
+}
@@ -1,6 +1,7 @@
 package websocket
 
 import (
+	"github.com/gophab/gophrame/core/router"
 	"github.com/gophab/gophrame/core/starter"
 	"github.com/gophab/gophrame/core/websocket/config"
 
@@ -10,6 +11,7 @@ import (
 
 func init() {
 	starter.RegisterStarter(Start)
+	starter.RegisterTerminater(Terminate)
 }
 
 func Start() {
@@ -22,5 +24,15 @@ func Start() {
 			go WebsocketHub.Run()
 			logger.Info("Running websocket hub OK")
 		}
+
+		if config.Setting.Path != "" {
+			router.Root().GET(config.Setting.Path, Handler())
+		}
+	}
+}
+
+func Terminate() {
+	if hub := GetHub(); hub != nil {
+		hub.Stop()
 	}
 }
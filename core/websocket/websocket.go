@@ -19,11 +19,9 @@ type Websocket struct {
 // onOpen 事件函数
 func (w *Websocket) OnOpen(context *gin.Context) (*Websocket, bool) {
 	if client, ok := (&Client{}).OnOpen(context); ok {
+		logger.Debug("Websocket client online: ", client.Id, " user: ", client.UserId)
 
-		token := context.GetString("token")
-		logger.Info("获取到的客户端上线时携带的唯一标记值：", "token", token)
-
-		// 成功上线以后，开发者可以基于客户端上线时携带的唯一参数(这里用token键表示)
+		// 成功上线以后，开发者可以基于已认证的用户(client.UserId)
 		// 在数据库查询更多的其他字段信息，直接追加在 Client 结构体上，方便后续使用
 		//client.ClientMoreParams.UserParams1 = "123"
 		//client.ClientMoreParams.UserParams2 = "456"
@@ -39,19 +37,13 @@ func (w *Websocket) OnOpen(context *gin.Context) (*Websocket, bool) {
 	return nil, false
 }
 
-// OnMessage 处理业务消息
+// OnMessage 处理业务消息：文本消息按 JSON 的 type 字段分发到 Router() 注册的处理函数
 func (w *Websocket) OnMessage(context *gin.Context) {
 	go w.Client.ReadPump(func(messageType int, receivedData []byte) {
-		//参数说明
-		//messageType 消息类型，1=文本
-		//receivedData 服务器接收到客户端（例如js客户端）发来的的数据，[]byte 格式
-		// 实际项目中，消息的传递请统一按照json格式传递
-		tempMsg := "服务器已经收到了你的消息：" + string(receivedData)
-		// 回复客户端已经收到消息;
-		if err := w.Client.SendMessage(messageType, tempMsg); err != nil {
-			logger.Error("消息发送出现错误", err.Error())
+		if messageType != websocket.TextMessage {
+			return
 		}
-
+		defaultRouter.Dispatch(w.Client, receivedData)
 	}, w.OnError, w.OnClose)
 }
 
@@ -64,22 +56,34 @@ func (w *Websocket) OnError(err error) {
 // OnClose 客户端关闭回调，发生onError回调以后会继续回调该函数
 func (w *Websocket) OnClose() {
 	w.Client.State = 0
-	w.Client.Hub.UnRegister <- w.Client // 向hub管道投递一条注销消息，由hub中心负责关闭连接、删除在线数据
+	w.Client.Hub.UnRegister(w.Client) // 由hub中心负责关闭连接、删除在线数据
 }
 
 // 获取在线的全部客户端
 func (w *Websocket) GetOnlineClients() int {
-	//fmt.Printf("在线客户端数量：%d\n", len(w.WsClient.Hub.Clients))
-	return len(w.Client.Hub.Clients)
+	return w.Client.Hub.OnlineCount()
+}
+
+// 加入房间
+func (w *Websocket) Join(room string) {
+	w.Client.Hub.Join(w.Client, room)
 }
 
-// 向全部在线客户端广播消息
-// 广播函数可能被不同的逻辑同时调用，由于操作的都是 Conn ，因此为了保证并发安全，加互斥锁
+// 离开房间
+func (w *Websocket) Leave(room string) {
+	w.Client.Hub.Leave(w.Client, room)
+}
+
+// 向全部在线客户端广播消息，开启 fanOut 时同时发送到其他实例
 func (w *Websocket) BroadcastMsg(sendMsg string) {
-	for onlineClient := range w.Client.Hub.Clients {
-		//获取每一个在线的客户端，向远端发送消息
-		if err := onlineClient.SendMessage(websocket.TextMessage, sendMsg); err != nil {
-			logger.Error(ErrorsWebsocketWriteMgsFail, err.Error())
+	w.Client.Hub.Broadcast([]byte(sendMsg))
+}
+
+// 握手端点：认证、升级并分发消息
+func Handler() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		if w, ok := (&Websocket{}).OnOpen(context); ok {
+			w.OnMessage(context)
 		}
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"github.com/gophab/gophrame/core/controller"
 	"github.com/gophab/gophrame/core/inject"
 	"github.com/gophab/gophrame/core/security"
+	"github.com/gophab/gophrame/core/webservice/middleware"
 
 	"github.com/gophab/gophrame/default/controller/api/auth"
 
@@ -14,6 +15,7 @@ var Resources *controller.Controllers = &controller.Controllers{
 	Base: "/api",
 	Handlers: []gin.HandlerFunc{
 		security.HandleTokenVerify(), // oauth2 验证
+		middleware.UserRateLimit(),   // 按用户限流
 	},
 	Controllers: []controller.Controller{
 		userController,
@@ -165,7 +165,7 @@ func (u *UserController) AddUser(c *gin.Context) {
 		service.GetUserService().LoadPolicy(res.Id)
 		response.Success(c, res)
 	} else {
-		response.SystemErrorMessage(c, errors.ERROR_CREATE_FAIL, err.Error())
+		response.AbortWithError(c, errors.Wrap(err, errors.ERROR_CREATE_FAIL))
 	}
 }
 
@@ -211,7 +211,7 @@ func (u *UserController) UpdateUser(c *gin.Context) {
 	}
 
 	if result, err := service.GetUserService().UpdateUser(&user); err != nil {
-		response.SystemErrorMessage(c, errors.ERROR_UPDATE_FAIL, err.Error())
+		response.AbortWithError(c, errors.Wrap(err, errors.ERROR_UPDATE_FAIL))
 	} else {
 		response.Success(c, result)
 	}
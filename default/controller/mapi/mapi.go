@@ -6,6 +6,7 @@ import (
 	"github.com/gophab/gophrame/core/controller"
 	"github.com/gophab/gophrame/core/permission"
 	"github.com/gophab/gophrame/core/security"
+	"github.com/gophab/gophrame/core/webservice/middleware"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,6 +15,7 @@ var Resources = &controller.Controllers{
 	Base: "/mapi",
 	Handlers: []gin.HandlerFunc{
 		security.HandleTokenVerify(),      // oauth2 验证
+		middleware.UserRateLimit(),        // 按用户限流
 		permission.NeedSystemUser(),       // 需要系统用户
 		permission.CheckUserPermissions(), // 权限验证
 	},
@@ -25,6 +27,7 @@ var Resources = &controller.Controllers{
 		organizationUserMController,
 		systemOptionMController,
 		tenantOptionMController,
+		pluginMController,
 		auth.Resources,
 	},
 }
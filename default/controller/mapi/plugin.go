@@ -0,0 +1,63 @@
+package mapi
+
+import (
+	"github.com/gophab/gophrame/core/controller"
+	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/plugin"
+	"github.com/gophab/gophrame/core/webservice/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PluginMController struct {
+	controller.ResourceController
+}
+
+var pluginMController = &PluginMController{}
+
+func init() {
+	inject.InjectValue("pluginMController", pluginMController)
+}
+
+func (c *PluginMController) AfterInitialize() {
+	c.SetResourceHandlers([]controller.ResourceHandler{
+		{HttpMethod: "GET", ResourcePath: "/plugins", Handler: c.GetPlugins},
+		{HttpMethod: "GET", ResourcePath: "/plugin/:name", Handler: c.GetPlugin},
+		{HttpMethod: "PUT", ResourcePath: "/plugin/:name/enable", Handler: c.EnablePlugin},
+		{HttpMethod: "PUT", ResourcePath: "/plugin/:name/disable", Handler: c.DisablePlugin},
+	})
+}
+
+func (c *PluginMController) GetPlugins(ctx *gin.Context) {
+	response.Success(ctx, plugin.GetPlugins())
+}
+
+func (c *PluginMController) GetPlugin(ctx *gin.Context) {
+	if p := plugin.GetPlugin(ctx.Param("name")); p != nil {
+		response.Success(ctx, p.Info())
+	} else {
+		response.NotFound(ctx, plugin.ErrPluginNotFound.Error())
+	}
+}
+
+func (c *PluginMController) EnablePlugin(ctx *gin.Context) {
+	name := ctx.Param("name")
+	if err := plugin.EnablePlugin(name); err == plugin.ErrPluginNotFound {
+		response.NotFound(ctx, err.Error())
+	} else if err != nil {
+		response.FailMessage(ctx, 400, err.Error())
+	} else {
+		response.Success(ctx, plugin.GetPlugin(name).Info())
+	}
+}
+
+func (c *PluginMController) DisablePlugin(ctx *gin.Context) {
+	name := ctx.Param("name")
+	if err := plugin.DisablePlugin(name); err == plugin.ErrPluginNotFound {
+		response.NotFound(ctx, err.Error())
+	} else if err != nil {
+		response.FailMessage(ctx, 400, err.Error())
+	} else {
+		response.Success(ctx, plugin.GetPlugin(name).Info())
+	}
+}
@@ -157,7 +157,7 @@ func (u *UserMController) AddUser(c *gin.Context) {
 		service.GetUserService().LoadPolicy(res.Id)
 		response.Success(c, res)
 	} else {
-		response.SystemErrorMessage(c, errors.ERROR_CREATE_FAIL, err.Error())
+		response.AbortWithError(c, errors.Wrap(err, errors.ERROR_CREATE_FAIL))
 	}
 }
 
@@ -201,7 +201,7 @@ func (u *UserMController) UpdateUser(c *gin.Context) {
 	}
 
 	if result, err := service.GetUserService().UpdateUser(&user); err != nil {
-		response.SystemErrorCode(c, errors.ERROR_UPDATE_FAIL)
+		response.AbortWithError(c, errors.Wrap(err, errors.ERROR_UPDATE_FAIL))
 	} else {
 		response.Success(c, result)
 	}
@@ -252,7 +252,11 @@ func (u *UserMController) CreateUser(c *gin.Context) {
 	}
 
 	if res, err := u.UserService.CreateUser(&user); err != nil {
-		response.FailMessage(c, 400, err.Error())
+		if appErr, ok := errors.AsAppError(err); ok {
+			response.AbortWithError(c, appErr)
+		} else {
+			response.FailMessage(c, 400, err.Error())
+		}
 		return
 	} else {
 		response.Success(c, res)
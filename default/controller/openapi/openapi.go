@@ -6,6 +6,7 @@ import (
 	"github.com/gophab/gophrame/core/controller"
 	"github.com/gophab/gophrame/core/permission"
 	"github.com/gophab/gophrame/core/security"
+	"github.com/gophab/gophrame/core/webservice/middleware"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,6 +23,7 @@ var PublicResources *controller.Controllers = &controller.Controllers{
 	Base: "/openapi/public",
 	Handlers: []gin.HandlerFunc{
 		security.CheckTokenVerify(),       // oauth2 验证
+		middleware.UserRateLimit(),        // 按用户限流
 		permission.CheckUserPermissions(), // 权限验证
 	},
 	Controllers: []controller.Controller{
@@ -33,6 +35,7 @@ var UserResources *controller.Controllers = &controller.Controllers{
 	Base: "/openapi/user",
 	Handlers: []gin.HandlerFunc{
 		security.HandleTokenVerify(),      // oauth2 验证
+		middleware.UserRateLimit(),        // 按用户限流
 		permission.CheckUserPermissions(), // 权限验证
 	},
 	Controllers: []controller.Controller{
@@ -51,6 +54,7 @@ var AdminResources *controller.Controllers = &controller.Controllers{
 	Base: "/openapi/admin",
 	Handlers: []gin.HandlerFunc{
 		security.HandleTokenVerify(), // oauth2 验证
+		middleware.UserRateLimit(),   // 按用户限流
 		permission.NeedAdmin(),
 		permission.CheckUserPermissions(), // 权限验证
 	},
@@ -166,13 +166,13 @@ func (u *UserOpenController) AddUser(c *gin.Context) {
 
 	res, err := service.GetUserService().CreateUser(&user)
 	if err != nil {
-		response.SystemErrorCode(c, errors.ERROR_CREATE_FAIL)
+		response.AbortWithError(c, errors.Wrap(err, errors.ERROR_CREATE_FAIL))
 		return
 	}
 
 	err = service.GetUserService().LoadPolicy(res.Id)
 	if err != nil {
-		response.SystemErrorCode(c, errors.ERROR_UPDATE_FAIL)
+		response.AbortWithError(c, errors.Wrap(err, errors.ERROR_UPDATE_FAIL))
 		return
 	}
 
@@ -218,7 +218,7 @@ func (u *UserOpenController) UpdateUser(c *gin.Context) {
 	}
 
 	if result, err := service.GetUserService().UpdateUser(&user); err != nil {
-		response.SystemErrorCode(c, errors.ERROR_UPDATE_FAIL)
+		response.AbortWithError(c, errors.Wrap(err, errors.ERROR_UPDATE_FAIL))
 		return
 	} else {
 		response.OK(c, result)
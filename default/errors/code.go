@@ -1,9 +1,44 @@
 package errors
 
 import (
-	module "github.com/gophab/gophrame/default"
+	"net/http"
+
+	"github.com/gophab/gophrame/errors"
 )
 
+// 模块错误码从 MODULE_ID * 100000 开始，default 模块 MODULE_ID 为 1
+// 不引用 module 包：module 依赖 service，service 依赖本包
 const (
-	ERROR_START = module.MODULE_ID + iota
+	ERROR_START = 100000 + iota
+
+	ERROR_USER_LOGIN_EXISTS
+	ERROR_USER_MOBILE_EXISTS
+	ERROR_USER_EMAIL_EXISTS
+	ERROR_USER_ID_EMPTY
+	ERROR_ROLE_NAME_EXISTS
 )
+
+var (
+	ErrUserLoginExists  = errors.NewAppError(ERROR_USER_LOGIN_EXISTS, http.StatusConflict)
+	ErrUserMobileExists = errors.NewAppError(ERROR_USER_MOBILE_EXISTS, http.StatusConflict)
+	ErrUserEmailExists  = errors.NewAppError(ERROR_USER_EMAIL_EXISTS, http.StatusConflict)
+	ErrUserIdEmpty      = errors.NewAppError(ERROR_USER_ID_EMPTY, http.StatusBadRequest)
+	ErrRoleNameExists   = errors.NewAppError(ERROR_ROLE_NAME_EXISTS, http.StatusConflict)
+)
+
+func init() {
+	errors.RegisterErrorMessages("zh", map[int]string{
+		ERROR_USER_LOGIN_EXISTS:  "用户名重复,请更改！",
+		ERROR_USER_MOBILE_EXISTS: "手机号重复,请更改！",
+		ERROR_USER_EMAIL_EXISTS:  "邮箱重复,请更改！",
+		ERROR_USER_ID_EMPTY:      "Id为空",
+		ERROR_ROLE_NAME_EXISTS:   "name 名字重复,请更改！",
+	})
+	errors.RegisterErrorMessages("en", map[int]string{
+		ERROR_USER_LOGIN_EXISTS:  "Login name already exists",
+		ERROR_USER_MOBILE_EXISTS: "Mobile number already exists",
+		ERROR_USER_EMAIL_EXISTS:  "Email already exists",
+		ERROR_USER_ID_EMPTY:      "Id is empty",
+		ERROR_ROLE_NAME_EXISTS:   "Role name already exists",
+	})
+}
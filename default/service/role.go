@@ -1,7 +1,7 @@
 package service
 
 import (
-	"errors"
+	"fmt"
 
 	"github.com/gophab/gophrame/core/inject"
 	"github.com/gophab/gophrame/core/logger"
@@ -9,6 +9,7 @@ import (
 	"github.com/gophab/gophrame/service"
 
 	"github.com/gophab/gophrame/default/domain"
+	"github.com/gophab/gophrame/default/errors"
 	"github.com/gophab/gophrame/default/repository"
 	"github.com/gophab/gophrame/default/service/dto"
 
@@ -33,7 +34,7 @@ func init() {
 func (s *RoleService) Add(role *dto.Role) (*domain.Role, error) {
 	name, _ := s.RoleResposity.CheckRoleName(role.Name)
 	if name {
-		return nil, errors.New("name 名字重复,请更改！")
+		return nil, errors.ErrRoleNameExists
 	}
 
 	res, err := s.RoleResposity.AddRole(map[string]interface{}{
@@ -46,7 +47,7 @@ func (s *RoleService) Add(role *dto.Role) (*domain.Role, error) {
 
 	err = s.LoadPolicy(role.Id)
 	if err != nil {
-		return res, errors.New("load policy failed")
+		return res, fmt.Errorf("load policy failed")
 	}
 
 	return res, nil
@@ -55,7 +56,7 @@ func (s *RoleService) Add(role *dto.Role) (*domain.Role, error) {
 func (s *RoleService) Edit(role *dto.Role) error {
 	name, _ := s.RoleResposity.CheckRoleNameId(role.Name, role.Id)
 	if name {
-		return errors.New("name 名字重复,请更改！")
+		return errors.ErrRoleNameExists
 	}
 
 	err := s.RoleResposity.EditRole(role.Id, map[string]interface{}{
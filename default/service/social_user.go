@@ -178,7 +178,7 @@ func (s *SocialUserService) onUserLogin(args ...interface{}) {
 			return
 		} else {
 			if socialUser.UserId != nil {
-				eventbus.PublishEvent("USER_LOGIN", socialUser.UserId, data)
+				eventbus.PublishEvent("USER_LOGIN", *socialUser.UserId, data)
 			}
 
 			socialUser.LastLoginTime = util.TimeAddr(time.Now())
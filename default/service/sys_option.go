@@ -1,7 +1,11 @@
 package service
 
 import (
+	"context"
+
+	"github.com/gophab/gophrame/core/cache"
 	"github.com/gophab/gophrame/core/inject"
+	"github.com/gophab/gophrame/core/logger"
 	"github.com/gophab/gophrame/service"
 
 	"github.com/gophab/gophrame/default/domain"
@@ -55,7 +59,38 @@ func (s *SysOptionService) GetDefaultOptions(tenantId string) (*domain.SysOption
 	return result, nil
 }
 
+// 租户选项缓存，DEFAULT 选项变更时清空整个区域；未启用 Redis 时不缓存，多实例下各实例都读到最新值
+func (s *SysOptionService) cache() cache.Cache {
+	return cache.SharedRegion("sys.option")
+}
+
+func (s *SysOptionService) invalidate(tenantIds ...string) {
+	if s.cache() == nil {
+		return
+	}
+
+	var err error
+	for _, tenantId := range tenantIds {
+		if tenantId == "DEFAULT" {
+			err = s.cache().Clear(context.Background())
+			break
+		}
+	}
+	if err == nil {
+		err = s.cache().Delete(context.Background(), tenantIds...)
+	}
+	if err != nil {
+		logger.Warn("Invalidate sys option cache error: ", err.Error())
+	}
+}
+
 func (s *SysOptionService) GetTenantOptions(tenantId string) (*domain.SysOptions, error) {
+	return cache.GetOrLoad(context.Background(), s.cache(), tenantId, 0, func(ctx context.Context) (*domain.SysOptions, error) {
+		return s.loadTenantOptions(tenantId)
+	})
+}
+
+func (s *SysOptionService) loadTenantOptions(tenantId string) (*domain.SysOptions, error) {
 	result, err := s.GetDefaultOptions(tenantId)
 	if err != nil {
 		return nil, err
@@ -85,6 +120,7 @@ func (s *SysOptionService) GetSystemOptions() (*domain.SysOptions, error) {
 
 func (s *SysOptionService) AddSysOption(option *domain.SysOption) (*domain.SysOption, error) {
 	if res := s.SysOptionRepository.Save(option); res.Error == nil && res.RowsAffected > 0 {
+		s.invalidate(option.TenantId)
 		return option, nil
 	} else {
 		return nil, res.Error
@@ -93,6 +129,7 @@ func (s *SysOptionService) AddSysOption(option *domain.SysOption) (*domain.SysOp
 
 func (s *SysOptionService) DeleteSysOption(option *domain.SysOption) (*domain.SysOption, error) {
 	if res := s.SysOptionRepository.Delete(option); res.Error == nil {
+		s.invalidate(option.TenantId)
 		return option, nil
 	} else {
 		return nil, res.Error
@@ -105,16 +142,19 @@ func (s *SysOptionService) AddSysOptions(options []domain.SysOption) (*[]domain.
 		if res := s.SysOptionRepository.Save(option); res.Error != nil {
 			return nil, res.Error
 		}
+		s.invalidate(option.TenantId)
 		result[i] = option
 	}
 	return &result, nil
 }
 
 func (s *SysOptionService) RemoveAllTenantOptions(tenantId string) error {
+	defer s.invalidate(tenantId)
 	return s.SysOptionRepository.RemoveAllTenantOptions(tenantId)
 }
 
 func (s *SysOptionService) RemoveTenantOption(tenantId string, key string) (*domain.SysOption, error) {
+	defer s.invalidate(tenantId)
 	return nil, s.SysOptionRepository.Delete(&domain.SysOption{TenantId: tenantId, Option: domain.Option{Name: key}}).Error
 }
 
@@ -129,6 +169,7 @@ func (s *SysOptionService) SetTenantOption(tenantId string, key string, value st
 	}
 
 	if res := s.SysOptionRepository.Save(&option); res.Error == nil && res.RowsAffected > 0 {
+		s.invalidate(tenantId)
 		return &option, nil
 	} else {
 		return nil, res.Error
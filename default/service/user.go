@@ -1,7 +1,6 @@
 package service
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 
@@ -14,6 +13,7 @@ import (
 	"github.com/gophab/gophrame/service"
 
 	"github.com/gophab/gophrame/default/domain"
+	"github.com/gophab/gophrame/default/errors"
 	"github.com/gophab/gophrame/default/repository"
 	"github.com/gophab/gophrame/default/service/dto"
 
@@ -48,19 +48,19 @@ func (s *UserService) Check(username, password string) (bool, error) {
 func (s *UserService) CreateUser(user *dto.User) (*domain.User, error) {
 	if user.Login != nil {
 		if b, _ := s.UserRepository.CheckUserLogin(*user.Login); b {
-			return nil, errors.New("用户名重复,请更改！")
+			return nil, errors.ErrUserLoginExists
 		}
 	}
 
 	if user.Mobile != nil {
 		if b, _ := s.UserRepository.CheckUserMobile(*user.Mobile); b {
-			return nil, errors.New("手机号重复,请更改！")
+			return nil, errors.ErrUserMobileExists
 		}
 	}
 
 	if user.Email != nil {
 		if b, _ := s.UserRepository.CheckUserMobile(*user.Email); b {
-			return nil, errors.New("邮箱重复,请更改！")
+			return nil, errors.ErrUserEmailExists
 		}
 	}
 
@@ -83,7 +83,7 @@ func (s *UserService) CreateUser(user *dto.User) (*domain.User, error) {
 
 func (s *UserService) UpdateUser(user *dto.User) (*domain.User, error) {
 	if user.Id == nil {
-		return nil, errors.New("Id为空")
+		return nil, errors.ErrUserIdEmpty
 	}
 
 	exists, err := s.GetById(*user.Id)
@@ -94,7 +94,7 @@ func (s *UserService) UpdateUser(user *dto.User) (*domain.User, error) {
 	if user.Login != nil {
 		b, _ := s.UserRepository.CheckUserLoginId(*user.Login, *user.Id)
 		if b {
-			return nil, errors.New("用户名重复,请更改！")
+			return nil, errors.ErrUserLoginExists
 		}
 		exists.Login = user.Login
 	}
@@ -102,7 +102,7 @@ func (s *UserService) UpdateUser(user *dto.User) (*domain.User, error) {
 	if user.Mobile != nil {
 		b, _ := s.UserRepository.CheckUserMobileId(*user.Mobile, *user.Id)
 		if b {
-			return nil, errors.New("手机号重复,请更改！")
+			return nil, errors.ErrUserMobileExists
 		}
 		exists.Mobile = user.Mobile
 	}
@@ -110,7 +110,7 @@ func (s *UserService) UpdateUser(user *dto.User) (*domain.User, error) {
 	if user.Email != nil {
 		b, _ := s.UserRepository.CheckUserEmailId(*user.Email, *user.Id)
 		if b {
-			return nil, errors.New("邮箱重复,请更改！")
+			return nil, errors.ErrUserEmailExists
 		}
 		exists.Email = user.Email
 	}
@@ -281,6 +281,9 @@ func (s *UserService) onUserLogin(args ...interface{}) {
 	}
 
 	if userId != "" && !strings.HasPrefix(userId, "sns:") {
-		s.UserRepository.LogUserLogin(userId, data["IP"])
+		logger.Info("[AUDIT] User login:", fmt.Sprintf("user_id=%s ip=%s request_id=%s", userId, data["IP"], data["RequestId"]))
+		if err := s.UserRepository.LogUserLogin(userId, data["IP"]); err != nil {
+			logger.Error("Log user login error: ", err.Error())
+		}
 	}
 }
@@ -6,6 +6,11 @@ const (
 	SUCCESS        = 200 //
 	ERROR          = 500
 	INVALID_PARAMS = 400
+	UNAUTHORIZED   = 401
+	FORBIDDEN      = 403
+	NOT_FOUND      = 404
+
+	ERROR_TOO_MANY_REQUESTS = 429
 
 	ERROR_EXIST       = 10001
 	ERROR_EXIST_FAIL  = 10002
@@ -24,6 +29,9 @@ const (
 	ERROR_AUTH_CHECK_TOKEN_TIMEOUT = 20002
 	ERROR_AUTH_TOKEN               = 20003
 	ERROR_AUTH                     = 20004
+	ERROR_BAD_CREDENTIALS          = 20005
+	ERROR_INVALID_CLIENT           = 20006
+	ERROR_UNAUTHORIZED_CLIENT      = 20007
 
 	ERROR_FUNC_EVENT_ALREADY_EXISTS = 30001
 	ERROR_FUNC_EVENT_NOT_CALL       = 30002
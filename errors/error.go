@@ -0,0 +1,119 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gophab/gophrame/core/i18n"
+)
+
+// 应用错误：服务层返回，由 Web 层统一渲染
+type AppError struct {
+	Code    int           // 业务错误码
+	Status  int           // HTTP 状态码
+	Key     string        // 消息键，为空时按错误码取消息
+	Args    []interface{} // 消息参数
+	Message string        // 未设置消息键时使用的消息
+	Details interface{}   // 附加信息，如字段校验错误
+	Cause   error         // 原始错误，仅记录日志，不返回给客户端
+}
+
+func NewAppError(code int, status int) *AppError {
+	return &AppError{Code: code, Status: status}
+}
+
+func (e *AppError) clone() *AppError {
+	result := *e
+	return &result
+}
+
+// 以下方法均返回副本，预定义错误可安全复用
+func (e *AppError) WithKey(key string, args ...interface{}) *AppError {
+	result := e.clone()
+	result.Key = key
+	result.Args = args
+	return result
+}
+
+func (e *AppError) WithMessage(message string) *AppError {
+	result := e.clone()
+	result.Message = message
+	return result
+}
+
+func (e *AppError) WithDetails(details interface{}) *AppError {
+	result := e.clone()
+	result.Details = details
+	return result
+}
+
+func (e *AppError) WithCause(cause error) *AppError {
+	result := e.clone()
+	result.Cause = cause
+	return result
+}
+
+// 按语言获取消息：消息键 > 消息 > 错误码消息
+func (e *AppError) Localize(language string) string {
+	if e.Key != "" {
+		if message, ok := i18n.Lookup(language, e.Key); ok {
+			if len(e.Args) > 0 {
+				return fmt.Sprintf(message, e.Args...)
+			}
+			return message
+		}
+	}
+	if e.Message != "" {
+		return e.Message
+	}
+	return GetLocalizedErrorMessage(language, e.Code)
+}
+
+func (e *AppError) Error() string {
+	message := e.Localize(i18n.DefaultLanguage())
+	if e.Cause != nil {
+		return fmt.Sprintf("[%d] %s: %s", e.Code, message, e.Cause.Error())
+	}
+	return fmt.Sprintf("[%d] %s", e.Code, message)
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Cause
+}
+
+// 错误码相同即视为同一错误，支持 errors.Is(err, ErrNotFound)
+func (e *AppError) Is(target error) bool {
+	if t, ok := target.(*AppError); ok {
+		return e.Code == t.Code
+	}
+	return false
+}
+
+var (
+	ErrBadRequest      = NewAppError(INVALID_PARAMS, http.StatusBadRequest)
+	ErrUnauthorized    = NewAppError(UNAUTHORIZED, http.StatusUnauthorized)
+	ErrForbidden       = NewAppError(FORBIDDEN, http.StatusForbidden)
+	ErrNotFound        = NewAppError(NOT_FOUND, http.StatusNotFound)
+	ErrTooManyRequests = NewAppError(ERROR_TOO_MANY_REQUESTS, http.StatusTooManyRequests)
+	ErrInternal        = NewAppError(ERROR, http.StatusInternalServerError)
+)
+
+func AsAppError(err error) (*AppError, bool) {
+	var result *AppError
+	if errors.As(err, &result) {
+		return result, true
+	}
+	return nil, false
+}
+
+// 非应用错误包装为指定错误码的内部错误，原始错误作为 Cause
+func Wrap(err error, code int) *AppError {
+	if err == nil {
+		return nil
+	}
+	if result, ok := AsAppError(err); ok {
+		return result
+	}
+	return NewAppError(code, http.StatusInternalServerError).WithCause(err)
+}
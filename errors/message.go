@@ -1,9 +1,19 @@
 package errors
 
+import (
+	"strconv"
+
+	"github.com/gophab/gophrame/core/i18n"
+)
+
 var error_messages = map[int]string{
 	SUCCESS:                        "ok",
 	ERROR:                          "fail",
 	INVALID_PARAMS:                 "请求参数错误",
+	UNAUTHORIZED:                   "未登录用户",
+	FORBIDDEN:                      "登录用户没有权限",
+	NOT_FOUND:                      "请求的资源不存在",
+	ERROR_TOO_MANY_REQUESTS:        "请求过于频繁，请稍后再试",
 	ERROR_EXIST:                    "已存在该对象名称",
 	ERROR_EXIST_FAIL:               "获取已存在对象失败",
 	ERROR_NOT_EXIST:                "该对象不存在",
@@ -18,23 +28,80 @@ var error_messages = map[int]string{
 	ERROR_AUTH_CHECK_TOKEN_TIMEOUT: "Token已超时",
 	ERROR_AUTH_TOKEN:               "Token生成失败",
 	ERROR_AUTH:                     "Token错误",
+	ERROR_BAD_CREDENTIALS:          "账号密码错误",
+	ERROR_INVALID_CLIENT:           "未知应用",
+	ERROR_UNAUTHORIZED_CLIENT:      "应用未授权",
+}
+
+var error_messages_en = map[int]string{
+	SUCCESS:                        "ok",
+	ERROR:                          "Internal server error",
+	INVALID_PARAMS:                 "Invalid request parameters",
+	UNAUTHORIZED:                   "Authentication required",
+	FORBIDDEN:                      "Access denied",
+	NOT_FOUND:                      "Resource not found",
+	ERROR_TOO_MANY_REQUESTS:        "Too many requests, please try again later",
+	ERROR_EXIST:                    "Object already exists",
+	ERROR_EXIST_FAIL:               "Failed to check object existence",
+	ERROR_NOT_EXIST:                "Object does not exist",
+	ERROR_GET_S_FAIL:               "Failed to list objects",
+	ERROR_COUNT_FAIL:               "Failed to count objects",
+	ERROR_CREATE_FAIL:              "Failed to create object",
+	ERROR_UPDATE_FAIL:              "Failed to update object",
+	ERROR_DELETE_FAIL:              "Failed to delete object",
+	ERROR_EXPORT_FAIL:              "Failed to export objects",
+	ERROR_IMPORT_FAIL:              "Failed to import objects",
+	ERROR_AUTH_CHECK_TOKEN_FAIL:    "Token authentication failed",
+	ERROR_AUTH_CHECK_TOKEN_TIMEOUT: "Token expired",
+	ERROR_AUTH_TOKEN:               "Failed to generate token",
+	ERROR_AUTH:                     "Invalid token",
+	ERROR_BAD_CREDENTIALS:          "Invalid username or password",
+	ERROR_INVALID_CLIENT:           "Unknown client",
+	ERROR_UNAUTHORIZED_CLIENT:      "Client is not authorized",
+}
+
+func init() {
+	RegisterErrorMessages("zh", error_messages)
+	RegisterErrorMessages("en", error_messages_en)
+}
+
+// 错误码对应的消息键
+func MessageKey(code int) string {
+	return "error." + strconv.Itoa(code)
 }
 
+// 注册错误码的语言包，各模块在 init 中注册自己的错误码表
+func RegisterErrorMessages(language string, messages map[int]string) {
+	bundle := make(map[string]string, len(messages))
+	for code, message := range messages {
+		bundle[MessageKey(code)] = message
+	}
+	i18n.RegisterMessages(language, bundle)
+}
+
+// 注册中文错误消息
 func AddErrorMessage(code int, message string) {
-	error_messages[code] = message
+	RegisterErrorMessages("zh", map[int]string{code: message})
 }
 
 func AddErrorMessages(messages map[int]string) {
-	for code, value := range messages {
-		error_messages[code] = value
-	}
+	RegisterErrorMessages("zh", messages)
 }
 
-func GetErrorMessage(code int) string {
-	msg, ok := error_messages[code]
-	if ok {
-		return msg
+// 按指定语言获取错误码消息，未注册时返回通用错误消息
+func GetLocalizedErrorMessage(language string, code int) string {
+	if message, ok := i18n.Lookup(language, MessageKey(code)); ok {
+		return message
 	}
+	return i18n.Translate(language, MessageKey(ERROR))
+}
+
+// 错误码是否注册了消息（默认语言）
+func HasErrorMessage(code int) bool {
+	_, ok := i18n.Lookup(i18n.DefaultLanguage(), MessageKey(code))
+	return ok
+}
 
-	return error_messages[ERROR]
+func GetErrorMessage(code int) string {
+	return GetLocalizedErrorMessage(i18n.DefaultLanguage(), code)
 }